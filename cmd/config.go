@@ -0,0 +1,120 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/the-trybe/forge-deploy-cli/pkg/config"
+	"github.com/the-trybe/forge-deploy-cli/pkg/generators"
+	"github.com/the-trybe/forge-deploy-cli/pkg/models"
+	"github.com/the-trybe/forge-deploy-cli/pkg/prompts"
+	"github.com/the-trybe/forge-deploy-cli/pkg/secrets"
+	"github.com/the-trybe/forge-deploy-cli/pkg/validate"
+)
+
+// loadDocument loads an existing forge-deploy.yml file
+func loadDocument(path string) (*config.Document, error) {
+	doc, err := config.LoadDocument(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, fmt.Errorf("%s not found (run 'forge-deploy generate' first)", path)
+		}
+		return nil, err
+	}
+	warnSecrets(doc.Config)
+	return doc, nil
+}
+
+// loadExistingDocument loads a forge-deploy.yml file if it exists.
+// It returns a nil document when there is no file at path.
+func loadExistingDocument(path string) (*config.Document, error) {
+	doc, err := config.LoadDocument(path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err == nil {
+		warnSecrets(doc.Config)
+	}
+	return doc, err
+}
+
+// warnSecrets prints a warning for every inline environment value that looks like a real secret
+func warnSecrets(cfg *models.DeploymentConfig) {
+	for _, site := range cfg.Sites {
+		for _, finding := range secrets.Scan(site.Environment) {
+			fmt.Printf("Warning: site %s environment %s; replace the value with %s\n", site.Name, finding, finding.Placeholder())
+		}
+	}
+}
+
+// validateConfig runs the checks of the validate command on cfg, with its
+// paths resolved against dir. Warnings are printed, and errors fail.
+func validateConfig(cfg *models.DeploymentConfig, dir string) error {
+	fmt.Println("\nValidating configuration...")
+	return reportIssues(validate.Config(cfg, validate.Options{Dir: dir, NginxTemplates: prompts.NginxTemplates}))
+}
+
+// reportIssues prints warnings and returns an error listing the errors, if any
+func reportIssues(issues []validate.Issue) error {
+	var errors []validate.Issue
+	for _, issue := range issues {
+		switch issue.Severity {
+		case validate.SeverityError:
+			errors = append(errors, issue)
+		case validate.SeverityWarning:
+			fmt.Printf("Warning: %s\n", issue)
+		}
+	}
+	if len(errors) > 0 {
+		fmt.Println("\nConfiguration validation failed:")
+		for _, issue := range errors {
+			fmt.Printf("  - %s\n", issue)
+		}
+		return fmt.Errorf("configuration validation failed")
+	}
+
+	fmt.Println("Configuration valid!")
+	return nil
+}
+
+// renderConfig renders the forge-deploy.yml content for cfg. When doc is not
+// nil the existing file is updated in place so its comments and ordering survive.
+func renderConfig(doc *config.Document, cfg *models.DeploymentConfig) (string, error) {
+	if doc == nil {
+		content, err := generators.GenerateForgeDeployYAML(cfg)
+		if err != nil {
+			return "", fmt.Errorf("failed to generate forge config: %w", err)
+		}
+		return content, nil
+	}
+
+	if err := doc.Update(cfg); err != nil {
+		return "", fmt.Errorf("failed to update forge config: %w", err)
+	}
+
+	content, err := doc.Bytes()
+	if err != nil {
+		return "", fmt.Errorf("failed to encode forge config: %w", err)
+	}
+	return string(content), nil
+}
+
+// writeConfig writes cfg back to the file doc was loaded from. When confirm
+// is set, overwriting it needs confirmation or --force, as for writeOutput.
+func writeConfig(doc *config.Document, cfg *models.DeploymentConfig, confirm bool) error {
+	content, err := renderConfig(doc, cfg)
+	if err != nil {
+		return err
+	}
+	return writeOutput(doc.Path, content, confirm)
+}
+
+// findSite returns the index of the site with the given name, or -1
+func findSite(cfg *models.DeploymentConfig, name string) int {
+	for i, site := range cfg.Sites {
+		if site.Name == name {
+			return i
+		}
+	}
+	return -1
+}
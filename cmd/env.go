@@ -0,0 +1,228 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/the-trybe/forge-deploy-cli/pkg/dotenv"
+	"github.com/the-trybe/forge-deploy-cli/pkg/models"
+	"github.com/the-trybe/forge-deploy-cli/pkg/secrets"
+	"github.com/the-trybe/forge-deploy-cli/pkg/validate"
+)
+
+var (
+	envConfigPath   string
+	envTemplatePath string
+	envExitCode     bool
+)
+
+var envCmd = &cobra.Command{
+	Use:   "env",
+	Short: "Compare and sync site environments with .env.example",
+}
+
+var envDiffCmd = &cobra.Command{
+	Use:   "diff [site]",
+	Short: "List environment keys missing from or extra to .env.example",
+	Long: `List, for each site (or only the given one), the environment keys that are
+missing compared to the template and the keys the template does not define.
+
+The template is .env.example in the site's root_dir unless --template is given.`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: runEnvDiff,
+}
+
+var envSyncCmd = &cobra.Command{
+	Use:   "sync [site]",
+	Short: "Add keys missing from .env.example to site environments",
+	Long: `Add the keys of the template missing from each site's inline environment (or
+only the given site's). Existing values are never changed.
+
+Missing keys get the template's default, except APP_URL, which is set from the
+site's domain, and secrets such as APP_KEY and passwords, which become ${KEY}
+placeholders for GitHub secrets of the same name.`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: runEnvSync,
+}
+
+func init() {
+	for _, c := range []*cobra.Command{envDiffCmd, envSyncCmd} {
+		c.Flags().StringVarP(&envConfigPath, "forge-config", "f", "forge-deploy.yml", "Path to the forge deployment config")
+		c.Flags().StringVarP(&envTemplatePath, "template", "t", "", "Template env file, relative to the repository root (default: .env.example in the site's root_dir)")
+	}
+	envDiffCmd.Flags().BoolVar(&envExitCode, "exit-code", false, "Exit with status 1 when a site differs from the template")
+	addWriteFlags(envSyncCmd, false)
+
+	envCmd.AddCommand(envDiffCmd)
+	envCmd.AddCommand(envSyncCmd)
+}
+
+// envSites returns the indexes of the sites an env command applies to
+func envSites(cfg *models.DeploymentConfig, args []string) ([]int, error) {
+	if len(args) == 0 {
+		indexes := make([]int, len(cfg.Sites))
+		for i := range cfg.Sites {
+			indexes[i] = i
+		}
+		return indexes, nil
+	}
+
+	index := findSite(cfg, args[0])
+	if index < 0 {
+		return nil, fmt.Errorf("site %q not found in %s", args[0], envConfigPath)
+	}
+	return []int{index}, nil
+}
+
+// envTemplate loads the template a site's environment is compared with
+func envTemplate(site *models.SiteConfig) (*dotenv.File, string, error) {
+	root := filepath.Dir(envConfigPath)
+	path := envTemplatePath
+	if path == "" {
+		path = filepath.Join(site.RootDir, validate.EnvTemplate)
+	}
+
+	template, err := dotenv.Load(filepath.Join(root, path))
+	if err != nil {
+		return nil, path, fmt.Errorf("failed to read template %s: %w", path, err)
+	}
+	return template, path, nil
+}
+
+// siteEnvironment returns the content of a site's inline environment or env_file
+func siteEnvironment(site *models.SiteConfig) (string, error) {
+	if site.Environment != "" || site.EnvFile == "" {
+		return site.Environment, nil
+	}
+	data, err := os.ReadFile(filepath.Join(filepath.Dir(envConfigPath), site.EnvFile))
+	if err != nil {
+		return "", fmt.Errorf("failed to read env_file: %w", err)
+	}
+	return string(data), nil
+}
+
+func runEnvDiff(cmd *cobra.Command, args []string) error {
+	doc, err := loadDocument(envConfigPath)
+	if err != nil {
+		return err
+	}
+	cfg := doc.Config
+
+	indexes, err := envSites(cfg, args)
+	if err != nil {
+		return err
+	}
+
+	differs := false
+	for _, i := range indexes {
+		site := &cfg.Sites[i]
+
+		template, templatePath, err := envTemplate(site)
+		if err != nil {
+			return fmt.Errorf("site %s: %w", site.Name, err)
+		}
+		content, err := siteEnvironment(site)
+		if err != nil {
+			return fmt.Errorf("site %s: %w", site.Name, err)
+		}
+		env := dotenv.Parse(content)
+
+		missing, extra := env.Missing(template), env.Extra(template)
+		fmt.Printf("%s (compared with %s):\n", site.Name, templatePath)
+		if len(missing) == 0 && len(extra) == 0 {
+			fmt.Println("  in sync")
+			continue
+		}
+		differs = true
+		for _, key := range missing {
+			fmt.Printf("  - %s (missing)\n", key)
+		}
+		for _, key := range extra {
+			fmt.Printf("  + %s (not in template)\n", key)
+		}
+	}
+
+	if envExitCode && differs {
+		return &exitError{code: 1}
+	}
+	return nil
+}
+
+func runEnvSync(cmd *cobra.Command, args []string) error {
+	doc, err := loadDocument(envConfigPath)
+	if err != nil {
+		return err
+	}
+	config := doc.Config
+
+	indexes, err := envSites(config, args)
+	if err != nil {
+		return err
+	}
+
+	var secretKeys []string
+	for _, i := range indexes {
+		site := &config.Sites[i]
+
+		if site.Environment == "" {
+			reason := "it has no inline environment"
+			if site.EnvFile != "" {
+				reason = "it uses env_file " + site.EnvFile
+			}
+			fmt.Printf("Skipping %s: %s\n", site.Name, reason)
+			continue
+		}
+
+		template, templatePath, err := envTemplate(site)
+		if err != nil {
+			return fmt.Errorf("site %s: %w", site.Name, err)
+		}
+
+		var added []string
+		site.Environment, added = dotenv.AppendMissing(site.Environment, template, func(entry dotenv.Entry) string {
+			switch {
+			case entry.Key == "APP_URL":
+				return site.URL()
+			case secrets.Sensitive(entry.Key, entry.Value):
+				secretKeys = append(secretKeys, entry.Key)
+				return "${" + entry.Key + "}"
+			}
+			return entry.Value
+		})
+
+		if len(added) == 0 {
+			fmt.Printf("%s is in sync with %s\n", site.Name, templatePath)
+			continue
+		}
+		fmt.Printf("%s: adding %s from %s\n", site.Name, strings.Join(added, ", "), templatePath)
+	}
+
+	if err := validateConfig(config, filepath.Dir(envConfigPath)); err != nil {
+		return err
+	}
+	if err := writeConfig(doc, config, false); err != nil {
+		return err
+	}
+
+	if len(secretKeys) > 0 && !dryRun {
+		fmt.Printf("\nAdd these GitHub secrets for the new placeholders: %s\n", strings.Join(unique(secretKeys), ", "))
+	}
+	return nil
+}
+
+// unique returns values without duplicates, keeping the first occurrence
+func unique(values []string) []string {
+	seen := make(map[string]bool)
+	var out []string
+	for _, v := range values {
+		if !seen[v] {
+			seen[v] = true
+			out = append(out, v)
+		}
+	}
+	return out
+}
@@ -0,0 +1,29 @@
+package cmd
+
+import (
+	"os"
+
+	"github.com/the-trybe/forge-deploy-cli/pkg/forge"
+)
+
+var (
+	forgeToken string
+	forgeURL   string
+)
+
+// forgeClient returns a Forge API client, or nil when no token is configured
+func forgeClient() *forge.Client {
+	token := forgeToken
+	if token == "" {
+		token = os.Getenv(forge.TokenEnv)
+	}
+	if token == "" {
+		return nil
+	}
+
+	client := forge.NewClient(token)
+	if forgeURL != "" {
+		client.BaseURL = forgeURL
+	}
+	return client
+}
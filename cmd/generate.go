@@ -6,10 +6,11 @@ import (
 	"path/filepath"
 	"strings"
 
-	"github.com/AlecAivazis/survey/v2"
 	"github.com/spf13/cobra"
 
+	"github.com/the-trybe/forge-deploy-cli/pkg/answers"
 	"github.com/the-trybe/forge-deploy-cli/pkg/generators"
+	"github.com/the-trybe/forge-deploy-cli/pkg/models"
 	"github.com/the-trybe/forge-deploy-cli/pkg/prompts"
 )
 
@@ -18,13 +19,19 @@ var (
 	workflowFilename string
 	forgeConfigFile  string
 	triggerBranch    string
+	answersFile      string
 )
 
 var generateCmd = &cobra.Command{
 	Use:   "generate",
 	Short: "Generate deployment configuration files interactively",
-	Long:  `Generate deployment configuration files interactively by prompting for all configuration options.`,
-	RunE:  runGenerate,
+	Long: `Generate deployment configuration files interactively by prompting for all configuration options.
+
+Answers can be supplied up front with --answers (YAML or JSON, using the same
+keys as forge-deploy.yml plus an optional site_count). Only questions missing
+from the answers file are asked. Combine with --no-input to fail instead of
+prompting when a required answer is absent.`,
+	RunE: runGenerate,
 }
 
 func init() {
@@ -32,11 +39,40 @@ func init() {
 	generateCmd.Flags().StringVarP(&workflowFilename, "workflow-file", "w", "deploy.yml", "GitHub Actions workflow filename")
 	generateCmd.Flags().StringVarP(&forgeConfigFile, "forge-config", "f", "forge-deploy.yml", "Forge deployment config filename")
 	generateCmd.Flags().StringVarP(&triggerBranch, "trigger-branch", "b", "main", "Branch that triggers deployment")
+	generateCmd.Flags().StringVarP(&answersFile, "answers", "a", "", "YAML or JSON file with answers to the prompts")
+	addKeepSecretsFlag(generateCmd)
+	addWriteFlags(generateCmd, true)
+	addWorkflowFlags(generateCmd, true)
+}
+
+// addKeepSecretsFlag registers --keep-secrets on a command that prompts for sites
+func addKeepSecretsFlag(c *cobra.Command) {
+	c.Flags().BoolVar(&prompts.KeepSecrets, "keep-secrets", false, "Keep environment values that look like real secrets instead of replacing them with ${KEY} placeholders")
 }
 
 func runGenerate(cmd *cobra.Command, args []string) error {
+	workflow, err := workflowOptions(triggerBranch, forgeConfigFile)
+	if err != nil {
+		return err
+	}
+	if err := workflow.Validate(); err != nil {
+		return err
+	}
+
+	// Load answers file
+	var preset *answers.Answers
+	if answersFile != "" {
+		var err error
+		if preset, err = answers.Load(answersFile); err != nil {
+			return fmt.Errorf("failed to load answers: %w", err)
+		}
+	}
+
+	// Detect project settings where the configuration is written
+	prompts.ProjectDir = outputDir
+
 	// Get base configuration
-	config, err := prompts.PromptBaseConfig()
+	config, err := prompts.PromptBaseConfig(preset, forgeClient())
 	if err != nil {
 		return fmt.Errorf("failed to get base config: %w", err)
 	}
@@ -45,16 +81,13 @@ func runGenerate(cmd *cobra.Command, args []string) error {
 	fmt.Println()
 	fmt.Println(strings.Repeat("=", 50))
 
-	var siteCount int
-	if err := survey.AskOne(&survey.Input{
-		Message: "How many sites do you want to configure?",
-		Default: "1",
-	}, &siteCount); err != nil {
+	siteCount, err := prompts.PromptSiteCount(preset)
+	if err != nil {
 		return fmt.Errorf("failed to get site count: %w", err)
 	}
 
 	for i := 0; i < siteCount; i++ {
-		site, err := prompts.PromptCompleteSite(config.GithubBranch, i+1)
+		site, err := prompts.PromptCompleteSite(config, i+1, preset.Site(i), nil)
 		if err != nil {
 			return fmt.Errorf("failed to configure site %d: %w", i+1, err)
 		}
@@ -68,65 +101,97 @@ func runGenerate(cmd *cobra.Command, args []string) error {
 	}
 
 	// Validate configuration
-	fmt.Println("\nValidating configuration...")
-	errors := config.Validate()
-	if len(errors) > 0 {
-		fmt.Println("\nConfiguration validation failed:")
-		for _, err := range errors {
-			fmt.Printf("  - %s\n", err)
-		}
-		return fmt.Errorf("configuration validation failed")
+	if err := validateConfig(config, outputDir); err != nil {
+		return err
 	}
 
-	fmt.Println("Configuration valid!")
-
 	// Generate files
+	if err := writeGeneratedFiles(config, outputDir, workflowFilename, workflow); err != nil {
+		return err
+	}
+
+	if dryRun {
+		fmt.Println("\nDry run: no files were written.")
+		return nil
+	}
+
+	printNextSteps(workflow, generators.SecretNames(config))
+	return nil
+}
+
+// writeGeneratedFiles writes the forge config and the GitHub workflow for config
+func writeGeneratedFiles(config *models.DeploymentConfig, outputDir, workflowFilename string, workflow generators.WorkflowOptions) error {
 	fmt.Println("\nGenerating files...")
 
-	// Create output directory
-	if err := os.MkdirAll(outputDir, 0755); err != nil {
-		return fmt.Errorf("failed to create output directory: %w", err)
+	// Create output directories
+	workflowDir := filepath.Join(outputDir, ".github", "workflows")
+	if !dryRun {
+		if err := os.MkdirAll(workflowDir, 0755); err != nil {
+			return fmt.Errorf("failed to create workflow directory: %w", err)
+		}
 	}
 
-	// Generate forge config file
-	forgeConfigPath := filepath.Join(outputDir, forgeConfigFile)
-	forgeConfig, err := generators.GenerateForgeDeployYAML(config)
+	// Generate forge config file, updating an existing one in place
+	forgeConfigPath := filepath.Join(outputDir, workflow.ConfigFileName)
+	existing, err := loadExistingDocument(forgeConfigPath)
 	if err != nil {
-		return fmt.Errorf("failed to generate forge config: %w", err)
+		fmt.Printf("  Warning: could not read existing %s, it will be replaced: %v\n", forgeConfigPath, err)
 	}
 
-	if err := os.WriteFile(forgeConfigPath, []byte(forgeConfig), 0644); err != nil {
-		return fmt.Errorf("failed to write forge config: %w", err)
+	forgeConfig, err := renderConfig(existing, config)
+	if err != nil {
+		return err
 	}
-	fmt.Printf("  Created %s\n", forgeConfigPath)
 
-	// Generate GitHub workflow
-	workflowDir := filepath.Join(outputDir, ".github", "workflows")
-	if err := os.MkdirAll(workflowDir, 0755); err != nil {
-		return fmt.Errorf("failed to create workflow directory: %w", err)
+	if err := writeOutput(forgeConfigPath, forgeConfig, true); err != nil {
+		return err
 	}
 
+	// Generate GitHub workflow
 	workflowPath := filepath.Join(workflowDir, workflowFilename)
-	workflow := generators.GenerateGitHubWorkflow(config, "Deploy to Forge", triggerBranch, forgeConfigFile)
-
-	if err := os.WriteFile(workflowPath, []byte(workflow), 0644); err != nil {
-		return fmt.Errorf("failed to write workflow: %w", err)
+	content, err := generators.GenerateGitHubWorkflow(config, workflow)
+	if err != nil {
+		return fmt.Errorf("failed to generate workflow: %w", err)
 	}
-	fmt.Printf("  Created %s\n", workflowPath)
 
-	// Success message
+	return writeOutput(workflowPath, content, true)
+}
+
+// printNextSteps prints the success message and what to do next, including
+// the repository secrets the workflow needs
+func printNextSteps(workflow generators.WorkflowOptions, secrets []string) {
 	fmt.Println()
 	fmt.Println(strings.Repeat("=", 50))
 	fmt.Println("Configuration generated successfully!")
 	fmt.Println()
 	fmt.Println("Next steps:")
 	fmt.Println("  1. Review the generated files")
-	fmt.Println("  2. Add required secrets to your GitHub repository:")
+	fmt.Println("  2. Add these secrets to your GitHub repository:")
 	fmt.Println("     Settings > Secrets and variables > Actions")
-	fmt.Println("  3. Commit and push the files to your repository")
-	fmt.Printf("  4. Push to '%s' branch to trigger deployment\n", triggerBranch)
+	fmt.Println("       - FORGE_API_TOKEN")
+	for _, secret := range secrets {
+		if secret != "FORGE_API_TOKEN" {
+			fmt.Printf("       - %s\n", secret)
+		}
+	}
+	step := 3
+	if workflow.Environment != "" {
+		fmt.Printf("  %d. Create the '%s' environment in Settings > Environments", step, workflow.Environment)
+		if len(workflow.Reviewers) > 0 {
+			fmt.Printf(" with required reviewers: %s", strings.Join(workflow.Reviewers, ", "))
+		}
+		fmt.Println()
+		step++
+	}
+	fmt.Printf("  %d. Commit and push the files to your repository\n", step)
+	var triggers []string
+	for _, branch := range workflow.Branches {
+		triggers = append(triggers, fmt.Sprintf("the '%s' branch", branch))
+	}
+	for _, tag := range workflow.Tags {
+		triggers = append(triggers, fmt.Sprintf("a tag matching '%s'", tag))
+	}
+	fmt.Printf("  %d. Push to %s to trigger deployment\n", step+1, strings.Join(triggers, " or "))
 	fmt.Println()
 	fmt.Println("Happy deploying!")
-
-	return nil
 }
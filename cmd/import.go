@@ -0,0 +1,124 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/the-trybe/forge-deploy-cli/pkg/generators"
+	"github.com/the-trybe/forge-deploy-cli/pkg/importer"
+)
+
+var (
+	importOptions       importer.Options
+	importOutputDir     string
+	importWorkflowFile  string
+	importForgeConfig   string
+	importTriggerBranch string
+)
+
+var importCmd = &cobra.Command{
+	Use:   "import",
+	Short: "Generate deployment configuration files from existing Forge sites",
+	Long: `Generate forge-deploy.yml and the GitHub Actions workflow from sites that
+already exist on a Forge server.
+
+Domains, aliases, PHP version, deployment scripts, daemons, the scheduler,
+isolation and the environment are read through the Forge API. A token is
+required (--forge-token or FORGE_API_TOKEN).
+
+Environment values that look like real secrets are replaced with ${KEY}
+placeholders, which the workflow fills in from GitHub secrets.`,
+	Args: cobra.NoArgs,
+	RunE: runImport,
+}
+
+func init() {
+	importCmd.Flags().StringVar(&importOptions.Organization, "organization", "", "Forge organization (defaults to the only organization of the token)")
+	importCmd.Flags().StringVar(&importOptions.Server, "server", "", "Forge server to import sites from")
+	importCmd.Flags().StringSliceVar(&importOptions.Sites, "site", nil, "Site to import (repeatable, defaults to all sites)")
+	importCmd.Flags().StringVar(&importOptions.Repository, "repository", "", "Only import sites deploying this GitHub repository (owner/repo)")
+	importCmd.Flags().BoolVar(&importOptions.SkipEnvironment, "skip-env", false, "Do not import the sites' environment files")
+	importCmd.Flags().StringVarP(&importOutputDir, "output-dir", "o", ".", "Output directory for generated files")
+	importCmd.Flags().StringVarP(&importWorkflowFile, "workflow-file", "w", "deploy.yml", "GitHub Actions workflow filename")
+	importCmd.Flags().StringVarP(&importForgeConfig, "forge-config", "f", "forge-deploy.yml", "Forge deployment config filename")
+	importCmd.Flags().StringVarP(&importTriggerBranch, "trigger-branch", "b", "", "Branch that triggers deployment (defaults to the sites' branch)")
+	importCmd.MarkFlagRequired("server")
+	addWriteFlags(importCmd, true)
+	addWorkflowFlags(importCmd, true)
+}
+
+func runImport(cmd *cobra.Command, args []string) error {
+	client := forgeClient()
+	if client == nil {
+		return fmt.Errorf("a Forge API token is required (--forge-token or FORGE_API_TOKEN)")
+	}
+
+	ctx := context.Background()
+
+	if importOptions.Organization == "" {
+		orgs, err := client.ListOrganizations(ctx)
+		if err != nil {
+			return fmt.Errorf("failed to list organizations: %w", err)
+		}
+		if len(orgs) != 1 {
+			slugs := make([]string, len(orgs))
+			for i, org := range orgs {
+				slugs[i] = org.Slug
+			}
+			return fmt.Errorf("--organization is required (available: %s)", strings.Join(slugs, ", "))
+		}
+		importOptions.Organization = orgs[0].Slug
+	}
+
+	fmt.Printf("Importing sites from %s/%s...\n", importOptions.Organization, importOptions.Server)
+
+	result, err := importer.Import(ctx, client, importOptions)
+	if err != nil {
+		return fmt.Errorf("failed to import: %w", err)
+	}
+	config := result.Config
+
+	for _, site := range config.Sites {
+		fmt.Printf("  Imported %s\n", site.Name)
+	}
+	for _, warning := range result.Warnings {
+		fmt.Printf("  Warning: %s\n", warning)
+	}
+
+	if err := validateConfig(config, importOutputDir); err != nil {
+		return err
+	}
+
+	triggerBranch := importTriggerBranch
+	if triggerBranch == "" {
+		triggerBranch = config.GithubBranch
+	}
+
+	workflow, err := workflowOptions(triggerBranch, importForgeConfig)
+	if err != nil {
+		return err
+	}
+	if err := workflow.Validate(); err != nil {
+		return err
+	}
+
+	if err := writeGeneratedFiles(config, importOutputDir, importWorkflowFile, workflow); err != nil {
+		return err
+	}
+
+	if !importOptions.SkipEnvironment {
+		fmt.Println("\nNote: the sites' environment files were imported inline.")
+		fmt.Println("Review them for secrets that were not replaced before committing, or re-run with --skip-env.")
+	}
+
+	if dryRun {
+		fmt.Println("\nDry run: no files were written.")
+		return nil
+	}
+
+	printNextSteps(workflow, generators.SecretNames(config))
+	return nil
+}
@@ -0,0 +1,91 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/the-trybe/forge-deploy-cli/pkg/config"
+	"github.com/the-trybe/forge-deploy-cli/pkg/nginx"
+	"github.com/the-trybe/forge-deploy-cli/pkg/prompts"
+)
+
+var nginxConfigPath string
+
+var nginxCmd = &cobra.Command{
+	Use:   "nginx",
+	Short: "Work with local nginx templates",
+}
+
+var nginxRenderCmd = &cobra.Command{
+	Use:   "render <site>",
+	Short: "Print a site's nginx server block rendered from its template",
+	Long: `Render the site's nginx_template from the local templates directory
+(--nginx-templates) and print it.
+
+Forge variables are filled in from the site's domain, aliases, web directory
+and PHP version, and custom variables from nginx_template_variables.
+Variables only Forge knows, such as {{SITE_ID}}, are left as they are.`,
+	Args: cobra.ExactArgs(1),
+	RunE: runNginxRender,
+}
+
+func init() {
+	nginxRenderCmd.Flags().StringVarP(&nginxConfigPath, "forge-config", "f", "forge-deploy.yml", "Path to the forge deployment config")
+
+	nginxCmd.AddCommand(nginxRenderCmd)
+}
+
+func runNginxRender(cmd *cobra.Command, args []string) error {
+	// Loaded without warnings so the output can be redirected to a file
+	doc, err := config.LoadDocument(nginxConfigPath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return fmt.Errorf("%s not found (run 'forge-deploy generate' first)", nginxConfigPath)
+		}
+		return err
+	}
+
+	index := findSite(doc.Config, args[0])
+	if index < 0 {
+		return fmt.Errorf("site %q not found in %s", args[0], nginxConfigPath)
+	}
+	// Render with the settings Forge uses for fields left out, such as web_dir
+	site := doc.Config.Sites[index]
+	site.SetDefaults()
+	if site.NginxTemplate == "" {
+		return fmt.Errorf("site %s has no nginx_template", site.Name)
+	}
+
+	dir := prompts.NginxTemplates
+	if !filepath.IsAbs(dir) {
+		dir = filepath.Join(filepath.Dir(nginxConfigPath), dir)
+	}
+	templates, err := nginx.LoadTemplates(dir)
+	if err != nil {
+		return fmt.Errorf("failed to read nginx templates: %w", err)
+	}
+	template, ok := nginx.Find(templates, site.NginxTemplate)
+	if !ok {
+		return fmt.Errorf("nginx template %q not found in %s", site.NginxTemplate, dir)
+	}
+
+	rendered, unresolved := template.Render(&site)
+	fmt.Print(rendered)
+
+	var missing []string
+	for _, name := range unresolved {
+		if _, forge := nginx.ForgeVariables[name]; forge {
+			fmt.Fprintf(os.Stderr, "Note: {{%s}} (%s) is filled in by Forge\n", name, nginx.ForgeVariables[name])
+		} else {
+			missing = append(missing, name)
+		}
+	}
+	if len(missing) > 0 {
+		return fmt.Errorf("nginx_template_variables does not set %s", strings.Join(missing, ", "))
+	}
+	return nil
+}
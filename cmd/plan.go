@@ -0,0 +1,63 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/spf13/cobra"
+
+	"github.com/the-trybe/forge-deploy-cli/pkg/config"
+	"github.com/the-trybe/forge-deploy-cli/pkg/plan"
+)
+
+var planDetailedExitCode bool
+
+var planCmd = &cobra.Command{
+	Use:   "plan [file]",
+	Short: "Show what deploying forge-deploy.yml would change on Forge",
+	Long: `Compare a forge-deploy.yml file with the live state of its Forge server and
+print the changes a deployment would make to each site.
+
+Reports sites to create and changed fields such as the PHP version, aliases,
+processes, nginx template, deployment script and environment keys. Environment
+values are never printed. A Forge API token is required (--forge-token or
+FORGE_API_TOKEN).`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: runPlan,
+}
+
+func init() {
+	planCmd.Flags().BoolVar(&planDetailedExitCode, "detailed-exitcode", false, "Exit with status 2 when there are changes")
+}
+
+func runPlan(cmd *cobra.Command, args []string) error {
+	path := "forge-deploy.yml"
+	if len(args) > 0 {
+		path = args[0]
+	}
+
+	client := forgeClient()
+	if client == nil {
+		return fmt.Errorf("a Forge API token is required (--forge-token or FORGE_API_TOKEN)")
+	}
+
+	cfg, err := config.Load(path)
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+	warnSecrets(cfg)
+
+	p, err := plan.Build(context.Background(), client, cfg, filepath.Dir(path))
+	if err != nil {
+		return fmt.Errorf("failed to build plan: %w", err)
+	}
+
+	plan.Render(os.Stdout, p)
+
+	if planDetailedExitCode && p.HasChanges() {
+		return &exitError{code: 2}
+	}
+	return nil
+}
@@ -0,0 +1,129 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/the-trybe/forge-deploy-cli/pkg/config"
+	"github.com/the-trybe/forge-deploy-cli/pkg/generators"
+	"github.com/the-trybe/forge-deploy-cli/pkg/prompts"
+	"github.com/the-trybe/forge-deploy-cli/pkg/validate"
+)
+
+var (
+	renderOutputDir    string
+	renderWorkflowFile string
+)
+
+var renderCmd = &cobra.Command{
+	Use:   "render [file]",
+	Short: "Render per-environment forge-deploy files from a base configuration",
+	Long: `Render one resolved forge-deploy.<environment>.yml per environment from a base
+configuration with environment overlays (default forge-deploy.base.yml), plus a
+GitHub Actions workflow that deploys each environment from its own branch.`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: runRender,
+}
+
+func init() {
+	renderCmd.Flags().StringVarP(&renderOutputDir, "output-dir", "o", ".", "Output directory for generated files")
+	renderCmd.Flags().StringVarP(&renderWorkflowFile, "workflow-file", "w", "deploy.yml", "GitHub Actions workflow filename")
+	addWriteFlags(renderCmd, true)
+	addWorkflowFlags(renderCmd, false)
+}
+
+func runRender(cmd *cobra.Command, args []string) error {
+	source := "forge-deploy.base.yml"
+	if len(args) > 0 {
+		source = args[0]
+	}
+
+	project, err := config.LoadProject(source)
+	if err != nil {
+		return fmt.Errorf("failed to load %s: %w", source, err)
+	}
+	warnSecrets(&project.DeploymentConfig)
+
+	fmt.Println("Validating configuration...")
+	issues := validate.Project(project, validate.Options{Dir: filepath.Dir(source), NginxTemplates: prompts.NginxTemplates})
+	if err := reportIssues(issues); err != nil {
+		return err
+	}
+
+	opts, err := workflowOptions("", "")
+	if err != nil {
+		return err
+	}
+	for _, env := range project.Environments {
+		opts.Branches = append(opts.Branches, env.Branch)
+	}
+	if err := opts.Validate(); err != nil {
+		return err
+	}
+
+	fmt.Println("Generating files...")
+
+	workflowDir := filepath.Join(renderOutputDir, ".github", "workflows")
+	if !dryRun {
+		if err := os.MkdirAll(workflowDir, 0755); err != nil {
+			return fmt.Errorf("failed to create workflow directory: %w", err)
+		}
+	}
+
+	var targets []generators.EnvironmentTarget
+	for _, env := range project.Environments {
+		resolved, err := project.Resolve(env.Name)
+		if err != nil {
+			return err
+		}
+
+		fileName := environmentFileName(source, env.Name)
+		content, err := generators.GenerateEnvironmentForgeDeployYAML(resolved, env.Name, filepath.Base(source))
+		if err != nil {
+			return fmt.Errorf("failed to generate config for %s: %w", env.Name, err)
+		}
+
+		if err := writeOutput(filepath.Join(renderOutputDir, fileName), content, false); err != nil {
+			return err
+		}
+
+		targets = append(targets, generators.EnvironmentTarget{
+			Name:           env.Name,
+			Branch:         env.Branch,
+			ConfigFileName: fileName,
+			Secrets:        generators.SecretNames(resolved),
+		})
+	}
+
+	workflow, err := generators.GenerateEnvironmentsWorkflow(targets, opts)
+	if err != nil {
+		return fmt.Errorf("failed to generate workflow: %w", err)
+	}
+	if err := writeOutput(filepath.Join(workflowDir, renderWorkflowFile), workflow, true); err != nil {
+		return err
+	}
+
+	if dryRun {
+		fmt.Println("\nDry run: no files were written.")
+		return nil
+	}
+
+	for _, target := range targets {
+		if len(target.Secrets) > 0 {
+			fmt.Printf("\nSecrets used by %s: %s\n", target.Name, strings.Join(target.Secrets, ", "))
+		}
+	}
+	return nil
+}
+
+// environmentFileName derives forge-deploy.<env>.yml from the base file name
+func environmentFileName(source, env string) string {
+	base := filepath.Base(source)
+	ext := filepath.Ext(base)
+	stem := strings.TrimSuffix(strings.TrimSuffix(base, ext), ".base")
+	return fmt.Sprintf("%s.%s%s", stem, env, ext)
+}
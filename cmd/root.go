@@ -1,10 +1,15 @@
 package cmd
 
 import (
+	"errors"
 	"fmt"
 	"os"
 
 	"github.com/spf13/cobra"
+
+	"github.com/the-trybe/forge-deploy-cli/pkg/forge"
+	"github.com/the-trybe/forge-deploy-cli/pkg/nginx"
+	"github.com/the-trybe/forge-deploy-cli/pkg/prompts"
 )
 
 var rootCmd = &cobra.Command{
@@ -15,16 +20,47 @@ var rootCmd = &cobra.Command{
 This tool helps you create GitHub Actions workflow and forge-deploy.yml
 files for automated deployment to Laravel Forge.`,
 	Version: "1.0.0",
+	// Execute prints the error, and usage is only shown for --help
+	SilenceErrors: true,
+	SilenceUsage:  true,
+}
+
+// exitError ends a command with a given status and no message, e.g. plan
+// --detailed-exitcode reporting changes
+type exitError struct {
+	code int
+}
+
+func (e *exitError) Error() string {
+	return fmt.Sprintf("exit status %d", e.code)
 }
 
 // Execute runs the root command
 func Execute() {
 	if err := rootCmd.Execute(); err != nil {
+		var exit *exitError
+		if errors.As(err, &exit) {
+			os.Exit(exit.code)
+		}
 		fmt.Fprintln(os.Stderr, err)
 		os.Exit(1)
 	}
 }
 
 func init() {
+	rootCmd.PersistentFlags().BoolVar(&prompts.NoInput, "no-input", false, "Never prompt; fail when a required answer is missing")
+	rootCmd.PersistentFlags().StringVar(&forgeToken, "forge-token", "", "Forge API token (defaults to $"+forge.TokenEnv+")")
+	rootCmd.PersistentFlags().StringVar(&forgeURL, "forge-url", "", "Forge API base URL")
+	rootCmd.PersistentFlags().StringVar(&prompts.NginxTemplates, "nginx-templates", nginx.DefaultTemplatesDir, "Directory of local nginx templates (<name>"+nginx.TemplateExt+")")
+
 	rootCmd.AddCommand(generateCmd)
+	rootCmd.AddCommand(validateCmd)
+	rootCmd.AddCommand(addSiteCmd)
+	rootCmd.AddCommand(editSiteCmd)
+	rootCmd.AddCommand(removeSiteCmd)
+	rootCmd.AddCommand(renderCmd)
+	rootCmd.AddCommand(importCmd)
+	rootCmd.AddCommand(planCmd)
+	rootCmd.AddCommand(envCmd)
+	rootCmd.AddCommand(nginxCmd)
 }
@@ -0,0 +1,185 @@
+package cmd
+
+import (
+	"fmt"
+	"path/filepath"
+
+	"github.com/spf13/cobra"
+
+	"github.com/the-trybe/forge-deploy-cli/pkg/answers"
+	"github.com/the-trybe/forge-deploy-cli/pkg/prompts"
+)
+
+var (
+	siteConfigPath  string
+	siteAnswersFile string
+	removeSiteYes   bool
+)
+
+var addSiteCmd = &cobra.Command{
+	Use:   "add-site",
+	Short: "Add a site to an existing forge-deploy.yml",
+	Long: `Add a site to an existing forge-deploy.yml by prompting for the new site only.
+
+The answers for the site can be supplied with --answers, using the first entry
+of the answers file's sites list.`,
+	Args: cobra.NoArgs,
+	RunE: runAddSite,
+}
+
+var editSiteCmd = &cobra.Command{
+	Use:   "edit-site <name>",
+	Short: "Edit a site in an existing forge-deploy.yml",
+	Long:  `Edit a site in an existing forge-deploy.yml. Every prompt is pre-filled with the current value.`,
+	Args:  cobra.ExactArgs(1),
+	RunE:  runEditSite,
+}
+
+var removeSiteCmd = &cobra.Command{
+	Use:   "remove-site <name>",
+	Short: "Remove a site from an existing forge-deploy.yml",
+	Args:  cobra.ExactArgs(1),
+	RunE:  runRemoveSite,
+}
+
+func init() {
+	for _, c := range []*cobra.Command{addSiteCmd, editSiteCmd, removeSiteCmd} {
+		c.Flags().StringVarP(&siteConfigPath, "forge-config", "f", "forge-deploy.yml", "Path to the forge deployment config")
+	}
+	for _, c := range []*cobra.Command{addSiteCmd, editSiteCmd} {
+		c.Flags().StringVarP(&siteAnswersFile, "answers", "a", "", "YAML or JSON file with answers to the prompts")
+		addKeepSecretsFlag(c)
+	}
+	removeSiteCmd.Flags().BoolVarP(&removeSiteYes, "yes", "y", false, "Remove without asking for confirmation")
+	addWriteFlags(addSiteCmd, true)
+	addWriteFlags(editSiteCmd, true)
+	// Confirming the removal, or --yes, also confirms the write
+	addWriteFlags(removeSiteCmd, false)
+}
+
+// loadSiteAnswers returns the answers for a single site, if an answers file was given
+func loadSiteAnswers() (*answers.Site, error) {
+	if siteAnswersFile == "" {
+		return nil, nil
+	}
+	preset, err := answers.Load(siteAnswersFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load answers: %w", err)
+	}
+	return preset.Site(0), nil
+}
+
+func runAddSite(cmd *cobra.Command, args []string) error {
+	doc, err := loadDocument(siteConfigPath)
+	if err != nil {
+		return err
+	}
+	config := doc.Config
+	prompts.ProjectDir = filepath.Dir(siteConfigPath)
+
+	preset, err := loadSiteAnswers()
+	if err != nil {
+		return err
+	}
+
+	site, err := prompts.PromptCompleteSite(config, len(config.Sites)+1, preset, nil)
+	if err != nil {
+		return fmt.Errorf("failed to configure site: %w", err)
+	}
+
+	config.Sites = append(config.Sites, *site)
+
+	if err := validateConfig(config, filepath.Dir(siteConfigPath)); err != nil {
+		return err
+	}
+
+	if err := writeConfig(doc, config, true); err != nil {
+		return err
+	}
+
+	if !dryRun {
+		fmt.Printf("\nAdded site %s to %s\n", site.Name, siteConfigPath)
+	}
+	return nil
+}
+
+func runEditSite(cmd *cobra.Command, args []string) error {
+	doc, err := loadDocument(siteConfigPath)
+	if err != nil {
+		return err
+	}
+	config := doc.Config
+	prompts.ProjectDir = filepath.Dir(siteConfigPath)
+
+	index := findSite(config, args[0])
+	if index < 0 {
+		return fmt.Errorf("site %q not found in %s", args[0], siteConfigPath)
+	}
+
+	preset, err := loadSiteAnswers()
+	if err != nil {
+		return err
+	}
+
+	site, err := prompts.PromptCompleteSite(config, index+1, preset, &config.Sites[index])
+	if err != nil {
+		return fmt.Errorf("failed to configure site: %w", err)
+	}
+
+	config.Sites[index] = *site
+
+	if err := validateConfig(config, filepath.Dir(siteConfigPath)); err != nil {
+		return err
+	}
+
+	if err := writeConfig(doc, config, true); err != nil {
+		return err
+	}
+
+	if !dryRun {
+		fmt.Printf("\nUpdated site %s in %s\n", site.Name, siteConfigPath)
+	}
+	return nil
+}
+
+func runRemoveSite(cmd *cobra.Command, args []string) error {
+	doc, err := loadDocument(siteConfigPath)
+	if err != nil {
+		return err
+	}
+	config := doc.Config
+
+	index := findSite(config, args[0])
+	if index < 0 {
+		return fmt.Errorf("site %q not found in %s", args[0], siteConfigPath)
+	}
+
+	if !removeSiteYes {
+		if prompts.NoInput {
+			return fmt.Errorf("removing site %q needs confirmation (use --yes when input is disabled)", args[0])
+		}
+		confirmed, err := prompts.PromptConfirm(fmt.Sprintf("Remove site %s from %s?", args[0], siteConfigPath), false)
+		if err != nil {
+			return err
+		}
+		if !confirmed {
+			fmt.Println("Aborted.")
+			return nil
+		}
+	}
+
+	config.Sites = append(config.Sites[:index], config.Sites[index+1:]...)
+
+	if err := validateConfig(config, filepath.Dir(siteConfigPath)); err != nil {
+		return err
+	}
+
+	if err := writeConfig(doc, config, false); err != nil {
+		return err
+	}
+
+	if !dryRun {
+		fmt.Printf("\nRemoved site %s from %s\n", args[0], siteConfigPath)
+	}
+	return nil
+}
@@ -0,0 +1,59 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/the-trybe/forge-deploy-cli/pkg/prompts"
+	"github.com/the-trybe/forge-deploy-cli/pkg/validate"
+)
+
+var (
+	validateFormat string
+	validateStrict bool
+)
+
+var validateCmd = &cobra.Command{
+	Use:   "validate [file]",
+	Short: "Validate a forge-deploy.yml file",
+	Long: `Validate a forge-deploy.yml file without prompting.
+
+Runs the model validation plus semantic checks and exits non-zero when errors
+are found, so it can gate pull requests in CI. A base configuration with
+environments is checked once per resolved environment. Each issue has a field path,
+a code, a severity (error, warning or info) and, where there is an obvious
+one, a fix. Use --format github to emit GitHub Actions annotations and
+--format sarif for code scanning.`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: runValidate,
+}
+
+func init() {
+	validateCmd.Flags().StringVar(&validateFormat, "format", "text", "Output format ("+strings.Join(validate.Formats, ", ")+")")
+	validateCmd.Flags().BoolVar(&validateStrict, "strict", false, "Treat warnings as errors")
+}
+
+func runValidate(cmd *cobra.Command, args []string) error {
+	path := "forge-deploy.yml"
+	if len(args) > 0 {
+		path = args[0]
+	}
+
+	result, err := validate.File(path, validate.Options{NginxTemplates: prompts.NginxTemplates})
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+
+	if err := validate.Render(os.Stdout, result, validateFormat); err != nil {
+		return err
+	}
+
+	if result.Failed(validateStrict) {
+		return fmt.Errorf("configuration validation failed")
+	}
+
+	return nil
+}
@@ -0,0 +1,65 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	"github.com/the-trybe/forge-deploy-cli/pkg/generators"
+)
+
+var (
+	workflowRunner           string
+	workflowActionVersion    string
+	workflowCheckoutVersion  string
+	workflowConcurrency      string
+	workflowCancelInProgress bool
+	workflowEnvironment      string
+	workflowEnvironmentURL   string
+	workflowReviewers        []string
+	workflowPaths            []string
+	workflowTags             []string
+)
+
+// addWorkflowFlags registers the options of the generated GitHub Actions workflow.
+// withEnvironment adds the flags that only apply to a single deploy job.
+func addWorkflowFlags(c *cobra.Command, withEnvironment bool) {
+	c.Flags().StringVar(&workflowRunner, "runner", generators.DefaultRunner, "Runner label of the deploy job")
+	c.Flags().StringVar(&workflowActionVersion, "action-version", generators.DefaultActionVersion, "Tag or full commit SHA of the deploy action")
+	c.Flags().StringVar(&workflowCheckoutVersion, "checkout-version", generators.DefaultCheckoutVersion, "Tag or full commit SHA of actions/checkout")
+	c.Flags().StringVar(&workflowConcurrency, "concurrency", "", "Concurrency group so only one deployment runs at a time")
+	c.Flags().BoolVar(&workflowCancelInProgress, "cancel-in-progress", false, "Cancel a running deployment when a new one starts (requires --concurrency)")
+	c.Flags().StringSliceVar(&workflowPaths, "paths", nil, "Only deploy when these paths change (repeatable)")
+	if withEnvironment {
+		c.Flags().StringVar(&workflowEnvironment, "environment", "", "GitHub environment the deploy job runs in")
+		c.Flags().StringVar(&workflowEnvironmentURL, "environment-url", "", "URL shown on deployments to the environment")
+		c.Flags().StringSliceVar(&workflowReviewers, "reviewer", nil, "Required reviewer of the environment (repeatable)")
+		c.Flags().StringSliceVar(&workflowTags, "tag", nil, "Tag pattern that triggers a deployment, e.g. v* (repeatable)")
+	}
+}
+
+// workflowOptions collects the workflow flags for a workflow deploying configFileName
+func workflowOptions(triggerBranch, configFileName string) (generators.WorkflowOptions, error) {
+	if workflowCancelInProgress && workflowConcurrency == "" {
+		return generators.WorkflowOptions{}, fmt.Errorf("--cancel-in-progress requires --concurrency")
+	}
+
+	opts := generators.WorkflowOptions{
+		Name:             "Deploy to Forge",
+		Tags:             workflowTags,
+		Paths:            workflowPaths,
+		Runner:           workflowRunner,
+		ActionVersion:    workflowActionVersion,
+		CheckoutVersion:  workflowCheckoutVersion,
+		Concurrency:      workflowConcurrency,
+		CancelInProgress: workflowCancelInProgress,
+		Environment:      workflowEnvironment,
+		EnvironmentURL:   workflowEnvironmentURL,
+		Reviewers:        workflowReviewers,
+		ConfigFileName:   configFileName,
+	}
+	if triggerBranch != "" {
+		opts.Branches = []string{triggerBranch}
+	}
+	return opts, nil
+}
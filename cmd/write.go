@@ -0,0 +1,84 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+
+	"github.com/the-trybe/forge-deploy-cli/pkg/diff"
+	"github.com/the-trybe/forge-deploy-cli/pkg/prompts"
+)
+
+var (
+	dryRun    bool
+	showDiff  bool
+	forceFlag bool
+)
+
+// addWriteFlags registers the flags controlling how generated files are written
+func addWriteFlags(c *cobra.Command, withForce bool) {
+	c.Flags().BoolVar(&dryRun, "dry-run", false, "Print what would be written without writing any files")
+	c.Flags().BoolVar(&showDiff, "diff", false, "Show a unified diff against the files on disk")
+	if withForce {
+		c.Flags().BoolVar(&forceFlag, "force", false, "Overwrite existing files without asking")
+	}
+}
+
+// writeOutput writes a generated file, honouring --dry-run and --diff.
+// When confirm is set, overwriting an existing file needs confirmation or --force.
+func writeOutput(path, content string, confirm bool) error {
+	existing, err := os.ReadFile(path)
+	exists := err == nil
+	if err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to read %s: %w", path, err)
+	}
+
+	if exists && string(existing) == content {
+		fmt.Printf("  Unchanged %s\n", path)
+		return nil
+	}
+
+	if showDiff {
+		oldName := "a/" + path
+		if !exists {
+			oldName = "/dev/null"
+		}
+		fmt.Println()
+		fmt.Print(diff.Unified(oldName, "b/"+path, string(existing), content, 3))
+		fmt.Println()
+	}
+
+	if dryRun {
+		if !showDiff {
+			fmt.Printf("\n--- %s (dry run) ---\n%s\n", path, content)
+		}
+		fmt.Printf("  Would write %s\n", path)
+		return nil
+	}
+
+	if exists && confirm && !forceFlag {
+		if prompts.NoInput {
+			return fmt.Errorf("%s already exists (use --force to overwrite)", path)
+		}
+		overwrite, err := prompts.PromptConfirm(fmt.Sprintf("%s already exists. Overwrite?", path), false)
+		if err != nil {
+			return err
+		}
+		if !overwrite {
+			fmt.Printf("  Skipped %s\n", path)
+			return nil
+		}
+	}
+
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		return fmt.Errorf("failed to write %s: %w", path, err)
+	}
+
+	if exists {
+		fmt.Printf("  Updated %s\n", path)
+	} else {
+		fmt.Printf("  Created %s\n", path)
+	}
+	return nil
+}
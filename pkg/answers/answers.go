@@ -0,0 +1,84 @@
+package answers
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/the-trybe/forge-deploy-cli/pkg/models"
+)
+
+// Answers holds pre-recorded answers for the interactive prompts.
+// A nil field means the question was not answered and is asked interactively.
+type Answers struct {
+	Organization     *string `yaml:"organization"`
+	Server           *string `yaml:"server"`
+	GithubRepository *string `yaml:"github_repository"`
+	GithubBranch     *string `yaml:"github_branch"`
+	SiteCount        *int    `yaml:"site_count"`
+	Sites            []Site  `yaml:"sites"`
+}
+
+// Site holds pre-recorded answers for a single site
+type Site struct {
+	Name                        *string                `yaml:"name"`
+	DomainMode                  *string                `yaml:"domain_mode"`
+	WWWRedirectType             *string                `yaml:"www_redirect_type"`
+	GithubBranch                *string                `yaml:"github_branch"`
+	RootDir                     *string                `yaml:"root_dir"`
+	WebDir                      *string                `yaml:"web_dir"`
+	CloneRepository             *bool                  `yaml:"clone_repository"`
+	ProjectType                 *string                `yaml:"project_type"`
+	PHPVersion                  *string                `yaml:"php_version"`
+	InstallComposerDependencies *bool                  `yaml:"install_composer_dependencies"`
+	DeploymentScript            *string                `yaml:"deployment_script"`
+	DeploymentScriptTemplate    *string                `yaml:"deployment_script_template"` // ignored when deployment_script is set
+	Environment                 *string                `yaml:"environment"`
+	EnvFile                     *string                `yaml:"env_file"`
+	Processes                   *[]models.Process      `yaml:"processes"`
+	LaravelScheduler            *bool                  `yaml:"laravel_scheduler"`
+	ScheduledJobs               *[]models.ScheduledJob `yaml:"scheduled_jobs"`
+	Aliases                     *[]string              `yaml:"aliases"`
+	NginxTemplate               *string                `yaml:"nginx_template"`
+	NginxTemplateVariables      *map[string]string     `yaml:"nginx_template_variables"`
+	NginxCustomConfig           *string                `yaml:"nginx_custom_config"`
+	Certificate                 *bool                  `yaml:"certificate"`
+	Isolated                    *bool                  `yaml:"isolated"`
+	IsolatedUser                *string                `yaml:"isolated_user"`
+	ZeroDowntimeDeployments     *bool                  `yaml:"zero_downtime_deployments"`
+	SharedPaths                 *[]models.SharedPath   `yaml:"shared_paths"`
+}
+
+// Load reads an answers file. JSON files are accepted as well since JSON is valid YAML.
+func Load(path string) (*Answers, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	a := &Answers{}
+
+	decoder := yaml.NewDecoder(f)
+	decoder.KnownFields(true)
+	if err := decoder.Decode(a); err != nil && !errors.Is(err, io.EOF) {
+		return nil, fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+
+	if a.SiteCount != nil && *a.SiteCount < len(a.Sites) {
+		return nil, fmt.Errorf("%s: site_count (%d) is less than the number of sites (%d)", path, *a.SiteCount, len(a.Sites))
+	}
+
+	return a, nil
+}
+
+// Site returns the answers for the site at index i, or nil if there are none
+func (a *Answers) Site(i int) *Site {
+	if a == nil || i < 0 || i >= len(a.Sites) {
+		return nil
+	}
+	return &a.Sites[i]
+}
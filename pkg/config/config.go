@@ -0,0 +1,408 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/the-trybe/forge-deploy-cli/pkg/models"
+)
+
+// Error describes a problem at a position in a configuration file
+type Error struct {
+	File    string
+	Line    int
+	Column  int
+	Message string
+}
+
+func (e *Error) Error() string {
+	switch {
+	case e.Line == 0:
+		return fmt.Sprintf("%s: %s", e.File, e.Message)
+	case e.Column == 0:
+		return fmt.Sprintf("%s:%d: %s", e.File, e.Line, e.Message)
+	}
+	return fmt.Sprintf("%s:%d:%d: %s", e.File, e.Line, e.Column, e.Message)
+}
+
+// ErrorList is a list of errors found while decoding a configuration file
+type ErrorList []*Error
+
+func (l ErrorList) Error() string {
+	messages := make([]string, len(l))
+	for i, err := range l {
+		messages[i] = err.Error()
+	}
+	return strings.Join(messages, "\n")
+}
+
+// Document is a decoded forge-deploy.yml file that keeps its node tree
+type Document struct {
+	Path   string
+	Config *models.DeploymentConfig
+	// Project is set for base configurations with environment overlays, and
+	// Config is then its base configuration
+	Project *models.ProjectConfig
+	Root    *yaml.Node
+
+	source []byte
+}
+
+// Load reads and decodes a forge-deploy.yml file
+func Load(path string) (*models.DeploymentConfig, error) {
+	doc, err := LoadDocument(path)
+	if err != nil {
+		return nil, err
+	}
+	return doc.Config, nil
+}
+
+// Parse decodes forge-deploy.yml content. The name is only used in error messages.
+// Unknown keys and values of the wrong type are reported with their line and column.
+func Parse(data []byte, name string) (*models.DeploymentConfig, error) {
+	doc, err := ParseDocument(data, name)
+	if err != nil {
+		return nil, err
+	}
+	return doc.Config, nil
+}
+
+// LoadDocument reads and decodes a forge-deploy.yml file, keeping its node tree
+func LoadDocument(path string) (*Document, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return ParseDocument(data, path)
+}
+
+// ParseDocument decodes forge-deploy.yml content, keeping its node tree
+func ParseDocument(data []byte, name string) (*Document, error) {
+	config := &models.DeploymentConfig{}
+	root, err := decodeStrict(data, name, config)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Document{Path: name, Config: config, Root: root, source: data}, nil
+}
+
+// LoadProject reads and decodes a base configuration with environment overlays
+func LoadProject(path string) (*models.ProjectConfig, error) {
+	doc, err := LoadProjectDocument(path)
+	if err != nil {
+		return nil, err
+	}
+	return doc.Project, nil
+}
+
+// LoadProjectDocument reads and decodes a base configuration with environment
+// overlays, keeping its node tree
+func LoadProjectDocument(path string) (*Document, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return ParseProjectDocument(data, path)
+}
+
+// ParseProjectDocument decodes a base configuration with environment overlays,
+// keeping its node tree
+func ParseProjectDocument(data []byte, name string) (*Document, error) {
+	project := &models.ProjectConfig{}
+	root, err := decodeStrict(data, name, project)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Document{Path: name, Config: &project.DeploymentConfig, Project: project, Root: root, source: data}, nil
+}
+
+// IsProject reports whether content is a base configuration with environment
+// overlays, that is whether it has a top-level environments key
+func IsProject(data []byte) bool {
+	var root yaml.Node
+	if err := yaml.Unmarshal(data, &root); err != nil || len(root.Content) == 0 {
+		return false
+	}
+	return root.Content[0].Kind == yaml.MappingNode && mappingIndex(root.Content[0], "environments") >= 0
+}
+
+// decodeStrict decodes YAML content into v, rejecting unknown keys
+func decodeStrict(data []byte, name string, v interface{}) (*yaml.Node, error) {
+	var root yaml.Node
+	if err := yaml.Unmarshal(data, &root); err != nil {
+		return nil, syntaxError(name, err)
+	}
+
+	if root.Kind != yaml.DocumentNode || len(root.Content) == 0 {
+		return nil, &Error{File: name, Message: "file is empty"}
+	}
+	attachAnchorComments(&root)
+
+	d := &decoder{file: name}
+	d.decode(root.Content[0], reflect.ValueOf(v).Elem())
+	if len(d.errors) > 0 {
+		return nil, d.errors
+	}
+
+	return &root, nil
+}
+
+var pathSegment = regexp.MustCompile(`([^.\[\]"]+)|\[(\d+)\]|\[("(?:[^"\\]|\\.)*")\]`)
+
+// Position returns the line and column of a field path such as "sites[2].php_version".
+// Map keys may be given quoted in brackets, e.g. nginx_template_variables["app.host"].
+// When the field is not present in the file, the position of its closest parent is returned.
+func (d *Document) Position(path string) (int, int) {
+	if d.Root == nil || len(d.Root.Content) == 0 {
+		return 0, 0
+	}
+
+	node := d.Root.Content[0]
+	line, column := node.Line, node.Column
+
+	for _, m := range pathSegment.FindAllStringSubmatch(path, -1) {
+		if node.Kind == yaml.AliasNode {
+			node = node.Alias
+		}
+
+		var next *yaml.Node
+		if m[2] != "" {
+			index, _ := strconv.Atoi(m[2])
+			if node.Kind == yaml.SequenceNode && index < len(node.Content) {
+				next = node.Content[index]
+				line, column = next.Line, next.Column
+			}
+		} else if node.Kind == yaml.MappingNode {
+			key := m[1]
+			if m[3] != "" {
+				key, _ = strconv.Unquote(m[3])
+			}
+			for i := 0; i+1 < len(node.Content); i += 2 {
+				if node.Content[i].Value == key {
+					next = node.Content[i+1]
+					line, column = node.Content[i].Line, node.Content[i].Column
+					break
+				}
+			}
+		}
+
+		if next == nil {
+			break
+		}
+		node = next
+	}
+
+	return line, column
+}
+
+var syntaxLine = regexp.MustCompile(`^yaml: line (\d+): (.*)$`)
+
+// syntaxError converts a yaml.v3 parse error into an Error
+func syntaxError(name string, err error) error {
+	if m := syntaxLine.FindStringSubmatch(err.Error()); m != nil {
+		line, _ := strconv.Atoi(m[1])
+		return &Error{File: name, Line: line, Message: m[2]}
+	}
+	return &Error{File: name, Message: strings.TrimPrefix(err.Error(), "yaml: ")}
+}
+
+var unmarshalerType = reflect.TypeOf((*yaml.Unmarshaler)(nil)).Elem()
+
+// decoder decodes a node tree into Go values, collecting positioned errors
+type decoder struct {
+	file   string
+	errors ErrorList
+}
+
+// errorf records an error at node. Nodes merged into several mappings are
+// decoded once per mapping, but their errors are only reported once.
+func (d *decoder) errorf(node *yaml.Node, format string, args ...interface{}) {
+	err := &Error{
+		File:    d.file,
+		Line:    node.Line,
+		Column:  node.Column,
+		Message: fmt.Sprintf(format, args...),
+	}
+	for _, other := range d.errors {
+		if *other == *err {
+			return
+		}
+	}
+	d.errors = append(d.errors, err)
+}
+
+func (d *decoder) decode(node *yaml.Node, v reflect.Value) {
+	if node.Kind == yaml.AliasNode {
+		node = node.Alias
+	}
+
+	if node.Kind == yaml.ScalarNode && node.Tag == "!!null" {
+		return
+	}
+
+	if v.CanAddr() && v.Addr().Type().Implements(unmarshalerType) {
+		if err := node.Decode(v.Addr().Interface()); err != nil {
+			var nodeErr *models.NodeError
+			if errors.As(err, &nodeErr) {
+				d.errorf(nodeErr.Node, "%s", nodeErr.Message)
+			} else {
+				d.errorf(node, "%s", err)
+			}
+		}
+		return
+	}
+
+	switch v.Kind() {
+	case reflect.Ptr:
+		value := reflect.New(v.Type().Elem())
+		d.decode(node, value.Elem())
+		v.Set(value)
+	case reflect.Struct:
+		d.decodeStruct(node, v)
+	case reflect.Slice:
+		if node.Kind != yaml.SequenceNode {
+			d.errorf(node, "expected a list")
+			return
+		}
+		slice := reflect.MakeSlice(v.Type(), len(node.Content), len(node.Content))
+		for i, item := range node.Content {
+			d.decode(item, slice.Index(i))
+		}
+		v.Set(slice)
+	case reflect.Map:
+		if node.Kind != yaml.MappingNode {
+			d.errorf(node, "expected a mapping")
+			return
+		}
+		m := reflect.MakeMapWithSize(v.Type(), len(node.Content)/2)
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			key := reflect.New(v.Type().Key()).Elem()
+			d.decode(node.Content[i], key)
+			value := reflect.New(v.Type().Elem()).Elem()
+			d.decode(node.Content[i+1], value)
+			m.SetMapIndex(key, value)
+		}
+		v.Set(m)
+	default:
+		if node.Kind != yaml.ScalarNode {
+			d.errorf(node, "expected %s", kindName(v.Kind()))
+			return
+		}
+		if err := node.Decode(v.Addr().Interface()); err != nil {
+			d.errorf(node, "cannot use %q as %s", node.Value, kindName(v.Kind()))
+		}
+	}
+}
+
+func (d *decoder) decodeStruct(node *yaml.Node, v reflect.Value) {
+	d.decodeFields(node, v, make(map[string]bool))
+}
+
+// decodeFields decodes the keys of a mapping into the fields of a struct,
+// skipping the fields in set, which an including mapping already set. Keys
+// of the mapping win over the mappings it merges with "<<", and earlier
+// merged mappings win over later ones.
+func (d *decoder) decodeFields(node *yaml.Node, v reflect.Value, set map[string]bool) {
+	if node.Kind == yaml.AliasNode {
+		node = node.Alias
+	}
+	if node.Kind != yaml.MappingNode {
+		d.errorf(node, "expected a mapping")
+		return
+	}
+
+	fields := structFields(v.Type())
+	seen := make(map[string]bool)
+	var merges []*yaml.Node
+
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		key, value := node.Content[i], node.Content[i+1]
+
+		if key.Value == "<<" {
+			merges = append(merges, value)
+			continue
+		}
+
+		index, ok := fields[key.Value]
+		if !ok {
+			d.errorf(key, "unknown field %q", key.Value)
+			continue
+		}
+
+		if seen[key.Value] {
+			d.errorf(key, "duplicate field %q", key.Value)
+			continue
+		}
+		seen[key.Value] = true
+
+		if set[key.Value] {
+			continue
+		}
+		set[key.Value] = true
+
+		d.decode(value, v.FieldByIndex(index))
+	}
+
+	for _, merge := range merges {
+		if merge.Kind == yaml.AliasNode {
+			merge = merge.Alias
+		}
+		if merge.Kind == yaml.SequenceNode {
+			for _, item := range merge.Content {
+				d.decodeFields(item, v, set)
+			}
+			continue
+		}
+		d.decodeFields(merge, v, set)
+	}
+}
+
+// structFields maps yaml keys to field indexes of a struct type, including inlined structs
+func structFields(t reflect.Type) map[string][]int {
+	fields := make(map[string][]int)
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		tag := strings.Split(field.Tag.Get("yaml"), ",")
+		name := tag[0]
+		if name == "-" || !field.IsExported() {
+			continue
+		}
+
+		if len(tag) > 1 && tag[1] == "inline" && field.Type.Kind() == reflect.Struct {
+			for key, index := range structFields(field.Type) {
+				fields[key] = append([]int{i}, index...)
+			}
+			continue
+		}
+
+		if name == "" {
+			name = strings.ToLower(field.Name)
+		}
+		fields[name] = []int{i}
+	}
+	return fields
+}
+
+func kindName(k reflect.Kind) string {
+	switch k {
+	case reflect.Bool:
+		return "a boolean"
+	case reflect.String:
+		return "a string"
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return "an integer"
+	case reflect.Float32, reflect.Float64:
+		return "a number"
+	}
+	return "a " + k.String()
+}
@@ -0,0 +1,220 @@
+package config
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/the-trybe/forge-deploy-cli/pkg/models"
+)
+
+const header = `organization: acme
+server: web-1
+github_repository: acme/app
+github_branch: main
+`
+
+func TestParseErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		want    []string
+	}{
+		{
+			name:    "unknown top-level key",
+			content: header + "region: eu\nsites: []\n",
+			want:    []string{`forge-deploy.yml:5:1: unknown field "region"`},
+		},
+		{
+			name:    "unknown site key",
+			content: header + "sites:\n  - name: app\n    php: php84\n",
+			want:    []string{`forge-deploy.yml:7:5: unknown field "php"`},
+		},
+		{
+			name:    "wrong scalar type",
+			content: header + "sites:\n  - name: app\n    certificate: maybe\n",
+			want:    []string{`forge-deploy.yml:7:18: cannot use "maybe" as a boolean`},
+		},
+		{
+			name:    "list instead of scalar",
+			content: header + "sites:\n  - name: [app]\n",
+			want:    []string{`forge-deploy.yml:6:11: expected a string`},
+		},
+		{
+			name:    "scalar instead of list",
+			content: header + "sites: app\n",
+			want:    []string{`forge-deploy.yml:5:8: expected a list`},
+		},
+		{
+			name:    "duplicate key",
+			content: header + "sites:\n  - name: app\n    name: other\n",
+			want:    []string{`forge-deploy.yml:7:5: duplicate field "name"`},
+		},
+		{
+			name:    "several errors",
+			content: header + "sites:\n  - name: app\n    php: php84\n    processes:\n      - name: queue\n        workers: 2\n",
+			want: []string{
+				`forge-deploy.yml:7:5: unknown field "php"`,
+				`forge-deploy.yml:10:9: unknown field "workers"`,
+			},
+		},
+		{
+			name:    "shared path error at its node",
+			content: header + "sites:\n  - name: app\n    shared_paths:\n      - from: storage\n        into: storage\n",
+			want:    []string{`forge-deploy.yml:9:9: unknown field "into" in shared path (expected 'from' and 'to')`},
+		},
+		{
+			name:    "anchor holder key",
+			content: "defaults: &defaults\n  php_version: php84\n" + header + "sites:\n  - <<: *defaults\n    name: app\n",
+			want:    []string{`forge-deploy.yml:1:1: unknown field "defaults"`},
+		},
+		{
+			name:    "unknown key in merged mapping",
+			content: header + "sites:\n  - &app\n    name: app\n    php: php84\n  - <<: *app\n    name: admin\n",
+			want:    []string{`forge-deploy.yml:8:5: unknown field "php"`},
+		},
+		{
+			name: "syntax error",
+			// The parser reports the line the indentation started on
+			content: header + "sites:\n  - name: app\n\tphp_version: php84\n",
+			want:    []string{`forge-deploy.yml:6: found a tab character that violates indentation`},
+		},
+		{
+			name:    "empty file",
+			content: "",
+			want:    []string{`forge-deploy.yml: file is empty`},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Parse([]byte(tt.content), "forge-deploy.yml")
+			if err == nil {
+				t.Fatal("expected an error")
+			}
+
+			var got []string
+			var list ErrorList
+			var single *Error
+			switch {
+			case errors.As(err, &list):
+				for _, e := range list {
+					got = append(got, e.Error())
+				}
+			case errors.As(err, &single):
+				got = []string{single.Error()}
+			default:
+				t.Fatalf("got %T (%v), want a positioned error", err, err)
+			}
+
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseMergeKeys(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		want    []models.SiteConfig
+	}{
+		{
+			name:    "explicit keys override merged keys",
+			content: header + "sites:\n  - &app\n    name: app\n    php_version: php84\n    web_dir: public\n  - <<: *app\n    name: admin\n    php_version: php83\n",
+			want: []models.SiteConfig{
+				{Name: "app", PHPVersion: "php84", WebDir: "public"},
+				{Name: "admin", PHPVersion: "php83", WebDir: "public"},
+			},
+		},
+		{
+			name:    "explicit keys before the merge key win",
+			content: header + "sites:\n  - &app\n    name: app\n    php_version: php84\n  - name: admin\n    php_version: php83\n    <<: *app\n",
+			want: []models.SiteConfig{
+				{Name: "app", PHPVersion: "php84"},
+				{Name: "admin", PHPVersion: "php83"},
+			},
+		},
+		{
+			name:    "earlier merged mappings win",
+			content: header + "sites:\n  - &a\n    name: a\n    php_version: php84\n    web_dir: web\n  - &b\n    name: b\n    php_version: php83\n    root_dir: api\n  - <<: [*a, *b]\n    name: c\n",
+			want: []models.SiteConfig{
+				{Name: "a", PHPVersion: "php84", WebDir: "web"},
+				{Name: "b", PHPVersion: "php83", RootDir: "api"},
+				{Name: "c", PHPVersion: "php84", WebDir: "web", RootDir: "api"},
+			},
+		},
+		{
+			name:    "nested merges",
+			content: header + "sites:\n  - &base\n    name: base\n    project_type: laravel\n  - &app\n    <<: *base\n    name: app\n    php_version: php84\n  - <<: *app\n    name: admin\n",
+			want: []models.SiteConfig{
+				{Name: "base", ProjectType: "laravel"},
+				{Name: "app", ProjectType: "laravel", PHPVersion: "php84"},
+				{Name: "admin", ProjectType: "laravel", PHPVersion: "php84"},
+			},
+		},
+		{
+			name:    "aliased values",
+			content: header + "sites:\n  - name: app\n    aliases: &aliases\n      - www.example.com\n    php_version: &php php84\n  - name: admin\n    aliases: *aliases\n    php_version: *php\n",
+			want: []models.SiteConfig{
+				{Name: "app", Aliases: []string{"www.example.com"}, PHPVersion: "php84"},
+				{Name: "admin", Aliases: []string{"www.example.com"}, PHPVersion: "php84"},
+			},
+		},
+		{
+			name:    "explicit empty list overrides merged list",
+			content: header + "sites:\n  - &app\n    name: app\n    aliases: [www.example.com]\n  - <<: *app\n    name: admin\n    aliases: []\n",
+			want: []models.SiteConfig{
+				{Name: "app", Aliases: []string{"www.example.com"}},
+				{Name: "admin", Aliases: []string{}},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg, err := Parse([]byte(tt.content), "forge-deploy.yml")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(cfg.Sites, tt.want) {
+				t.Errorf("got %+v, want %+v", cfg.Sites, tt.want)
+			}
+		})
+	}
+}
+
+func TestPosition(t *testing.T) {
+	content := header + "sites:\n  - name: app\n    aliases:\n      - www.example.com\n  - &admin\n    name: admin\n    nginx_template_variables:\n      app.host: example.com\n"
+	doc, err := ParseDocument([]byte(content), "forge-deploy.yml")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		path         string
+		line, column int
+	}{
+		{"", 1, 1},
+		{"server", 2, 1},
+		{"sites", 5, 1},
+		{"sites[0]", 6, 5},
+		{"sites[0].aliases", 7, 5},
+		{"sites[0].aliases[0]", 8, 9},
+		{"sites[1].name", 10, 5},
+		{"sites[0].php_version", 6, 5},
+		{"sites[5].name", 5, 1},
+		{`sites[1].nginx_template_variables["app.host"]`, 12, 7},
+		{`sites[1].nginx_template_variables["other"]`, 11, 5},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			line, column := doc.Position(tt.path)
+			if line != tt.line || column != tt.column {
+				t.Errorf("got %d:%d, want %d:%d", line, column, tt.line, tt.column)
+			}
+		})
+	}
+}
@@ -0,0 +1,670 @@
+package config
+
+import (
+	"bytes"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/the-trybe/forge-deploy-cli/pkg/models"
+)
+
+// Update applies cfg to the document's node tree in place. Comments, anchors
+// and key order of the original file survive, and only changed keys are touched.
+// Aliases of anchors that are no longer in the tree are replaced with the
+// values they referenced. Update fails when the updated document would not
+// decode to cfg.
+func (d *Document) Update(cfg *models.DeploymentConfig) error {
+	var src yaml.Node
+	if err := src.Encode(cfg); err != nil {
+		return fmt.Errorf("failed to encode config: %w", err)
+	}
+
+	if d.Root == nil || len(d.Root.Content) == 0 {
+		d.Root = &yaml.Node{Kind: yaml.DocumentNode, Content: []*yaml.Node{&src}}
+	} else {
+		d.Root.Content[0] = merge(d.Root.Content[0], &src, reflect.ValueOf(cfg))
+		inlineDanglingAliases(d.Root)
+	}
+
+	if err := d.verify(cfg); err != nil {
+		return err
+	}
+
+	d.Config = cfg
+	return nil
+}
+
+// verify decodes the document again and checks that it holds cfg
+func (d *Document) verify(cfg *models.DeploymentConfig) error {
+	data, err := d.Bytes()
+	if err != nil {
+		return fmt.Errorf("failed to encode config: %w", err)
+	}
+
+	decoded := &models.DeploymentConfig{}
+	var v interface{} = decoded
+	if d.Project != nil {
+		project := &models.ProjectConfig{}
+		decoded, v = &project.DeploymentConfig, project
+	}
+	if _, err := decodeStrict(data, d.Path, v); err != nil {
+		return fmt.Errorf("updated file does not load: %w", err)
+	}
+
+	// Compare the encoded forms, which do not tell nil and empty lists apart
+	want, err := yaml.Marshal(cfg)
+	if err != nil {
+		return fmt.Errorf("failed to encode config: %w", err)
+	}
+	got, err := yaml.Marshal(decoded)
+	if err != nil {
+		return fmt.Errorf("failed to encode config: %w", err)
+	}
+	if !bytes.Equal(want, got) {
+		return fmt.Errorf("updated file would not load as the new configuration; %s may use anchors or merge keys that cannot be updated in place", d.Path)
+	}
+	return nil
+}
+
+// Bytes encodes the document using the indentation of the original file.
+// The encoder drops blank lines, writes line comments one space after their
+// value and writes the head comment of an anchored list item after its
+// anchor; all three are put back the way the original file had them.
+func (d *Document) Bytes() ([]byte, error) {
+	var buf bytes.Buffer
+	encoder := yaml.NewEncoder(&buf)
+	encoder.SetIndent(d.indent())
+	clearMergeTags(d.Root)
+	comments := liftAnchorComments(d.Root)
+	err := encoder.Encode(d.Root)
+	if err == nil {
+		err = encoder.Close()
+	}
+	for node, comment := range comments {
+		node.HeadComment = comment
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	out := insertAnchorComments(buf.String(), comments)
+	return []byte(d.restoreLayout(out)), nil
+}
+
+// attachAnchorComments moves comments written above an anchored list item,
+// which the parser gives to the item's first key, back onto the item
+func attachAnchorComments(node *yaml.Node) {
+	for _, child := range node.Content {
+		if node.Kind == yaml.SequenceNode && child.Anchor != "" && child.HeadComment == "" &&
+			(child.Kind == yaml.MappingNode || child.Kind == yaml.SequenceNode) && len(child.Content) > 0 {
+			first := child.Content[0]
+			if first.HeadComment != "" && first.Line-commentLines(first.HeadComment) <= child.Line {
+				child.HeadComment, first.HeadComment = first.HeadComment, ""
+			}
+		}
+		attachAnchorComments(child)
+	}
+}
+
+// liftAnchorComments removes the head comments of anchored list items and
+// returns them by node
+func liftAnchorComments(node *yaml.Node) map[*yaml.Node]string {
+	comments := make(map[*yaml.Node]string)
+	var lift func(node *yaml.Node)
+	lift = func(node *yaml.Node) {
+		for _, child := range node.Content {
+			if node.Kind == yaml.SequenceNode && child.Anchor != "" && child.HeadComment != "" &&
+				(child.Kind == yaml.MappingNode || child.Kind == yaml.SequenceNode) {
+				comments[child] = child.HeadComment
+				child.HeadComment = ""
+			}
+			lift(child)
+		}
+	}
+	lift(node)
+	return comments
+}
+
+// insertAnchorComments writes the lifted comments above the "- &anchor"
+// lines of their items
+func insertAnchorComments(out string, comments map[*yaml.Node]string) string {
+	if len(comments) == 0 {
+		return out
+	}
+	byAnchor := make(map[string]string)
+	for node, comment := range comments {
+		byAnchor[node.Anchor] = comment
+	}
+
+	var b strings.Builder
+	for _, line := range strings.SplitAfter(out, "\n") {
+		trimmed := strings.TrimLeft(line, " ")
+		if anchor, ok := strings.CutPrefix(strings.TrimRight(trimmed, "\n"), "- &"); ok {
+			if comment, ok := byAnchor[anchor]; ok {
+				indent := line[:len(line)-len(trimmed)]
+				for _, c := range strings.Split(comment, "\n") {
+					if c != "" {
+						b.WriteString(indent)
+					}
+					b.WriteString(c + "\n")
+				}
+				delete(byAnchor, anchor)
+			}
+		}
+		b.WriteString(line)
+	}
+	return b.String()
+}
+
+// restoreLayout puts back the blank lines that preceded nodes, or their head
+// comments, and the spacing before line comments in the original file. out
+// must be the encoding of d.Root.
+func (d *Document) restoreLayout(out string) string {
+	if len(d.source) == 0 || d.Root == nil {
+		return out
+	}
+	var encoded yaml.Node
+	if err := yaml.Unmarshal([]byte(out), &encoded); err != nil {
+		return out
+	}
+
+	source := strings.Split(string(d.source), "\n")
+	lines := strings.SplitAfter(out, "\n")
+	blank := make(map[int]bool)
+	// Line comments by line, with the whitespace written before them
+	type lineComment struct{ gap, text string }
+	spacing := make(map[int]lineComment)
+
+	// The first entry of a list or mapping follows its parent without a blank
+	// line, even when the entry before it in the original file was removed.
+	// Mapping values start on the line of their key.
+	var walk func(original, encoded *yaml.Node, first bool)
+	walk = func(original, encoded *yaml.Node, first bool) {
+		if original.Line > 0 && encoded.Line > 0 && !first {
+			before := original.Line - commentLines(original.HeadComment) - 1
+			at := encoded.Line - commentLines(encoded.HeadComment)
+			if before >= 1 && strings.TrimSpace(source[before-1]) == "" && at > 1 && at <= len(lines) && strings.TrimSpace(lines[at-2]) != "" {
+				blank[at] = true
+			}
+		}
+		if comment := original.LineComment; comment != "" && comment == encoded.LineComment && original.Line > 0 && original.Line <= len(source) {
+			if gap, ok := commentGap(source[original.Line-1], comment); ok {
+				spacing[encoded.Line] = lineComment{gap, comment}
+			}
+		}
+		if original.Kind == yaml.AliasNode || original.Kind != encoded.Kind || len(original.Content) != len(encoded.Content) {
+			return
+		}
+		for i := range original.Content {
+			walk(original.Content[i], encoded.Content[i], i == 0 || original.Kind == yaml.MappingNode && i%2 == 1)
+		}
+	}
+	walk(d.Root, &encoded, true)
+
+	var b strings.Builder
+	for i, line := range lines {
+		if blank[i+1] {
+			b.WriteString("\n")
+		}
+		if comment, ok := spacing[i+1]; ok {
+			if index := strings.LastIndex(line, comment.text); index > 0 {
+				line = strings.TrimRight(line[:index], " ") + comment.gap + line[index:]
+			}
+		}
+		b.WriteString(line)
+	}
+	return b.String()
+}
+
+// commentGap returns the whitespace between the value and the line comment
+// of a source line
+func commentGap(line, comment string) (string, bool) {
+	index := strings.LastIndex(line, comment)
+	if index <= 0 {
+		return "", false
+	}
+	value := strings.TrimRight(line[:index], " \t")
+	if value == "" {
+		return "", false
+	}
+	return line[len(value):index], true
+}
+
+// commentLines returns the number of lines of a comment
+func commentLines(comment string) int {
+	if comment == "" {
+		return 0
+	}
+	return strings.Count(comment, "\n") + 1
+}
+
+// indent returns the indentation width used by the original file
+func (d *Document) indent() int {
+	width := 0
+	for _, line := range strings.Split(string(d.source), "\n") {
+		trimmed := strings.TrimLeft(line, " ")
+		n := len(line) - len(trimmed)
+		if n == 0 || trimmed == "" || strings.HasPrefix(trimmed, "#") {
+			continue
+		}
+		if width == 0 || n < width {
+			width = n
+		}
+	}
+	if width < 2 {
+		return 4
+	}
+	return width
+}
+
+// clearMergeTags drops the resolved !!merge tag of "<<" keys, which the
+// encoder would otherwise write out as "!!merge <<"
+func clearMergeTags(node *yaml.Node) {
+	if node.Kind == yaml.ScalarNode && node.Value == "<<" && node.Tag == "!!merge" {
+		node.Tag = ""
+	}
+	for _, child := range node.Content {
+		clearMergeTags(child)
+	}
+}
+
+// merge updates dst to hold the value of src and returns the node to keep in
+// the tree. v is the Go value src was encoded from, if known.
+func merge(dst, src *yaml.Node, v reflect.Value) *yaml.Node {
+	if dst.Kind == yaml.AliasNode {
+		if equal(dst, src) {
+			return dst
+		}
+		return withComments(src, dst)
+	}
+
+	if dst.Kind != src.Kind {
+		return withComments(src, dst)
+	}
+
+	switch dst.Kind {
+	case yaml.ScalarNode:
+		if !equal(dst, src) {
+			// Keep the original quoting unless the type or block style changes
+			if dst.Tag != src.Tag || src.Style != 0 {
+				dst.Style = src.Style
+			}
+			dst.Value = src.Value
+			dst.Tag = src.Tag
+		}
+	case yaml.MappingNode:
+		mergeMapping(dst, src, v)
+	case yaml.SequenceNode:
+		mergeSequence(dst, src, v)
+	}
+
+	return dst
+}
+
+// mergeMapping updates matching keys in place, inserts new keys after their
+// predecessor and removes keys that are no longer present. Struct fields
+// omitted from src because they hold the zero value are kept when dst
+// already spells out that value, and removed when they were cleared.
+func mergeMapping(dst, src *yaml.Node, v reflect.Value) {
+	v = indirect(v)
+	var fields map[string][]int
+	if v.IsValid() && v.Kind() == reflect.Struct {
+		fields = structFields(v.Type())
+	}
+
+	wanted := make(map[string]*yaml.Node)
+	for i := 0; i+1 < len(src.Content); i += 2 {
+		wanted[src.Content[i].Value] = src.Content[i+1]
+	}
+
+	// Drop keys that are gone, keeping merge keys
+	var content []*yaml.Node
+	for i := 0; i+1 < len(dst.Content); i += 2 {
+		key, value := dst.Content[i], dst.Content[i+1]
+		if _, ok := wanted[key.Value]; ok || key.Value == "<<" {
+			content = append(content, key, value)
+			continue
+		}
+		if index, ok := fields[key.Value]; ok {
+			field := v.FieldByIndex(index)
+			if zero, ok := zeroNode(field); ok && decodesToZero(value, field) {
+				content = append(content, key, merge(value, zero, reflect.Value{}))
+			}
+		}
+	}
+	dst.Content = content
+
+	insertAt := 0
+	if len(dst.Content) > 0 && dst.Content[0].Value == "<<" {
+		insertAt = 2
+	}
+	for i := 0; i+1 < len(src.Content); i += 2 {
+		key, value := src.Content[i], src.Content[i+1]
+
+		var field reflect.Value
+		if path, ok := fields[key.Value]; ok {
+			field = v.FieldByIndex(path)
+		}
+
+		if index := mappingIndex(dst, key.Value); index >= 0 {
+			dst.Content[index+1] = merge(dst.Content[index+1], value, field)
+			insertAt = index + 2
+			continue
+		}
+
+		if inherited := mergedValue(dst, key.Value); inherited != nil && decodesTo(inherited, value, field) {
+			continue
+		}
+
+		dst.Content = append(dst.Content[:insertAt], append([]*yaml.Node{key, value}, dst.Content[insertAt:]...)...)
+		insertAt += 2
+	}
+
+	// Zero values are omitted from src, but a merge key may still provide
+	// another value for them, which has to be overridden explicitly
+	for _, key := range fieldOrder(fields) {
+		index := fields[key]
+		if _, ok := wanted[key]; ok || mappingIndex(dst, key) >= 0 {
+			continue
+		}
+		inherited := mergedValue(dst, key)
+		if inherited == nil {
+			continue
+		}
+		field := v.FieldByIndex(index)
+		if zero, ok := zeroNode(field); ok && !decodesToZero(inherited, field) {
+			dst.Content = append(dst.Content, &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: key}, zero)
+		}
+	}
+}
+
+// mergeSequence matches items by site or process name when they have one,
+// by value for scalars and by position otherwise
+func mergeSequence(dst, src *yaml.Node, v reflect.Value) {
+	v = indirect(v)
+	if v.IsValid() && (v.Kind() != reflect.Slice || v.Len() != len(src.Content)) {
+		v = reflect.Value{}
+	}
+
+	names := make(map[string]bool)
+	for _, item := range src.Content {
+		names[itemName(item)] = true
+	}
+
+	used := make([]bool, len(dst.Content))
+	content := make([]*yaml.Node, 0, len(src.Content))
+
+	for i, item := range src.Content {
+		match := -1
+		if name := itemName(item); name != "" {
+			for j, candidate := range dst.Content {
+				if !used[j] && itemName(candidate) == name {
+					match = j
+					break
+				}
+			}
+		} else if item.Kind == yaml.ScalarNode {
+			for j, candidate := range dst.Content {
+				if !used[j] && equal(candidate, item) {
+					match = j
+					break
+				}
+			}
+		}
+		if match < 0 && i < len(dst.Content) && !used[i] && (itemName(dst.Content[i]) == "" || !names[itemName(dst.Content[i])]) {
+			match = i
+		}
+
+		if match < 0 {
+			content = append(content, item)
+			continue
+		}
+
+		var element reflect.Value
+		if v.IsValid() {
+			element = v.Index(i)
+		}
+		used[match] = true
+		content = append(content, merge(dst.Content[match], item, element))
+	}
+
+	dst.Content = content
+}
+
+// indirect dereferences pointers, returning an invalid value for nil ones
+func indirect(v reflect.Value) reflect.Value {
+	for v.IsValid() && v.Kind() == reflect.Ptr {
+		v = v.Elem()
+	}
+	return v
+}
+
+// zeroNode encodes field when it holds its zero value or is an empty list or
+// map, which the encoder leaves out just the same
+func zeroNode(field reflect.Value) (*yaml.Node, bool) {
+	empty := (field.Kind() == reflect.Slice || field.Kind() == reflect.Map) && field.Len() == 0
+	if !field.IsZero() && !empty {
+		return nil, false
+	}
+	var node yaml.Node
+	if err := node.Encode(field.Interface()); err != nil {
+		return nil, false
+	}
+	return &node, true
+}
+
+// itemName returns the value of the "name" key of a mapping node
+func itemName(node *yaml.Node) string {
+	if node.Kind == yaml.AliasNode {
+		node = node.Alias
+	}
+	if node.Kind != yaml.MappingNode {
+		return ""
+	}
+	if index := mappingIndex(node, "name"); index >= 0 {
+		return node.Content[index+1].Value
+	}
+	return ""
+}
+
+func mappingIndex(node *yaml.Node, key string) int {
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if node.Content[i].Value == key {
+			return i
+		}
+	}
+	return -1
+}
+
+// mergedValue returns the value a key inherits through a "<<" merge key
+func mergedValue(node *yaml.Node, key string) *yaml.Node {
+	index := mappingIndex(node, "<<")
+	if index < 0 {
+		return nil
+	}
+
+	sources := []*yaml.Node{node.Content[index+1]}
+	if sources[0].Kind == yaml.SequenceNode {
+		sources = sources[0].Content
+	}
+
+	for _, source := range sources {
+		if source.Kind == yaml.AliasNode {
+			source = source.Alias
+		}
+		if i := mappingIndex(source, key); i >= 0 {
+			return source.Content[i+1]
+		}
+	}
+	return nil
+}
+
+// equal reports whether two nodes decode to the same value
+func equal(a, b *yaml.Node) bool {
+	var x, y interface{}
+	if a.Decode(&x) != nil || b.Decode(&y) != nil {
+		return false
+	}
+	return reflect.DeepEqual(x, y)
+}
+
+// decodesTo reports whether node holds the value src was encoded from. When
+// that value is known, node is decoded into its type so that keys omitted
+// for holding the zero value compare equal.
+func decodesTo(node, src *yaml.Node, v reflect.Value) bool {
+	if !v.IsValid() {
+		return equal(node, src)
+	}
+	decoded := reflect.New(v.Type())
+	if err := node.Decode(decoded.Interface()); err != nil {
+		return false
+	}
+	return reflect.DeepEqual(decoded.Elem().Interface(), v.Interface())
+}
+
+// inlineDanglingAliases replaces aliases of anchors that are no longer in the
+// tree, e.g. because the site defining them was removed, with copies of the
+// values they referenced. Merge keys of such anchors are replaced with the
+// keys they merged.
+func inlineDanglingAliases(root *yaml.Node) {
+	anchors := make(map[*yaml.Node]bool)
+	var collect func(node *yaml.Node)
+	collect = func(node *yaml.Node) {
+		if node.Anchor != "" {
+			anchors[node] = true
+		}
+		for _, child := range node.Content {
+			collect(child)
+		}
+	}
+	collect(root)
+
+	var inline func(node *yaml.Node)
+	inline = func(node *yaml.Node) {
+		if node.Kind == yaml.MappingNode {
+			inlineMerges(node, anchors)
+		}
+		for i, child := range node.Content {
+			if child.Kind == yaml.AliasNode && !anchors[child.Alias] {
+				node.Content[i] = withComments(copyNode(child.Alias), child)
+			}
+			inline(node.Content[i])
+		}
+	}
+	inline(root)
+}
+
+// inlineMerges replaces "<<" sources of a mapping whose anchors are not in
+// anchors with the keys they contribute, so the mapping decodes the same
+func inlineMerges(node *yaml.Node, anchors map[*yaml.Node]bool) {
+	index := mappingIndex(node, "<<")
+	if index < 0 {
+		return
+	}
+
+	value := node.Content[index+1]
+	sources := []*yaml.Node{value}
+	if value.Kind == yaml.SequenceNode {
+		sources = value.Content
+	}
+
+	// Earlier sources win over later ones and explicit keys over all of them
+	provided := make(map[string]bool)
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		provided[node.Content[i].Value] = true
+	}
+
+	var kept, inlined []*yaml.Node
+	for _, source := range sources {
+		target := source
+		if target.Kind == yaml.AliasNode {
+			target = target.Alias
+		}
+		dangling := source.Kind == yaml.AliasNode && !anchors[source.Alias]
+		for i := 0; i+1 < len(target.Content); i += 2 {
+			key := target.Content[i].Value
+			if provided[key] && key != "<<" {
+				continue
+			}
+			provided[key] = true
+			if dangling {
+				inlined = append(inlined, copyNode(target.Content[i]), copyNode(target.Content[i+1]))
+			}
+		}
+		if !dangling {
+			kept = append(kept, source)
+		}
+	}
+	if len(kept) == len(sources) {
+		return
+	}
+
+	var replacement []*yaml.Node
+	switch {
+	case len(kept) == 1:
+		replacement = []*yaml.Node{node.Content[index], kept[0]}
+	case len(kept) > 1:
+		value.Content = kept
+		replacement = []*yaml.Node{node.Content[index], value}
+	}
+	replacement = append(replacement, inlined...)
+
+	content := append([]*yaml.Node(nil), node.Content[:index]...)
+	content = append(content, replacement...)
+	node.Content = append(content, node.Content[index+2:]...)
+}
+
+// copyNode returns a deep copy of node without anchors. Aliases in it are
+// kept and still point to their anchors.
+func copyNode(node *yaml.Node) *yaml.Node {
+	c := *node
+	c.Anchor = ""
+	if node.Kind != yaml.AliasNode {
+		c.Content = make([]*yaml.Node, len(node.Content))
+		for i, child := range node.Content {
+			c.Content[i] = copyNode(child)
+		}
+	}
+	return &c
+}
+
+// fieldOrder returns the keys of fields in the order of the struct fields
+func fieldOrder(fields map[string][]int) []string {
+	keys := make([]string, 0, len(fields))
+	for key := range fields {
+		keys = append(keys, key)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		a, b := fields[keys[i]], fields[keys[j]]
+		for k := 0; k < len(a) && k < len(b); k++ {
+			if a[k] != b[k] {
+				return a[k] < b[k]
+			}
+		}
+		return len(a) < len(b)
+	})
+	return keys
+}
+
+// decodesToZero reports whether node decodes to the zero value of field's
+// type, or to an empty list or map
+func decodesToZero(node *yaml.Node, field reflect.Value) bool {
+	decoded := reflect.New(field.Type())
+	if err := node.Decode(decoded.Interface()); err != nil {
+		return false
+	}
+	value := decoded.Elem()
+	return value.IsZero() || (value.Kind() == reflect.Slice || value.Kind() == reflect.Map) && value.Len() == 0
+}
+
+// withComments moves the comments of old onto node
+func withComments(node, old *yaml.Node) *yaml.Node {
+	node.HeadComment = old.HeadComment
+	node.LineComment = old.LineComment
+	node.FootComment = old.FootComment
+	return node
+}
@@ -0,0 +1,275 @@
+package config
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/the-trybe/forge-deploy-cli/pkg/models"
+)
+
+const anchoredConfig = `# Deployment of the acme app
+organization: acme
+server: web-1
+github_repository: acme/app
+github_branch: main
+sites:
+    # main app
+    - &app
+      name: app
+      project_type: laravel
+      php_version: php84 # matches the server
+      processes:
+        - name: queue
+          command: php artisan queue:work
+
+    - <<: *app
+      name: admin
+      web_dir: admin/public
+      processes: []
+`
+
+func TestUpdate(t *testing.T) {
+	tests := []struct {
+		name   string
+		source string
+		edit   func(cfg *models.DeploymentConfig)
+		want   string
+	}{
+		{
+			name:   "unchanged",
+			source: anchoredConfig,
+			edit:   func(cfg *models.DeploymentConfig) {},
+			want:   anchoredConfig,
+		},
+		{
+			name:   "scalar edit",
+			source: anchoredConfig,
+			edit:   func(cfg *models.DeploymentConfig) { cfg.Sites[0].PHPVersion = "php83" },
+			want: strings.Replace(strings.Replace(anchoredConfig, "php84 #", "php83 #", 1),
+				"      web_dir: admin/public\n", "      web_dir: admin/public\n      php_version: php84\n", 1),
+		},
+		{
+			name:   "edit of a merging site",
+			source: anchoredConfig,
+			edit:   func(cfg *models.DeploymentConfig) { cfg.Sites[1].WebDir = "backoffice/public" },
+			want:   strings.Replace(anchoredConfig, "admin/public", "backoffice/public", 1),
+		},
+		{
+			name:   "override of a merged key",
+			source: anchoredConfig,
+			edit:   func(cfg *models.DeploymentConfig) { cfg.Sites[1].PHPVersion = "php83" },
+			want:   strings.Replace(anchoredConfig, "      web_dir: admin/public\n", "      web_dir: admin/public\n      php_version: php83\n", 1),
+		},
+		{
+			name:   "explicit empty list kept",
+			source: anchoredConfig,
+			edit:   func(cfg *models.DeploymentConfig) { cfg.Sites[1].Processes = nil },
+			want:   anchoredConfig,
+		},
+		{
+			name:   "new key",
+			source: anchoredConfig,
+			edit:   func(cfg *models.DeploymentConfig) { cfg.Sites[0].Certificate = true },
+			want: strings.Replace(strings.Replace(anchoredConfig, "          command: php artisan queue:work\n", "          command: php artisan queue:work\n      certificate: true\n", 1),
+				"      processes: []\n", "      processes: []\n      certificate: false\n", 1),
+		},
+		{
+			name: "explicit zero value kept",
+			source: `organization: acme
+server: web-1
+github_repository: acme/app
+github_branch: main
+sites:
+  - name: app
+    certificate: false
+`,
+			edit: func(cfg *models.DeploymentConfig) { cfg.Sites[0].PHPVersion = "php84" },
+			want: `organization: acme
+server: web-1
+github_repository: acme/app
+github_branch: main
+sites:
+  - name: app
+    php_version: php84
+    certificate: false
+`,
+		},
+		{
+			name:   "removed anchor holder",
+			source: anchoredConfig,
+			edit:   func(cfg *models.DeploymentConfig) { cfg.Sites = cfg.Sites[1:] },
+			want: `# Deployment of the acme app
+organization: acme
+server: web-1
+github_repository: acme/app
+github_branch: main
+sites:
+    - project_type: laravel
+      php_version: php84 # matches the server
+      name: admin
+      web_dir: admin/public
+      processes: []
+`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			doc, err := ParseDocument([]byte(tt.source), "forge-deploy.yml")
+			if err != nil {
+				t.Fatal(err)
+			}
+			cfg, err := Parse([]byte(tt.source), "forge-deploy.yml")
+			if err != nil {
+				t.Fatal(err)
+			}
+			tt.edit(cfg)
+
+			if err := doc.Update(cfg); err != nil {
+				t.Fatalf("Update: %v", err)
+			}
+			got, err := doc.Bytes()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(got) != tt.want {
+				t.Errorf("got:\n%s\nwant:\n%s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestUpdateKeepsExplicitEmptyOverride(t *testing.T) {
+	doc, err := ParseDocument([]byte(anchoredConfig), "forge-deploy.yml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	cfg := doc.Config
+	cfg.Sites[1].WebDir = "public"
+
+	if err := doc.Update(cfg); err != nil {
+		t.Fatalf("Update: %v", err)
+	}
+	got, err := doc.Bytes()
+	if err != nil {
+		t.Fatal(err)
+	}
+	reloaded, err := Parse(got, "forge-deploy.yml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n := len(reloaded.Sites[1].Processes); n != 0 {
+		t.Errorf("admin has %d processes after the update, want 0:\n%s", n, got)
+	}
+}
+
+func TestUpdateReplacesChangedAliases(t *testing.T) {
+	source := `organization: acme
+server: web-1
+github_repository: acme/app
+github_branch: main
+sites:
+  - name: app
+    php_version: &php php84
+  - name: admin
+    php_version: *php
+`
+	doc, err := ParseDocument([]byte(source), "forge-deploy.yml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	doc.Config.Sites[0].PHPVersion = "php83"
+
+	if err := doc.Update(doc.Config); err != nil {
+		t.Fatalf("Update: %v", err)
+	}
+	got, err := doc.Bytes()
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := strings.Replace(strings.Replace(source, "&php php84", "&php php83", 1), "*php", "php84", 1)
+	if string(got) != want {
+		t.Errorf("got:\n%s\nwant:\n%s", got, want)
+	}
+}
+
+const spacedConfig = `organization: acme
+server: web-1   # production
+github_repository: acme/app
+github_branch: main
+sites:
+  - name: app
+    php_version: php83   # pinned
+
+  - name: admin
+    php_version: php84
+
+  # public API
+  - name: api
+    php_version: php84
+`
+
+func TestUpdateLayout(t *testing.T) {
+	tests := []struct {
+		name string
+		edit func(cfg *models.DeploymentConfig)
+		want string
+	}{
+		{
+			name: "unchanged",
+			edit: func(cfg *models.DeploymentConfig) {},
+			want: spacedConfig,
+		},
+		{
+			name: "comment spacing kept on an edited value",
+			edit: func(cfg *models.DeploymentConfig) { cfg.Sites[0].PHPVersion = "php84" },
+			want: strings.Replace(spacedConfig, "php83   # pinned", "php84   # pinned", 1),
+		},
+		{
+			name: "first site removed",
+			edit: func(cfg *models.DeploymentConfig) { cfg.Sites = cfg.Sites[1:] },
+			want: strings.Replace(spacedConfig, "  - name: app\n    php_version: php83   # pinned\n\n", "", 1),
+		},
+		{
+			name: "middle site removed",
+			edit: func(cfg *models.DeploymentConfig) { cfg.Sites = append(cfg.Sites[:1], cfg.Sites[2]) },
+			want: strings.Replace(spacedConfig, "  - name: admin\n    php_version: php84\n\n", "", 1),
+		},
+		{
+			name: "last site removed",
+			edit: func(cfg *models.DeploymentConfig) { cfg.Sites = cfg.Sites[:2] },
+			want: strings.Replace(spacedConfig, "\n  # public API\n  - name: api\n    php_version: php84\n", "", 1),
+		},
+		{
+			name: "cleared key dropped",
+			edit: func(cfg *models.DeploymentConfig) { cfg.Sites[1].PHPVersion = "" },
+			want: strings.Replace(spacedConfig, "  - name: admin\n    php_version: php84\n", "  - name: admin\n", 1),
+		},
+		{
+			name: "cleared commented key dropped",
+			edit: func(cfg *models.DeploymentConfig) { cfg.Sites[0].PHPVersion = "" },
+			want: strings.Replace(spacedConfig, "    php_version: php83   # pinned\n", "", 1),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			doc, err := ParseDocument([]byte(spacedConfig), "forge-deploy.yml")
+			if err != nil {
+				t.Fatal(err)
+			}
+			tt.edit(doc.Config)
+
+			if err := doc.Update(doc.Config); err != nil {
+				t.Fatalf("Update: %v", err)
+			}
+			got, err := doc.Bytes()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(got) != tt.want {
+				t.Errorf("got:\n%s\nwant:\n%s", got, tt.want)
+			}
+		})
+	}
+}
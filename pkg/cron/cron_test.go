@@ -0,0 +1,183 @@
+package cron
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+// start is a Thursday
+var start = time.Date(2026, time.January, 15, 10, 7, 30, 0, time.UTC)
+
+func date(year int, month time.Month, day, hour, minute int) time.Time {
+	return time.Date(year, month, day, hour, minute, 0, 0, time.UTC)
+}
+
+func TestNext(t *testing.T) {
+	tests := []struct {
+		name string
+		expr string
+		from time.Time
+		want time.Time
+	}{
+		{"every minute", "* * * * *", start, date(2026, 1, 15, 10, 8)},
+		{"step", "*/15 * * * *", start, date(2026, 1, 15, 10, 15)},
+		{"step from a value", "1/20 * * * *", start, date(2026, 1, 15, 10, 21)},
+		{"range with step", "5-10/2 * * * *", start, date(2026, 1, 15, 10, 9)},
+		{"list", "0,30 * * * *", start, date(2026, 1, 15, 10, 30)},
+		{"hour range on weekdays", "0 9-17 * * mon-fri", start, date(2026, 1, 15, 11, 0)},
+		{"weekend names", "30 6 * * sat,sun", start, date(2026, 1, 17, 6, 30)},
+		{"names are case insensitive", "0 0 * JAN MON", start, date(2026, 1, 19, 0, 0)},
+		{"month name", "0 0 1 jan *", start, date(2027, 1, 1, 0, 0)},
+		{"sunday as 7", "0 0 * * 7", start, date(2026, 1, 18, 0, 0)},
+		{"sunday as 0", "0 0 * * 0", start, date(2026, 1, 18, 0, 0)},
+		{"@hourly", "@hourly", start, date(2026, 1, 15, 11, 0)},
+		{"@daily", "@daily", start, date(2026, 1, 16, 0, 0)},
+		{"@midnight", "@midnight", start, date(2026, 1, 16, 0, 0)},
+		{"@weekly", "@weekly", start, date(2026, 1, 18, 0, 0)},
+		{"@monthly", "@monthly", start, date(2026, 2, 1, 0, 0)},
+		{"@yearly", "@yearly", start, date(2027, 1, 1, 0, 0)},
+		{"@annually", "@ANNUALLY", start, date(2027, 1, 1, 0, 0)},
+		// Both day fields restricted: either may match
+		{"day of month or day of week", "0 0 20 * 1", start, date(2026, 1, 19, 0, 0)},
+		{"day of month before day of week", "0 0 16 * 1", start, date(2026, 1, 16, 0, 0)},
+		// A day field starting with "*" restricts together with the other
+		{"stepped day of month and day of week", "0 0 */2 * 1", date(2026, 1, 20, 0, 0), date(2026, 2, 9, 0, 0)},
+		{"leap day", "0 0 29 2 *", start, date(2028, 2, 29, 0, 0)},
+		{"exactly on a run", "0 11 * * *", date(2026, 1, 15, 11, 0), date(2026, 1, 16, 11, 0)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, err := Parse(tt.expr)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := s.Next(tt.from); !got.Equal(tt.want) {
+				t.Errorf("Next(%s) = %s, want %s", tt.from.Format(time.RFC3339), got.Format(time.RFC3339), tt.want.Format(time.RFC3339))
+			}
+		})
+	}
+}
+
+func TestNeverRuns(t *testing.T) {
+	for _, expr := range []string{
+		"0 0 30 2 *",
+		"0 0 31 4,6,9,11 *",
+		"0 0 31 2-4/2 *",
+	} {
+		s, err := Parse(expr)
+		if err != nil {
+			t.Fatalf("%s: %v", expr, err)
+		}
+		if next := s.Next(start); !next.IsZero() {
+			t.Errorf("%s: got %s, want the zero time", expr, next)
+		}
+		if runs := s.NextN(start, 3); len(runs) != 0 {
+			t.Errorf("%s: got %d runs, want none", expr, len(runs))
+		}
+	}
+}
+
+func TestNextN(t *testing.T) {
+	s, err := Parse("0 */6 * * *")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := []time.Time{
+		date(2026, 1, 15, 12, 0),
+		date(2026, 1, 15, 18, 0),
+		date(2026, 1, 16, 0, 0),
+		date(2026, 1, 16, 6, 0),
+	}
+	got := s.NextN(start, len(want))
+	if len(got) != len(want) {
+		t.Fatalf("got %d runs, want %d", len(got), len(want))
+	}
+	for i := range want {
+		if !got[i].Equal(want[i]) {
+			t.Errorf("run %d: got %s, want %s", i, got[i], want[i])
+		}
+	}
+}
+
+func TestNextKeepsLocation(t *testing.T) {
+	paris, err := time.LoadLocation("Europe/Paris")
+	if err != nil {
+		t.Skip("time zone data unavailable")
+	}
+
+	s, err := Parse("30 8 * * *")
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := s.Next(time.Date(2026, 1, 15, 9, 0, 0, 0, paris))
+	if want := time.Date(2026, 1, 16, 8, 30, 0, 0, paris); !got.Equal(want) || got.Location() != paris {
+		t.Errorf("got %s, want %s", got, want)
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	tests := []struct {
+		expr    string
+		message string
+	}{
+		{"", "must have 5 fields"},
+		{"* * * *", "must have 5 fields (minute hour day-of-month month day-of-week), got 4"},
+		{"* * * * * *", "must have 5 fields (minute hour day-of-month month day-of-week), got 6"},
+		{"60 * * * *", "minute field: 60 is out of range 0-59"},
+		{"* 24 * * *", "hour field: 24 is out of range 0-23"},
+		{"* * 0 * *", "day of month field: 0 is out of range 1-31"},
+		{"* * 32 * *", "day of month field: 32 is out of range 1-31"},
+		{"* * * 13 *", "month field: 13 is out of range 1-12"},
+		{"* * * * 8", "day of week field: 8 is out of range 0-7"},
+		{"5-1 * * * *", `range "5-1" is backwards`},
+		{"*/0 * * * *", `invalid step "0"`},
+		{"*/x * * * *", `invalid step "x"`},
+		{"x * * * *", `minute field: invalid value "x"`},
+		{"* * * foo *", `month field: invalid value "foo"`},
+		{"* * * * mon-", `day of week field: invalid value ""`},
+		{"@every 5m", "unknown cron macro"},
+		{"@reboot", "unknown cron macro"},
+	}
+
+	for _, tt := range tests {
+		_, err := Parse(tt.expr)
+		if err == nil {
+			t.Errorf("%q: got no error, want %q", tt.expr, tt.message)
+			continue
+		}
+		if !strings.Contains(err.Error(), tt.message) {
+			t.Errorf("%q: got %q, want %q", tt.expr, err, tt.message)
+		}
+	}
+}
+
+func TestExpression(t *testing.T) {
+	tests := []struct {
+		frequency, cron, want string
+	}{
+		{"nightly", "", "0 0 * * *"},
+		{"weekly", "", "0 0 * * 0"},
+		{"reboot", "", ""},
+		{"custom", "*/5 * * * *", "*/5 * * * *"},
+		{"", "*/5 * * * *", "*/5 * * * *"},
+	}
+
+	for _, tt := range tests {
+		if got := Expression(tt.frequency, tt.cron); got != tt.want {
+			t.Errorf("Expression(%q, %q) = %q, want %q", tt.frequency, tt.cron, got, tt.want)
+		}
+	}
+
+	for _, name := range FrequencyNames {
+		expr := Frequencies[name]
+		if expr == "" {
+			continue
+		}
+		if _, err := Parse(expr); err != nil {
+			t.Errorf("frequency %s: %v", name, err)
+		}
+	}
+}
@@ -0,0 +1,190 @@
+package detect
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/the-trybe/forge-deploy-cli/pkg/models"
+	presets "github.com/the-trybe/forge-deploy-cli/pkg/processes"
+)
+
+// Project describes what could be detected about a project directory
+type Project struct {
+	Laravel     bool
+	PHPVersion  string
+	WebDir      string
+	Composer    bool
+	PackageJSON bool
+	NpmBuild    bool
+	Octane      bool
+	Horizon     bool
+	Reverb      bool
+	Pulse       bool
+}
+
+type composerJSON struct {
+	Require    map[string]string `json:"require"`
+	RequireDev map[string]string `json:"require-dev"`
+}
+
+type packageJSON struct {
+	Scripts map[string]string `json:"scripts"`
+}
+
+// Detect inspects composer.json, artisan, package.json and the public
+// directory of dir. Files that do not exist are skipped.
+func Detect(dir string) (*Project, error) {
+	p := &Project{}
+
+	var composer composerJSON
+	found, err := readJSON(filepath.Join(dir, "composer.json"), &composer)
+	if err != nil {
+		return nil, err
+	}
+	if found {
+		p.Composer = true
+		p.PHPVersion = PHPVersion(composer.Require["php"])
+
+		_, laravel := composer.Require["laravel/framework"]
+		_, p.Octane = composer.Require["laravel/octane"]
+		_, p.Horizon = composer.Require["laravel/horizon"]
+		_, p.Reverb = composer.Require["laravel/reverb"]
+		_, p.Pulse = composer.Require["laravel/pulse"]
+		p.Laravel = laravel
+	}
+
+	if exists(filepath.Join(dir, "artisan")) {
+		p.Laravel = true
+	}
+
+	var pkg packageJSON
+	if p.PackageJSON, err = readJSON(filepath.Join(dir, "package.json"), &pkg); err != nil {
+		return nil, err
+	}
+	_, p.NpmBuild = pkg.Scripts["build"]
+
+	for _, webDir := range []string{"public", "web"} {
+		if isDir(filepath.Join(dir, webDir)) {
+			p.WebDir = webDir
+			break
+		}
+	}
+
+	return p, nil
+}
+
+// ProjectType returns the detected project type, or an empty string
+func (p *Project) ProjectType() string {
+	if p.Laravel {
+		return "laravel"
+	}
+	if p.Composer || p.PackageJSON {
+		return "other"
+	}
+	return ""
+}
+
+// Processes returns the daemons implied by the installed packages
+func (p *Project) Processes() []models.Process {
+	var processes []models.Process
+	for _, installed := range []struct {
+		preset  string
+		enabled bool
+	}{
+		{"horizon", p.Horizon},
+		{"octane", p.Octane},
+		{"reverb", p.Reverb},
+		{"pulse", p.Pulse},
+	} {
+		if preset, ok := presets.Find(installed.preset); ok && installed.enabled {
+			processes = append(processes, preset.Process(p.PHPVersion))
+		}
+	}
+	return processes
+}
+
+// Defaults returns site settings to pre-fill the prompts with
+func (p *Project) Defaults() *models.SiteConfig {
+	return &models.SiteConfig{
+		ProjectType:                 p.ProjectType(),
+		PHPVersion:                  p.PHPVersion,
+		WebDir:                      p.WebDir,
+		InstallComposerDependencies: p.Composer,
+		Processes:                   p.Processes(),
+	}
+}
+
+// Summary describes the detected project in one line
+func (p *Project) Summary() string {
+	var parts []string
+	if t := p.ProjectType(); t != "" {
+		parts = append(parts, "project type "+t)
+	}
+	if p.PHPVersion != "" {
+		parts = append(parts, "PHP "+p.PHPVersion)
+	}
+	if p.WebDir != "" {
+		parts = append(parts, "web dir "+p.WebDir)
+	}
+
+	var packages []string
+	for name, ok := range map[string]bool{"octane": p.Octane, "horizon": p.Horizon, "reverb": p.Reverb, "pulse": p.Pulse} {
+		if ok {
+			packages = append(packages, name)
+		}
+	}
+	sort.Strings(packages)
+	if len(packages) > 0 {
+		parts = append(parts, "packages "+strings.Join(packages, ", "))
+	}
+
+	return strings.Join(parts, "; ")
+}
+
+var phpConstraintVersion = regexp.MustCompile(`(\d+)\.(\d+)`)
+
+// PHPVersion converts a composer "php" constraint such as "^8.2" or
+// ">=8.1 <8.4" into a Forge PHP version, using the lowest version named.
+func PHPVersion(constraint string) string {
+	lowest, lowestMajor, lowestMinor := "", 0, 0
+	for _, m := range phpConstraintVersion.FindAllStringSubmatch(constraint, -1) {
+		major, _ := strconv.Atoi(m[1])
+		minor, _ := strconv.Atoi(m[2])
+		// Compared as numbers, since 8.10 is not lower than 8.2
+		if lowest == "" || major < lowestMajor || major == lowestMajor && minor < lowestMinor {
+			lowest, lowestMajor, lowestMinor = fmt.Sprintf("php%d%d", major, minor), major, minor
+		}
+	}
+	return lowest
+}
+
+// readJSON decodes a JSON file, reporting whether it exists
+func readJSON(path string, v interface{}) (bool, error) {
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	if err := json.Unmarshal(data, v); err != nil {
+		return true, fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+	return true, nil
+}
+
+func exists(path string) bool {
+	_, err := os.Stat(path)
+	return err == nil
+}
+
+func isDir(path string) bool {
+	info, err := os.Stat(path)
+	return err == nil && info.IsDir()
+}
@@ -0,0 +1,128 @@
+package detect
+
+import (
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestDetect(t *testing.T) {
+	tests := []struct {
+		dir         string
+		projectType string
+		php         string
+		webDir      string
+		composer    bool
+		npmBuild    bool
+		processes   []string
+		summary     string
+	}{
+		{
+			dir:         "octane",
+			projectType: "laravel",
+			php:         "php82",
+			webDir:      "public",
+			composer:    true,
+			npmBuild:    true,
+			processes:   []string{"octane"},
+			summary:     "project type laravel; PHP php82; web dir public; packages octane",
+		},
+		{
+			// No artisan file: laravel/framework is enough
+			dir:         "horizon",
+			projectType: "laravel",
+			php:         "php83",
+			webDir:      "public",
+			composer:    true,
+			processes:   []string{"horizon", "pulse"},
+			summary:     "project type laravel; PHP php83; web dir public; packages horizon, pulse",
+		},
+		{
+			dir:         "legacy",
+			projectType: "other",
+			php:         "php74",
+			webDir:      "web",
+			composer:    true,
+			summary:     "project type other; PHP php74; web dir web",
+		},
+		{
+			dir:         "node",
+			projectType: "other",
+			summary:     "project type other",
+		},
+		{
+			dir: "empty",
+		},
+		{
+			dir: "missing",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.dir, func(t *testing.T) {
+			p, err := Detect(filepath.Join("testdata", tt.dir))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if p.ProjectType() != tt.projectType || p.PHPVersion != tt.php || p.WebDir != tt.webDir {
+				t.Errorf("got project type %q, php %q and web dir %q, want %q, %q and %q",
+					p.ProjectType(), p.PHPVersion, p.WebDir, tt.projectType, tt.php, tt.webDir)
+			}
+			if p.Composer != tt.composer || p.NpmBuild != tt.npmBuild {
+				t.Errorf("got composer %v and npm build %v, want %v and %v", p.Composer, p.NpmBuild, tt.composer, tt.npmBuild)
+			}
+
+			var names []string
+			for _, process := range p.Processes() {
+				names = append(names, process.Name)
+				if !strings.Contains(process.Command, "artisan") {
+					t.Errorf("process %s: got command %q", process.Name, process.Command)
+				}
+			}
+			if !reflect.DeepEqual(names, tt.processes) {
+				t.Errorf("got processes %v, want %v", names, tt.processes)
+			}
+
+			if got := p.Summary(); got != tt.summary {
+				t.Errorf("got summary %q, want %q", got, tt.summary)
+			}
+
+			defaults := p.Defaults()
+			if defaults.ProjectType != tt.projectType || defaults.PHPVersion != tt.php || defaults.WebDir != tt.webDir || defaults.InstallComposerDependencies != tt.composer {
+				t.Errorf("got defaults %+v", defaults)
+			}
+		})
+	}
+}
+
+func TestDetectInvalidJSON(t *testing.T) {
+	_, err := Detect(filepath.Join("testdata", "invalid"))
+	if err == nil || !strings.Contains(err.Error(), "failed to parse") || !strings.Contains(err.Error(), "composer.json") {
+		t.Errorf("got %v, want a parse error naming composer.json", err)
+	}
+}
+
+func TestPHPVersion(t *testing.T) {
+	tests := []struct {
+		constraint string
+		want       string
+	}{
+		{"^8.2", "php82"},
+		{"~8.3.0", "php83"},
+		{">=8.1 <8.4", "php81"},
+		{"^7.4|^8.0", "php74"},
+		{"^8.3 || ^8.2", "php82"},
+		{">=8.10 || ^8.2", "php82"},
+		{"8.4.*", "php84"},
+		{"*", ""},
+		{"", ""},
+	}
+
+	for _, tt := range tests {
+		if got := PHPVersion(tt.constraint); got != tt.want {
+			t.Errorf("PHPVersion(%q): got %q, want %q", tt.constraint, got, tt.want)
+		}
+	}
+}
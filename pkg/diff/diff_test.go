@@ -0,0 +1,141 @@
+package diff
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+// numbered returns the lines "1" to "n", each ending with a newline
+func numbered(n int) []string {
+	lines := make([]string, n)
+	for i := range lines {
+		lines[i] = fmt.Sprint(i + 1)
+	}
+	return lines
+}
+
+func text(lines []string) string {
+	if len(lines) == 0 {
+		return ""
+	}
+	return strings.Join(lines, "\n") + "\n"
+}
+
+// replace returns a copy of lines with the given 1-based lines replaced
+func replace(lines []string, changes map[int]string) []string {
+	out := append([]string(nil), lines...)
+	for n, line := range changes {
+		out[n-1] = line
+	}
+	return out
+}
+
+func TestUnified(t *testing.T) {
+	tests := []struct {
+		name     string
+		old, new string
+		context  int
+		want     []string
+	}{
+		{
+			name: "equal",
+			old:  "a\nb\n",
+			new:  "a\nb\n",
+			want: nil,
+		},
+		{
+			name:    "single change",
+			old:     text(numbered(10)),
+			new:     text(replace(numbered(10), map[int]string{5: "five"})),
+			context: 2,
+			want:    []string{"@@ -3,5 +3,5 @@", " 3", " 4", "-5", "+five", " 6", " 7"},
+		},
+		{
+			name:    "changes within twice the context share a hunk",
+			old:     text(numbered(12)),
+			new:     text(replace(numbered(12), map[int]string{3: "three", 8: "eight"})),
+			context: 2,
+			want:    []string{"@@ -1,10 +1,10 @@", " 1", " 2", "-3", "+three", " 4", " 5", " 6", " 7", "-8", "+eight", " 9", " 10"},
+		},
+		{
+			name:    "changes further apart get separate hunks",
+			old:     text(numbered(12)),
+			new:     text(replace(numbered(12), map[int]string{3: "three", 9: "nine"})),
+			context: 2,
+			want: []string{
+				"@@ -1,5 +1,5 @@", " 1", " 2", "-3", "+three", " 4", " 5",
+				"@@ -7,5 +7,5 @@", " 7", " 8", "-9", "+nine", " 10", " 11",
+			},
+		},
+		{
+			name:    "pure addition",
+			old:     "a\nb\nc\n",
+			new:     "a\nb\nnew\nc\n",
+			context: 1,
+			want:    []string{"@@ -2,2 +2,3 @@", " b", "+new", " c"},
+		},
+		{
+			name:    "pure deletion",
+			old:     "a\nb\nc\nd\n",
+			new:     "a\nd\n",
+			context: 1,
+			want:    []string{"@@ -1,4 +1,2 @@", " a", "-b", "-c", " d"},
+		},
+		{
+			name:    "addition without context",
+			old:     "a\nb\n",
+			new:     "a\nnew\nb\n",
+			context: 0,
+			want:    []string{"@@ -1,0 +2,1 @@", "+new"},
+		},
+		{
+			name:    "empty old text",
+			old:     "",
+			new:     "a\nb\n",
+			context: 3,
+			want:    []string{"@@ -0,0 +1,2 @@", "+a", "+b"},
+		},
+		{
+			name:    "empty new text",
+			old:     "a\nb\n",
+			new:     "",
+			context: 3,
+			want:    []string{"@@ -1,2 +0,0 @@", "-a", "-b"},
+		},
+		{
+			name:    "final newline added",
+			old:     "a\nb",
+			new:     "a\nb\n",
+			context: 3,
+			want:    []string{"@@ -1,2 +1,2 @@", " a", "-b", `\ No newline at end of file`, "+b"},
+		},
+		{
+			name:    "final newline dropped",
+			old:     "a\nb\n",
+			new:     "a\nb",
+			context: 3,
+			want:    []string{"@@ -1,2 +1,2 @@", " a", "-b", "+b", `\ No newline at end of file`},
+		},
+		{
+			name:    "unchanged last line without newline",
+			old:     "a\nb",
+			new:     "x\nb",
+			context: 3,
+			want:    []string{"@@ -1,2 +1,2 @@", "-a", "+x", " b", `\ No newline at end of file`},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := Unified("a/file", "b/file", tt.old, tt.new, tt.context)
+			want := ""
+			if tt.want != nil {
+				want = "--- a/file\n+++ b/file\n" + strings.Join(tt.want, "\n") + "\n"
+			}
+			if got != want {
+				t.Errorf("got\n%s\nwant\n%s", got, want)
+			}
+		})
+	}
+}
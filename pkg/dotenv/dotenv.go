@@ -0,0 +1,318 @@
+// Package dotenv parses .env files as used by Laravel and phpdotenv: comments,
+// export prefixes, single- and double-quoted values spanning several lines,
+// and ${VAR} interpolation.
+package dotenv
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+)
+
+// Entry is a single variable of an env file
+type Entry struct {
+	Key   string
+	Value string
+	// Quote is the quote character around the value, or 0 when unquoted
+	Quote byte
+	// Export is set when the line starts with "export"
+	Export bool
+	// Line and EndLine are the first and last line of the entry, starting at 1
+	Line    int
+	EndLine int
+}
+
+// Interpolated reports whether ${VAR} references in the value are expanded
+func (e Entry) Interpolated() bool {
+	return e.Quote != '\''
+}
+
+// References returns the variable names the value references with ${VAR}
+func (e Entry) References() []string {
+	if !e.Interpolated() {
+		return nil
+	}
+	var names []string
+	for _, m := range reference.FindAllStringSubmatch(e.Value, -1) {
+		names = append(names, m[1])
+	}
+	return names
+}
+
+// Error is a line that could not be parsed
+type Error struct {
+	Line    int
+	Message string
+}
+
+func (e Error) Error() string {
+	return fmt.Sprintf("line %d: %s", e.Line, e.Message)
+}
+
+// File is a parsed env file. Malformed lines are collected in Errors and
+// skipped, so the rest of the file is still available.
+type File struct {
+	Entries []Entry
+	Errors  []Error
+}
+
+var (
+	keyPattern = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_.]*$`)
+	reference  = regexp.MustCompile(`\$\{([A-Za-z_][A-Za-z0-9_.]*)\}`)
+)
+
+// Parse parses the content of an env file
+func Parse(content string) *File {
+	f := &File{}
+	lines := strings.Split(strings.ReplaceAll(content, "\r\n", "\n"), "\n")
+
+	for i := 0; i < len(lines); i++ {
+		lineNo := i + 1
+		line := strings.TrimSpace(lines[i])
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		entry := Entry{Line: lineNo, EndLine: lineNo}
+		if rest, ok := strings.CutPrefix(line, "export "); ok {
+			entry.Export = true
+			line = strings.TrimSpace(rest)
+		}
+
+		key, value, ok := strings.Cut(line, "=")
+		if !ok {
+			f.Errors = append(f.Errors, Error{lineNo, fmt.Sprintf("expected KEY=VALUE, got %q", line)})
+			continue
+		}
+		entry.Key = strings.TrimSpace(key)
+		if !keyPattern.MatchString(entry.Key) {
+			f.Errors = append(f.Errors, Error{lineNo, fmt.Sprintf("invalid variable name %q", entry.Key)})
+			continue
+		}
+
+		value = strings.TrimLeft(value, " \t")
+		if value != "" && (value[0] == '"' || value[0] == '\'') {
+			entry.Quote = value[0]
+			parsed, end, err := quoted(lines, i, value)
+			if err != "" {
+				f.Errors = append(f.Errors, Error{lineNo, err})
+				i = end
+				continue
+			}
+			entry.Value = parsed
+			entry.EndLine = end + 1
+			i = end
+		} else {
+			entry.Value = unquoted(value)
+		}
+
+		f.Entries = append(f.Entries, entry)
+	}
+
+	return f
+}
+
+// quoted reads a quoted value starting on lines[start], which may continue on
+// the following lines. It returns the value, the index of its last line and
+// an error message.
+func quoted(lines []string, start int, value string) (string, int, string) {
+	quote := value[0]
+	text := value[1:]
+	var b strings.Builder
+
+	for i := start; i < len(lines); i++ {
+		if i > start {
+			text = lines[i]
+			b.WriteByte('\n')
+		}
+
+		for j := 0; j < len(text); j++ {
+			c := text[j]
+			if quote == '"' && c == '\\' && j+1 < len(text) {
+				j++
+				switch text[j] {
+				case 'n':
+					b.WriteByte('\n')
+				case 'r':
+					b.WriteByte('\r')
+				case 't':
+					b.WriteByte('\t')
+				case '"', '\\', '$':
+					b.WriteByte(text[j])
+				default:
+					b.WriteByte('\\')
+					b.WriteByte(text[j])
+				}
+				continue
+			}
+			if c == quote {
+				rest := strings.TrimSpace(text[j+1:])
+				if rest != "" && !strings.HasPrefix(rest, "#") {
+					return "", i, fmt.Sprintf("unexpected %q after closing quote", rest)
+				}
+				return b.String(), i, ""
+			}
+			b.WriteByte(c)
+		}
+	}
+
+	return "", len(lines) - 1, fmt.Sprintf("unterminated %c-quoted value", quote)
+}
+
+// unquoted returns an unquoted value without its trailing comment
+func unquoted(value string) string {
+	for i := 0; i < len(value); i++ {
+		if value[i] == '#' && (i == 0 || value[i-1] == ' ' || value[i-1] == '\t') {
+			value = value[:i]
+			break
+		}
+	}
+	return strings.TrimSpace(value)
+}
+
+// Keys returns the variable names in order of first definition
+func (f *File) Keys() []string {
+	seen := make(map[string]bool)
+	var keys []string
+	for _, e := range f.Entries {
+		if !seen[e.Key] {
+			seen[e.Key] = true
+			keys = append(keys, e.Key)
+		}
+	}
+	return keys
+}
+
+// Lookup returns the entry of a variable; later definitions win as in phpdotenv
+func (f *File) Lookup(key string) (Entry, bool) {
+	for i := len(f.Entries) - 1; i >= 0; i-- {
+		if f.Entries[i].Key == key {
+			return f.Entries[i], true
+		}
+	}
+	return Entry{}, false
+}
+
+// Has reports whether a variable is defined
+func (f *File) Has(key string) bool {
+	_, ok := f.Lookup(key)
+	return ok
+}
+
+// Map returns the raw values by name, without interpolation
+func (f *File) Map() map[string]string {
+	values := make(map[string]string, len(f.Entries))
+	for _, e := range f.Entries {
+		values[e.Key] = e.Value
+	}
+	return values
+}
+
+// Duplicates returns the entries that redefine an earlier variable
+func (f *File) Duplicates() []Entry {
+	seen := make(map[string]bool)
+	var duplicates []Entry
+	for _, e := range f.Entries {
+		if seen[e.Key] {
+			duplicates = append(duplicates, e)
+		}
+		seen[e.Key] = true
+	}
+	return duplicates
+}
+
+// Expand returns the values with ${VAR} references to variables defined
+// earlier in the file replaced by their values. References to unknown
+// variables, such as secret placeholders, are kept as they are.
+func (f *File) Expand() map[string]string {
+	values := make(map[string]string, len(f.Entries))
+	for _, e := range f.Entries {
+		value := e.Value
+		if e.Interpolated() {
+			value = reference.ReplaceAllStringFunc(value, func(ref string) string {
+				if v, ok := values[reference.FindStringSubmatch(ref)[1]]; ok {
+					return v
+				}
+				return ref
+			})
+		}
+		values[e.Key] = value
+	}
+	return values
+}
+
+// Missing returns the variables of template that f does not define
+func (f *File) Missing(template *File) []string {
+	var missing []string
+	for _, key := range template.Keys() {
+		if !f.Has(key) {
+			missing = append(missing, key)
+		}
+	}
+	return missing
+}
+
+// Extra returns the variables of f that template does not define
+func (f *File) Extra(template *File) []string {
+	var extra []string
+	for _, key := range f.Keys() {
+		if !template.Has(key) {
+			extra = append(extra, key)
+		}
+	}
+	return extra
+}
+
+// Load reads and parses an env file
+func Load(path string) (*File, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return Parse(string(data)), nil
+}
+
+var plainValue = regexp.MustCompile(`^[A-Za-z0-9_./:@${}+,=*%-]*$`)
+
+// Line formats a KEY=VALUE line. The value is written unquoted when that is
+// unambiguous, otherwise with the given quote character ('"' when 0).
+func Line(key, value string, quote byte) string {
+	if quote == 0 && plainValue.MatchString(value) {
+		return key + "=" + value
+	}
+	if quote == '\'' && !strings.Contains(value, "'") {
+		return key + "='" + value + "'"
+	}
+	escaped := strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`).Replace(value)
+	return key + `="` + escaped + `"`
+}
+
+// AppendMissing appends the variables of template that content does not
+// define, in template order, leaving existing lines untouched. value returns
+// the value written for each missing entry. It returns the new content and
+// the keys that were added.
+func AppendMissing(content string, template *File, value func(Entry) string) (string, []string) {
+	env := Parse(content)
+
+	var lines, added []string
+	for _, key := range env.Missing(template) {
+		entry, _ := template.Lookup(key)
+		v, quote := value(entry), entry.Quote
+		if v != entry.Value {
+			// Keep the template's quoting only for the template's own value
+			quote = 0
+		}
+		lines = append(lines, Line(key, v, quote))
+		added = append(added, key)
+	}
+	if len(lines) == 0 {
+		return content, nil
+	}
+
+	content = strings.TrimRight(content, "\n")
+	if content != "" {
+		content += "\n"
+	}
+	return content + strings.Join(lines, "\n") + "\n", added
+}
@@ -0,0 +1,229 @@
+package dotenv
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		key     string
+		value   string
+		quote   byte
+		export  bool
+		endLine int
+	}{
+		{"unquoted", "KEY=value", "KEY", "value", 0, false, 1},
+		{"empty", "KEY=", "KEY", "", 0, false, 1},
+		{"spaces around", "KEY =  value  ", "KEY", "value", 0, false, 1},
+		{"trailing comment", "KEY=value # comment", "KEY", "value", 0, false, 1},
+		{"hash inside value", "KEY=a#b", "KEY", "a#b", 0, false, 1},
+		{"export", "export KEY=value", "KEY", "value", 0, true, 1},
+		{"dotted key", "APP.NAME=x", "APP.NAME", "x", 0, false, 1},
+		{"crlf", "KEY=value\r\n", "KEY", "value", 0, false, 1},
+		{"single quoted", `KEY='a b # c'`, "KEY", "a b # c", '\'', false, 1},
+		{"single quoted keeps backslashes", `KEY='a\nb'`, "KEY", `a\nb`, '\'', false, 1},
+		{"double quoted", `KEY="a b # c"`, "KEY", "a b # c", '"', false, 1},
+		{"double quoted escapes", `KEY="a\nb\t\"c\" \\ \$d"`, "KEY", "a\nb\t\"c\" \\ $d", '"', false, 1},
+		{"double quoted unknown escape", `KEY="a\qb"`, "KEY", `a\qb`, '"', false, 1},
+		{"comment after quote", `KEY="value" # comment`, "KEY", "value", '"', false, 1},
+		{"double quoted multiline", "KEY=\"line 1\nline 2\"", "KEY", "line 1\nline 2", '"', false, 2},
+		{"single quoted multiline", "KEY='-----BEGIN KEY-----\nabc\n-----END KEY-----'", "KEY", "-----BEGIN KEY-----\nabc\n-----END KEY-----", '\'', false, 3},
+		{"after comments and blank lines", "# comment\n\n  KEY=value", "KEY", "value", 0, false, 3},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := Parse(tt.content)
+			if len(f.Errors) > 0 {
+				t.Fatalf("unexpected errors: %v", f.Errors)
+			}
+			if len(f.Entries) != 1 {
+				t.Fatalf("got %d entries, want 1", len(f.Entries))
+			}
+			e := f.Entries[0]
+			if e.Key != tt.key || e.Value != tt.value {
+				t.Errorf("got %s=%q, want %s=%q", e.Key, e.Value, tt.key, tt.value)
+			}
+			if e.Quote != tt.quote {
+				t.Errorf("got quote %q, want %q", e.Quote, tt.quote)
+			}
+			if e.Export != tt.export {
+				t.Errorf("got export %v, want %v", e.Export, tt.export)
+			}
+			if e.EndLine != tt.endLine {
+				t.Errorf("got end line %d, want %d", e.EndLine, tt.endLine)
+			}
+		})
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		line    int
+		message string
+		keys    []string
+	}{
+		{"missing equals", "KEY\nOTHER=1", 1, "expected KEY=VALUE", []string{"OTHER"}},
+		{"invalid name", "1KEY=x\nOTHER=1", 1, "invalid variable name", []string{"OTHER"}},
+		{"name with dash", "MY-KEY=x", 1, "invalid variable name", nil},
+		{"unterminated double quote", "A=1\nKEY=\"value\nB=2", 2, `unterminated "-quoted value`, []string{"A"}},
+		{"unterminated single quote", "KEY='value", 1, "unterminated '-quoted value", nil},
+		{"text after closing quote", "KEY=\"a\" b\nOTHER=1", 1, `unexpected "b" after closing quote`, []string{"OTHER"}},
+		{"error inside multiline value", "KEY=\"a\nb\" c\nOTHER=1", 1, `unexpected "c" after closing quote`, []string{"OTHER"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := Parse(tt.content)
+			if len(f.Errors) != 1 {
+				t.Fatalf("got errors %v, want 1", f.Errors)
+			}
+			err := f.Errors[0]
+			if err.Line != tt.line || !strings.Contains(err.Message, tt.message) {
+				t.Errorf("got %v, want line %d: %s", err, tt.line, tt.message)
+			}
+			if got := f.Keys(); !reflect.DeepEqual(got, tt.keys) {
+				t.Errorf("got keys %v, want %v", got, tt.keys)
+			}
+		})
+	}
+}
+
+func TestReferences(t *testing.T) {
+	tests := []struct {
+		line string
+		want []string
+	}{
+		{"KEY=${A}", []string{"A"}},
+		{"KEY=${A}-${B_2}", []string{"A", "B_2"}},
+		{`KEY="${A} and ${B}"`, []string{"A", "B"}},
+		{"KEY='${A}'", nil},
+		{"KEY=$A", nil},
+		{"KEY=${1A}", nil},
+		{"KEY=${A.B}", []string{"A.B"}},
+	}
+
+	for _, tt := range tests {
+		f := Parse(tt.line)
+		if len(f.Entries) != 1 {
+			t.Fatalf("%s: got %d entries", tt.line, len(f.Entries))
+		}
+		if got := f.Entries[0].References(); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s: got %v, want %v", tt.line, got, tt.want)
+		}
+	}
+}
+
+func TestExpand(t *testing.T) {
+	f := Parse(strings.Join([]string{
+		"APP_NAME=Shop",
+		`APP_TITLE="${APP_NAME} Admin"`,
+		"LITERAL='${APP_NAME}'",
+		"SECRET=${STRIPE_KEY}",
+		"EARLY=${LATE}",
+		"LATE=x",
+		"APP_NAME=Store",
+		"AFTER=${APP_NAME}",
+	}, "\n"))
+
+	want := map[string]string{
+		"APP_NAME":  "Store",
+		"APP_TITLE": "Shop Admin",
+		"LITERAL":   "${APP_NAME}",
+		"SECRET":    "${STRIPE_KEY}",
+		"EARLY":     "${LATE}",
+		"LATE":      "x",
+		"AFTER":     "Store",
+	}
+	if got := f.Expand(); !reflect.DeepEqual(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+}
+
+func TestLookupAndDuplicates(t *testing.T) {
+	f := Parse("A=1\nB=2\nA=3")
+
+	if e, ok := f.Lookup("A"); !ok || e.Value != "3" || e.Line != 3 {
+		t.Errorf("got %+v, want the later definition", e)
+	}
+	if f.Has("C") {
+		t.Error("C should not be defined")
+	}
+	if got := f.Keys(); !reflect.DeepEqual(got, []string{"A", "B"}) {
+		t.Errorf("got keys %v", got)
+	}
+	if d := f.Duplicates(); len(d) != 1 || d[0].Line != 3 {
+		t.Errorf("got duplicates %+v", d)
+	}
+}
+
+func TestMissingAndExtra(t *testing.T) {
+	env := Parse("A=1\nC=3")
+	template := Parse("A=\nB=\n")
+
+	if got := env.Missing(template); !reflect.DeepEqual(got, []string{"B"}) {
+		t.Errorf("got missing %v", got)
+	}
+	if got := env.Extra(template); !reflect.DeepEqual(got, []string{"C"}) {
+		t.Errorf("got extra %v", got)
+	}
+}
+
+func TestLine(t *testing.T) {
+	tests := []struct {
+		value string
+		quote byte
+		want  string
+	}{
+		{"plain", 0, "KEY=plain"},
+		{"${SECRET}", 0, "KEY=${SECRET}"},
+		{"", 0, "KEY="},
+		{"two words", 0, `KEY="two words"`},
+		{"a#b", 0, `KEY="a#b"`},
+		{`say "hi"`, 0, `KEY="say \"hi\""`},
+		{"line 1\nline 2", 0, `KEY="line 1\nline 2"`},
+		{"two words", '\'', "KEY='two words'"},
+		{"it's", '\'', `KEY="it's"`},
+	}
+
+	for _, tt := range tests {
+		got := Line("KEY", tt.value, tt.quote)
+		if got != tt.want {
+			t.Errorf("Line(%q, %q): got %s, want %s", tt.value, tt.quote, got, tt.want)
+		}
+		// Formatted lines parse back to the same value
+		f := Parse(got)
+		if len(f.Entries) != 1 || f.Entries[0].Value != tt.value {
+			t.Errorf("%s does not parse back to %q: %+v", got, tt.value, f)
+		}
+	}
+}
+
+func TestAppendMissing(t *testing.T) {
+	template := Parse("APP_NAME=Laravel\nAPP_KEY=\nMAIL_FROM=\"hello@example.com\"\n")
+
+	got, added := AppendMissing("APP_NAME=Shop", template, func(e Entry) string {
+		if e.Key == "APP_KEY" {
+			return "${APP_KEY}"
+		}
+		return e.Value
+	})
+
+	want := "APP_NAME=Shop\nAPP_KEY=${APP_KEY}\nMAIL_FROM=\"hello@example.com\"\n"
+	if got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+	if !reflect.DeepEqual(added, []string{"APP_KEY", "MAIL_FROM"}) {
+		t.Errorf("got added %v", added)
+	}
+
+	if got, added := AppendMissing(want, template, func(e Entry) string { return e.Value }); got != want || added != nil {
+		t.Errorf("complete content changed: %q, %v", got, added)
+	}
+}
@@ -0,0 +1,392 @@
+package forge
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// DefaultBaseURL is the base URL of the Forge API
+const DefaultBaseURL = "https://forge.laravel.com/api"
+
+// TokenEnv is the environment variable the API token is read from
+const TokenEnv = "FORGE_API_TOKEN"
+
+// Client is a minimal Forge API client
+type Client struct {
+	BaseURL    string
+	Token      string
+	HTTPClient *http.Client
+}
+
+// NewClient returns a client for the Forge API using the given token
+func NewClient(token string) *Client {
+	return &Client{
+		BaseURL:    DefaultBaseURL,
+		Token:      token,
+		HTTPClient: &http.Client{Timeout: 30 * time.Second},
+	}
+}
+
+// APIError is returned when the Forge API responds with an error status
+type APIError struct {
+	StatusCode int
+	Message    string
+}
+
+func (e *APIError) Error() string {
+	switch e.StatusCode {
+	case http.StatusUnauthorized:
+		return "forge api: invalid or missing API token"
+	case http.StatusNotFound:
+		return "forge api: not found"
+	}
+	if e.Message != "" {
+		return fmt.Sprintf("forge api: %s (status %d)", e.Message, e.StatusCode)
+	}
+	return fmt.Sprintf("forge api: unexpected status %d", e.StatusCode)
+}
+
+// IsNotFound reports whether err is a 404 response from the Forge API
+func IsNotFound(err error) bool {
+	var apiErr *APIError
+	return errors.As(err, &apiErr) && apiErr.StatusCode == http.StatusNotFound
+}
+
+// ID is a resource identifier. The API may return it as a number or a string.
+type ID string
+
+// UnmarshalJSON accepts both numeric and string identifiers. A null
+// identifier is empty.
+func (id *ID) UnmarshalJSON(data []byte) error {
+	if string(data) == "null" {
+		*id = ""
+		return nil
+	}
+	if strings.HasPrefix(string(data), `"`) {
+		var s string
+		if err := json.Unmarshal(data, &s); err != nil {
+			return err
+		}
+		*id = ID(s)
+		return nil
+	}
+	var n json.Number
+	if err := json.Unmarshal(data, &n); err != nil {
+		return fmt.Errorf("invalid id %s", data)
+	}
+	*id = ID(n)
+	return nil
+}
+
+// resource is a single JSON:API resource object
+type resource struct {
+	ID         ID              `json:"id"`
+	Type       string          `json:"type"`
+	Attributes json.RawMessage `json:"attributes"`
+}
+
+// document is a JSON:API response document
+type document struct {
+	Data  json.RawMessage `json:"data"`
+	Links struct {
+		Next string `json:"next"`
+	} `json:"links"`
+	Message string `json:"message"`
+}
+
+// resolve returns the URL of an endpoint. Absolute URLs, such as pagination
+// links, must point to the API's host so the token is never sent elsewhere.
+func (c *Client) resolve(endpoint string) (string, error) {
+	if !strings.HasPrefix(endpoint, "http://") && !strings.HasPrefix(endpoint, "https://") {
+		return strings.TrimSuffix(c.BaseURL, "/") + endpoint, nil
+	}
+
+	base, err := url.Parse(c.BaseURL)
+	if err != nil {
+		return "", fmt.Errorf("forge api: invalid base URL %q: %w", c.BaseURL, err)
+	}
+	target, err := url.Parse(endpoint)
+	if err != nil {
+		return "", fmt.Errorf("forge api: invalid URL %q: %w", endpoint, err)
+	}
+	if target.Scheme != base.Scheme || target.Host != base.Host {
+		return "", fmt.Errorf("forge api: refusing to follow %s outside %s://%s", endpoint, base.Scheme, base.Host)
+	}
+	return endpoint, nil
+}
+
+// get performs a GET request and decodes the response document
+func (c *Client) get(ctx context.Context, endpoint string) (*document, error) {
+	target, err := c.resolve(endpoint)
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, target, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Authorization", "Bearer "+c.Token)
+	req.Header.Set("Accept", "application/json")
+
+	httpClient := c.HTTPClient
+	if httpClient == nil {
+		httpClient = http.DefaultClient
+	}
+
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("forge api: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("forge api: %w", err)
+	}
+
+	doc := &document{}
+	if len(body) > 0 {
+		if err := json.Unmarshal(body, doc); err != nil && resp.StatusCode < 300 {
+			return nil, fmt.Errorf("forge api: invalid response from %s: %w", endpoint, err)
+		}
+	}
+
+	if resp.StatusCode >= 300 {
+		return nil, &APIError{StatusCode: resp.StatusCode, Message: doc.Message}
+	}
+
+	return doc, nil
+}
+
+// getOne fetches a single resource and decodes its attributes into v
+func (c *Client) getOne(ctx context.Context, endpoint string, v interface{}) (ID, error) {
+	doc, err := c.get(ctx, endpoint)
+	if err != nil {
+		return "", err
+	}
+
+	var r resource
+	if err := json.Unmarshal(doc.Data, &r); err != nil {
+		return "", fmt.Errorf("forge api: invalid response from %s: %w", endpoint, err)
+	}
+	if err := json.Unmarshal(r.Attributes, v); err != nil {
+		return "", fmt.Errorf("forge api: invalid attributes from %s: %w", endpoint, err)
+	}
+	return r.ID, nil
+}
+
+// maxPages bounds the pages list follows, in case an API keeps linking to new pages
+const maxPages = 1000
+
+// list fetches every page of a collection and calls fn for each resource.
+// It fails rather than loop when a next link points to a page already fetched.
+func (c *Client) list(ctx context.Context, endpoint string, fn func(resource) error) error {
+	seen := make(map[string]bool)
+	for endpoint != "" {
+		if seen[endpoint] {
+			return fmt.Errorf("forge api: pagination loops back to %s", endpoint)
+		}
+		if len(seen) == maxPages {
+			return fmt.Errorf("forge api: more than %d pages at %s", maxPages, endpoint)
+		}
+		seen[endpoint] = true
+
+		doc, err := c.get(ctx, endpoint)
+		if err != nil {
+			return err
+		}
+
+		var resources []resource
+		if err := json.Unmarshal(doc.Data, &resources); err != nil {
+			return fmt.Errorf("forge api: invalid response from %s: %w", endpoint, err)
+		}
+		for _, r := range resources {
+			if err := fn(r); err != nil {
+				return err
+			}
+		}
+
+		endpoint = doc.Links.Next
+	}
+	return nil
+}
+
+// Organization is a Forge organization
+type Organization struct {
+	ID   ID     `json:"-"`
+	Name string `json:"name"`
+	Slug string `json:"slug"`
+}
+
+// Server is a server managed by Forge
+type Server struct {
+	ID         ID     `json:"-"`
+	Name       string `json:"name"`
+	IPAddress  string `json:"ip_address"`
+	PHPVersion string `json:"php_version"`
+}
+
+// Site is a site on a Forge server
+type Site struct {
+	ID              ID       `json:"-"`
+	Name            string   `json:"name"`
+	Aliases         []string `json:"aliases"`
+	RootDirectory   string   `json:"root_directory"`
+	WebDirectory    string   `json:"web_directory"`
+	ProjectType     string   `json:"project_type"`
+	PHPVersion      string   `json:"php_version"`
+	Repository      string   `json:"repository"`
+	Branch          string   `json:"repository_branch"`
+	Isolated        bool     `json:"isolated"`
+	User            string   `json:"user"`
+	ZeroDowntime    bool     `json:"zero_downtime_deployments"`
+	WWWRedirectType string   `json:"www_redirect_type"`
+	Certificate     bool     `json:"has_certificate"`
+	NginxTemplate   string   `json:"nginx_template"`
+}
+
+// Daemon is a background process running on a server
+type Daemon struct {
+	ID           ID     `json:"-"`
+	Command      string `json:"command"`
+	Directory    string `json:"directory"`
+	User         string `json:"user"`
+	Processes    int    `json:"processes"`
+	StartSecs    int    `json:"startsecs"`
+	StopWaitSecs int    `json:"stopwaitsecs"`
+}
+
+// ScheduledJob is a cron job on a server
+type ScheduledJob struct {
+	ID        ID     `json:"-"`
+	Command   string `json:"command"`
+	User      string `json:"user"`
+	Frequency string `json:"frequency"`
+	Cron      string `json:"cron"`
+}
+
+// content is the attributes of a resource that wraps a text file
+type content struct {
+	Content string `json:"content"`
+}
+
+// ListOrganizations lists the organizations the token has access to
+func (c *Client) ListOrganizations(ctx context.Context) ([]Organization, error) {
+	var orgs []Organization
+	err := c.list(ctx, "/orgs", func(r resource) error {
+		var org Organization
+		if err := json.Unmarshal(r.Attributes, &org); err != nil {
+			return err
+		}
+		org.ID = r.ID
+		orgs = append(orgs, org)
+		return nil
+	})
+	return orgs, err
+}
+
+// ListServers lists the servers of an organization
+func (c *Client) ListServers(ctx context.Context, organization string) ([]Server, error) {
+	var servers []Server
+	err := c.list(ctx, fmt.Sprintf("/orgs/%s/servers", url.PathEscape(organization)), func(r resource) error {
+		var server Server
+		if err := json.Unmarshal(r.Attributes, &server); err != nil {
+			return err
+		}
+		server.ID = r.ID
+		servers = append(servers, server)
+		return nil
+	})
+	return servers, err
+}
+
+// FindServer returns the server of an organization with the given name
+func (c *Client) FindServer(ctx context.Context, organization, name string) (*Server, error) {
+	servers, err := c.ListServers(ctx, organization)
+	if err != nil {
+		return nil, err
+	}
+	for i := range servers {
+		if servers[i].Name == name {
+			return &servers[i], nil
+		}
+	}
+	return nil, fmt.Errorf("server %q not found in organization %q", name, organization)
+}
+
+// ListSites lists the sites of a server
+func (c *Client) ListSites(ctx context.Context, organization string, server ID) ([]Site, error) {
+	var sites []Site
+	err := c.list(ctx, fmt.Sprintf("/orgs/%s/servers/%s/sites", url.PathEscape(organization), url.PathEscape(string(server))), func(r resource) error {
+		var site Site
+		if err := json.Unmarshal(r.Attributes, &site); err != nil {
+			return err
+		}
+		site.ID = r.ID
+		sites = append(sites, site)
+		return nil
+	})
+	return sites, err
+}
+
+// sitePath returns the API path of a site
+func sitePath(organization string, server, site ID) string {
+	return fmt.Sprintf("/orgs/%s/servers/%s/sites/%s",
+		url.PathEscape(organization), url.PathEscape(string(server)), url.PathEscape(string(site)))
+}
+
+// GetDeploymentScript returns the deployment script of a site
+func (c *Client) GetDeploymentScript(ctx context.Context, organization string, server, site ID) (string, error) {
+	var script content
+	if _, err := c.getOne(ctx, sitePath(organization, server, site)+"/deployments/script", &script); err != nil {
+		return "", err
+	}
+	return script.Content, nil
+}
+
+// GetEnvironment returns the content of a site's .env file
+func (c *Client) GetEnvironment(ctx context.Context, organization string, server, site ID) (string, error) {
+	var env content
+	if _, err := c.getOne(ctx, sitePath(organization, server, site)+"/environment", &env); err != nil {
+		return "", err
+	}
+	return env.Content, nil
+}
+
+// ListDaemons lists the background processes of a server
+func (c *Client) ListDaemons(ctx context.Context, organization string, server ID) ([]Daemon, error) {
+	var daemons []Daemon
+	err := c.list(ctx, fmt.Sprintf("/orgs/%s/servers/%s/background-processes", url.PathEscape(organization), url.PathEscape(string(server))), func(r resource) error {
+		var daemon Daemon
+		if err := json.Unmarshal(r.Attributes, &daemon); err != nil {
+			return err
+		}
+		daemon.ID = r.ID
+		daemons = append(daemons, daemon)
+		return nil
+	})
+	return daemons, err
+}
+
+// ListScheduledJobs lists the scheduled jobs of a server
+func (c *Client) ListScheduledJobs(ctx context.Context, organization string, server ID) ([]ScheduledJob, error) {
+	var jobs []ScheduledJob
+	err := c.list(ctx, fmt.Sprintf("/orgs/%s/servers/%s/scheduled-jobs", url.PathEscape(organization), url.PathEscape(string(server))), func(r resource) error {
+		var job ScheduledJob
+		if err := json.Unmarshal(r.Attributes, &job); err != nil {
+			return err
+		}
+		job.ID = r.ID
+		jobs = append(jobs, job)
+		return nil
+	})
+	return jobs, err
+}
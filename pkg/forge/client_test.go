@@ -0,0 +1,228 @@
+package forge_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/the-trybe/forge-deploy-cli/pkg/forge"
+	"github.com/the-trybe/forge-deploy-cli/pkg/forge/forgetest"
+)
+
+const token = "test-token"
+
+func fixtures() *forgetest.Data {
+	return &forgetest.Data{
+		Organizations: []forge.Organization{
+			{ID: "1", Name: "Acme", Slug: "acme"},
+			{ID: "2", Name: "Globex", Slug: "globex"},
+			{ID: "3", Name: "Initech", Slug: "initech"},
+			{ID: "4", Name: "Umbrella", Slug: "umbrella"},
+			{ID: "5", Name: "Hooli", Slug: "hooli"},
+		},
+		Servers: map[string][]forge.Server{
+			"acme": {{ID: "10", Name: "web-1", PHPVersion: "php84"}},
+		},
+		Sites: map[forge.ID][]forge.Site{
+			"10": {{ID: "100", Name: "app.example.com", PHPVersion: "php84"}},
+		},
+		Environments: map[forge.ID]string{
+			"100": "APP_ENV=production\n",
+		},
+	}
+}
+
+func TestListFollowsPagination(t *testing.T) {
+	for _, pageSize := range []int{1, 2, 5, 0} {
+		server := forgetest.NewServer(token, fixtures())
+		server.PageSize = pageSize
+
+		orgs, err := server.Client().ListOrganizations(context.Background())
+		server.Close()
+		if err != nil {
+			t.Fatalf("page size %d: %v", pageSize, err)
+		}
+
+		var slugs []string
+		for _, org := range orgs {
+			slugs = append(slugs, org.Slug)
+		}
+		if got, want := strings.Join(slugs, ","), "acme,globex,initech,umbrella,hooli"; got != want {
+			t.Errorf("page size %d: got %s, want %s", pageSize, got, want)
+		}
+		if orgs[0].ID != "1" {
+			t.Errorf("page size %d: got id %q, want 1", pageSize, orgs[0].ID)
+		}
+	}
+}
+
+func TestListStopsOnPaginationLoop(t *testing.T) {
+	server := forgetest.NewServer(token, fixtures())
+	defer server.Close()
+	server.StuckPages = true
+
+	done := make(chan error, 1)
+	go func() {
+		_, err := server.Client().ListOrganizations(context.Background())
+		done <- err
+	}()
+
+	select {
+	case err := <-done:
+		if err == nil || !strings.Contains(err.Error(), "pagination loops back") {
+			t.Errorf("got %v, want a pagination loop error", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("ListOrganizations kept following the same next link")
+	}
+}
+
+func TestNotFound(t *testing.T) {
+	server := forgetest.NewServer(token, fixtures())
+	defer server.Close()
+	client := server.Client()
+	ctx := context.Background()
+
+	if _, err := client.ListServers(ctx, "missing"); !forge.IsNotFound(err) {
+		t.Errorf("ListServers of an unknown organization: got %v, want not found", err)
+	}
+	if _, err := client.GetEnvironment(ctx, "acme", "10", "999"); !forge.IsNotFound(err) {
+		t.Errorf("GetEnvironment of an unknown site: got %v, want not found", err)
+	}
+
+	env, err := client.GetEnvironment(ctx, "acme", "10", "100")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if env != "APP_ENV=production\n" {
+		t.Errorf("got environment %q", env)
+	}
+
+	_, err = client.FindServer(ctx, "acme", "web-2")
+	if err == nil || forge.IsNotFound(err) {
+		t.Errorf("FindServer of an unknown name: got %v, want a lookup error", err)
+	}
+}
+
+func TestErrorMapping(t *testing.T) {
+	tests := []struct {
+		name    string
+		status  int
+		body    string
+		message string
+	}{
+		{"unauthorized", http.StatusUnauthorized, `{"message":"Unauthenticated."}`, "forge api: invalid or missing API token"},
+		{"not found", http.StatusNotFound, `{"message":"Not found."}`, "forge api: not found"},
+		{"with message", http.StatusUnprocessableEntity, `{"message":"The name is invalid."}`, "forge api: The name is invalid. (status 422)"},
+		{"without body", http.StatusInternalServerError, ``, "forge api: unexpected status 500"},
+		{"html body", http.StatusBadGateway, `<html>Bad gateway</html>`, "forge api: unexpected status 502"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(tt.status)
+				_, _ = w.Write([]byte(tt.body))
+			}))
+			defer server.Close()
+
+			client := forge.NewClient(token)
+			client.BaseURL = server.URL
+			_, err := client.ListOrganizations(context.Background())
+
+			var apiErr *forge.APIError
+			if !errors.As(err, &apiErr) {
+				t.Fatalf("got %v, want an APIError", err)
+			}
+			if apiErr.StatusCode != tt.status {
+				t.Errorf("got status %d, want %d", apiErr.StatusCode, tt.status)
+			}
+			if err.Error() != tt.message {
+				t.Errorf("got %q, want %q", err.Error(), tt.message)
+			}
+		})
+	}
+}
+
+func TestWrongToken(t *testing.T) {
+	server := forgetest.NewServer(token, fixtures())
+	defer server.Close()
+
+	client := server.Client()
+	client.Token = "wrong"
+	_, err := client.ListOrganizations(context.Background())
+
+	var apiErr *forge.APIError
+	if !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusUnauthorized {
+		t.Errorf("got %v, want a 401 APIError", err)
+	}
+}
+
+func TestNextLinkToAnotherHost(t *testing.T) {
+	stolen := make(chan string, 1)
+	other := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		stolen <- r.Header.Get("Authorization")
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"data":[]}`))
+	}))
+	defer other.Close()
+
+	api := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{
+			"data":  []interface{}{map[string]interface{}{"id": 1, "type": "organizations", "attributes": map[string]string{"slug": "acme"}}},
+			"links": map[string]string{"next": other.URL + "/orgs?page=2"},
+		})
+	}))
+	defer api.Close()
+
+	client := forge.NewClient(token)
+	client.BaseURL = api.URL
+	if _, err := client.ListOrganizations(context.Background()); err == nil || !strings.Contains(err.Error(), "refusing to follow") {
+		t.Errorf("got %v, want the link to be refused", err)
+	}
+
+	select {
+	case header := <-stolen:
+		t.Errorf("token sent to another host: %q", header)
+	default:
+	}
+}
+
+func TestIDUnmarshal(t *testing.T) {
+	tests := []struct {
+		json string
+		want forge.ID
+	}{
+		{`{"id":42}`, "42"},
+		{`{"id":"42"}`, "42"},
+		{`{"id":"a\"b"}`, `a"b`},
+		{`{"id":null}`, ""},
+		{`{}`, ""},
+	}
+
+	for _, tt := range tests {
+		var v struct {
+			ID forge.ID `json:"id"`
+		}
+		if err := json.Unmarshal([]byte(tt.json), &v); err != nil {
+			t.Errorf("%s: %v", tt.json, err)
+			continue
+		}
+		if v.ID != tt.want {
+			t.Errorf("%s: got %q, want %q", tt.json, v.ID, tt.want)
+		}
+	}
+
+	var v struct {
+		ID forge.ID `json:"id"`
+	}
+	if err := json.Unmarshal([]byte(`{"id":true}`), &v); err == nil {
+		t.Error("a boolean id was accepted")
+	}
+}
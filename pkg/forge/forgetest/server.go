@@ -0,0 +1,213 @@
+// Package forgetest provides an in-memory fake of the Forge API for offline testing.
+package forgetest
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+
+	"github.com/the-trybe/forge-deploy-cli/pkg/forge"
+)
+
+// DefaultPageSize is the number of resources per page returned by the fake server
+const DefaultPageSize = 2
+
+// Data holds the fixtures served by the fake server. Servers are keyed by
+// organization slug; sites, daemons and scheduled jobs by server ID; and
+// deployment scripts and environments by site ID.
+type Data struct {
+	Organizations     []forge.Organization
+	Servers           map[string][]forge.Server
+	Sites             map[forge.ID][]forge.Site
+	Daemons           map[forge.ID][]forge.Daemon
+	ScheduledJobs     map[forge.ID][]forge.ScheduledJob
+	DeploymentScripts map[forge.ID]string
+	Environments      map[forge.ID]string
+}
+
+// Server is a fake Forge API backed by httptest
+type Server struct {
+	*httptest.Server
+
+	Token    string
+	Data     *Data
+	PageSize int
+	// StuckPages makes the next link of every page point to the page
+	// itself, as a misbehaving API would
+	StuckPages bool
+}
+
+// NewServer starts a fake Forge API that accepts the given token
+func NewServer(token string, data *Data) *Server {
+	s := &Server{Token: token, Data: data, PageSize: DefaultPageSize}
+	s.Server = httptest.NewServer(http.HandlerFunc(s.serveHTTP))
+	return s
+}
+
+// Client returns a Forge client configured to talk to the fake server
+func (s *Server) Client() *forge.Client {
+	c := forge.NewClient(s.Token)
+	c.BaseURL = s.URL
+	c.HTTPClient = s.Server.Client()
+	return c
+}
+
+type resource struct {
+	ID         forge.ID    `json:"id"`
+	Type       string      `json:"type"`
+	Attributes interface{} `json:"attributes"`
+}
+
+func (s *Server) serveHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Header.Get("Authorization") != "Bearer "+s.Token {
+		writeError(w, http.StatusUnauthorized, "Unauthenticated.")
+		return
+	}
+	if r.Method != http.MethodGet {
+		writeError(w, http.StatusMethodNotAllowed, "Method not allowed.")
+		return
+	}
+
+	parts := strings.Split(strings.Trim(r.URL.Path, "/"), "/")
+	if !s.route(w, r, parts) {
+		writeError(w, http.StatusNotFound, "Not found.")
+	}
+}
+
+// route dispatches a request and reports whether the path was known
+func (s *Server) route(w http.ResponseWriter, r *http.Request, parts []string) bool {
+	switch {
+	case len(parts) == 1 && parts[0] == "orgs":
+		var items []resource
+		for _, org := range s.Data.Organizations {
+			items = append(items, resource{ID: org.ID, Type: "organizations", Attributes: org})
+		}
+		s.writePage(w, r, items)
+		return true
+
+	case len(parts) == 3 && parts[0] == "orgs" && parts[2] == "servers":
+		servers, ok := s.Data.Servers[parts[1]]
+		if !ok {
+			return false
+		}
+		var items []resource
+		for _, server := range servers {
+			items = append(items, resource{ID: server.ID, Type: "servers", Attributes: server})
+		}
+		s.writePage(w, r, items)
+		return true
+
+	case len(parts) == 5 && parts[0] == "orgs" && parts[2] == "servers" && parts[4] == "sites":
+		if !s.hasServer(parts[1], forge.ID(parts[3])) {
+			return false
+		}
+		var items []resource
+		for _, site := range s.Data.Sites[forge.ID(parts[3])] {
+			items = append(items, resource{ID: site.ID, Type: "sites", Attributes: site})
+		}
+		s.writePage(w, r, items)
+		return true
+
+	case len(parts) == 5 && parts[0] == "orgs" && parts[2] == "servers" && parts[4] == "background-processes":
+		if !s.hasServer(parts[1], forge.ID(parts[3])) {
+			return false
+		}
+		var items []resource
+		for _, daemon := range s.Data.Daemons[forge.ID(parts[3])] {
+			items = append(items, resource{ID: daemon.ID, Type: "background-processes", Attributes: daemon})
+		}
+		s.writePage(w, r, items)
+		return true
+
+	case len(parts) == 5 && parts[0] == "orgs" && parts[2] == "servers" && parts[4] == "scheduled-jobs":
+		if !s.hasServer(parts[1], forge.ID(parts[3])) {
+			return false
+		}
+		var items []resource
+		for _, job := range s.Data.ScheduledJobs[forge.ID(parts[3])] {
+			items = append(items, resource{ID: job.ID, Type: "scheduled-jobs", Attributes: job})
+		}
+		s.writePage(w, r, items)
+		return true
+
+	case len(parts) == 8 && parts[0] == "orgs" && parts[2] == "servers" && parts[4] == "sites" && parts[6] == "deployments" && parts[7] == "script":
+		return s.writeContent(w, parts, "deployment-scripts", s.Data.DeploymentScripts)
+
+	case len(parts) == 7 && parts[0] == "orgs" && parts[2] == "servers" && parts[4] == "sites" && parts[6] == "environment":
+		return s.writeContent(w, parts, "environments", s.Data.Environments)
+	}
+
+	return false
+}
+
+// writeContent writes a single text resource of the site in the request path
+func (s *Server) writeContent(w http.ResponseWriter, parts []string, kind string, contents map[forge.ID]string) bool {
+	server, site := forge.ID(parts[3]), forge.ID(parts[5])
+	if !s.hasServer(parts[1], server) || !s.hasSite(server, site) {
+		return false
+	}
+
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"data": resource{ID: site, Type: kind, Attributes: map[string]string{"content": contents[site]}},
+	})
+	return true
+}
+
+func (s *Server) hasSite(server, id forge.ID) bool {
+	for _, site := range s.Data.Sites[server] {
+		if site.ID == id {
+			return true
+		}
+	}
+	return false
+}
+
+func (s *Server) hasServer(org string, id forge.ID) bool {
+	for _, server := range s.Data.Servers[org] {
+		if server.ID == id {
+			return true
+		}
+	}
+	return false
+}
+
+// writePage writes one page of a collection with a link to the next page
+func (s *Server) writePage(w http.ResponseWriter, r *http.Request, items []resource) {
+	page, _ := strconv.Atoi(r.URL.Query().Get("page"))
+	if page < 1 {
+		page = 1
+	}
+
+	size := s.PageSize
+	if size < 1 {
+		size = len(items) + 1
+	}
+
+	start := min((page-1)*size, len(items))
+	end := min(start+size, len(items))
+
+	body := map[string]interface{}{"data": items[start:end]}
+	if end < len(items) {
+		next := *r.URL
+		query := next.Query()
+		if !s.StuckPages {
+			query.Set("page", strconv.Itoa(page+1))
+		}
+		next.RawQuery = query.Encode()
+		body["links"] = map[string]string{"next": s.URL + next.String()}
+	}
+
+	writeJSON(w, http.StatusOK, body)
+}
+
+func writeJSON(w http.ResponseWriter, status int, body interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(body)
+}
+
+func writeError(w http.ResponseWriter, status int, message string) {
+	writeJSON(w, status, map[string]string{"message": message})
+}
@@ -27,47 +27,47 @@ func GenerateForgeDeployYAML(config *models.DeploymentConfig) (string, error) {
 }
 
 // GenerateGitHubWorkflow generates the GitHub Actions workflow file content
-func GenerateGitHubWorkflow(config *models.DeploymentConfig, workflowName, triggerBranch string, forgeConfigFileName string) string {
-	workflow := fmt.Sprintf(`# GitHub Actions Workflow for Laravel Forge Deployment
-# Generated by forge-deploy-cli
-
-name: %s
+func GenerateGitHubWorkflow(config *models.DeploymentConfig, opts WorkflowOptions) (string, error) {
+	if err := opts.Validate(); err != nil {
+		return "", err
+	}
+	return BuildWorkflow(opts, SecretNames(config)).Marshal(opts.Reviewers)
+}
 
-on:
-  push:
-    branches: [%s]
-  workflow_dispatch:
+// GenerateEnvironmentForgeDeployYAML generates the resolved forge-deploy file of one environment
+func GenerateEnvironmentForgeDeployYAML(config *models.DeploymentConfig, environment, sourceFileName string) (string, error) {
+	data, err := yaml.Marshal(config)
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal config: %w", err)
+	}
 
-jobs:
-  deploy:
-    runs-on: ubuntu-latest
-    name: Deploy to Laravel Forge
-    
-    steps:
-      - name: Checkout code
-        uses: actions/checkout@v4
-      
-      - name: Deploy to Forge
-        uses: the-trybe/deploy-to-laravel-forge@v2
-        with:
-          forge_api_token: ${{ secrets.FORGE_API_TOKEN }}
-          deployment_file: %s
+	header := fmt.Sprintf(`# Laravel Forge Deployment Configuration (environment: %s)
+# Generated by forge-deploy-cli from %s - edit that file instead
+# See: https://github.com/the-trybe/deploy-to-laravel-forge
 
-        #secrets: |
-          #SECRET_VAR=${{ secrets.SECRET_VAR }}
+`, environment, sourceFileName)
 
-`, workflowName, triggerBranch, forgeConfigFileName)
+	return header + string(data), nil
+}
 
-	return workflow
+// EnvironmentTarget maps a branch to the forge-deploy file of an environment
+// and the secrets its sites' environments reference
+type EnvironmentTarget struct {
+	Name           string
+	Branch         string
+	ConfigFileName string
+	Secrets        []string
 }
 
-// sortStrings is a simple sort for string slices
-func sortStrings(arr []string) {
-	for i := 0; i < len(arr)-1; i++ {
-		for j := i + 1; j < len(arr); j++ {
-			if arr[i] > arr[j] {
-				arr[i], arr[j] = arr[j], arr[i]
-			}
-		}
+// GenerateEnvironmentsWorkflow generates a GitHub Actions workflow with one
+// deploy job per environment, each running only for its own branch
+func GenerateEnvironmentsWorkflow(targets []EnvironmentTarget, opts WorkflowOptions) (string, error) {
+	opts.Branches, opts.Tags = nil, nil
+	for _, target := range targets {
+		opts.Branches = append(opts.Branches, target.Branch)
+	}
+	if err := opts.Validate(); err != nil {
+		return "", err
 	}
+	return BuildEnvironmentsWorkflow(targets, opts).Marshal(nil)
 }
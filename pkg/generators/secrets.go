@@ -0,0 +1,43 @@
+package generators
+
+import (
+	"regexp"
+	"sort"
+
+	"github.com/the-trybe/forge-deploy-cli/pkg/dotenv"
+	"github.com/the-trybe/forge-deploy-cli/pkg/models"
+)
+
+// secretName matches the names GitHub allows for secrets
+var secretName = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// SecretNames returns the ${NAME} placeholders used in the sites' inline
+// environments, sorted and without duplicates. The deploy action replaces them
+// with the GitHub secrets of the same name. Placeholders naming another key of
+// the same environment, such as "${APP_NAME}", are dotenv references, not
+// secrets, and names GitHub does not allow for secrets, such as "${A.B}", are
+// skipped.
+func SecretNames(config *models.DeploymentConfig) []string {
+	seen := make(map[string]bool)
+	var names []string
+
+	for _, site := range config.Sites {
+		if site.Environment == "" {
+			continue
+		}
+
+		env := dotenv.Parse(site.Environment)
+		for _, entry := range env.Entries {
+			for _, name := range entry.References() {
+				if (env.Has(name) && name != entry.Key) || seen[name] || !secretName.MatchString(name) {
+					continue
+				}
+				seen[name] = true
+				names = append(names, name)
+			}
+		}
+	}
+
+	sort.Strings(names)
+	return names
+}
@@ -0,0 +1,328 @@
+package generators
+
+import (
+	"bytes"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	// DefaultRunner is the runner deploy jobs use unless another is configured
+	DefaultRunner = "ubuntu-latest"
+	// DeployAction is the GitHub Action that deploys a forge-deploy file
+	DeployAction = "the-trybe/deploy-to-laravel-forge"
+	// DefaultActionVersion is the deploy action version used unless another is pinned
+	DefaultActionVersion = "v2"
+	// DefaultCheckoutVersion is the actions/checkout version used unless another is pinned
+	DefaultCheckoutVersion = "v4"
+)
+
+var (
+	abbreviatedSHA   = regexp.MustCompile(`^[0-9a-f]{7,39}$`)
+	actionVersionRef = regexp.MustCompile(`^[A-Za-z0-9._/-]+$`)
+)
+
+// WorkflowOptions configures the generated GitHub Actions workflow
+type WorkflowOptions struct {
+	// Name is the workflow name
+	Name string
+	// Branches trigger a deployment when pushed to
+	Branches []string
+	// Tags are tag patterns, e.g. "v*", that trigger a deployment when pushed
+	Tags []string
+	// Paths limits push triggers to changes in these paths
+	Paths []string
+	// Runner is the runs-on label of the deploy jobs
+	Runner string
+	// ActionVersion is a tag or a full commit SHA of the deploy action
+	ActionVersion string
+	// CheckoutVersion is a tag or a full commit SHA of actions/checkout
+	CheckoutVersion string
+	// Concurrency is the concurrency group of the deploy jobs; empty disables it
+	Concurrency string
+	// CancelInProgress cancels a running deployment of the same group
+	CancelInProgress bool
+	// Environment is the GitHub environment the deploy job runs in
+	Environment string
+	// EnvironmentURL is shown on the deployment in GitHub
+	EnvironmentURL string
+	// Reviewers must approve deployments to Environment. They are configured
+	// in the repository settings; the workflow only documents them.
+	Reviewers []string
+	// ConfigFileName is the forge-deploy file the action deploys
+	ConfigFileName string
+}
+
+// Validate checks the options for values GitHub would reject
+func (o WorkflowOptions) Validate() error {
+	for _, ref := range []struct{ name, value string }{
+		{"action version", o.ActionVersion},
+		{"checkout version", o.CheckoutVersion},
+	} {
+		if ref.value == "" {
+			continue
+		}
+		if !actionVersionRef.MatchString(ref.value) {
+			return fmt.Errorf("%s %q is not a valid git ref", ref.name, ref.value)
+		}
+		if abbreviatedSHA.MatchString(ref.value) && strings.ContainsAny(ref.value, "abcdef") {
+			return fmt.Errorf("%s %q looks like an abbreviated commit SHA; pin the full 40-character SHA", ref.name, ref.value)
+		}
+	}
+	if len(o.Reviewers) > 0 && o.Environment == "" {
+		return fmt.Errorf("required reviewers need an environment")
+	}
+	if len(o.Branches) == 0 && len(o.Tags) == 0 {
+		return fmt.Errorf("at least one branch or tag must trigger the workflow")
+	}
+	return nil
+}
+
+// Workflow is a GitHub Actions workflow
+type Workflow struct {
+	Name        string       `yaml:"name"`
+	On          Triggers     `yaml:"on"`
+	Concurrency *Concurrency `yaml:"concurrency,omitempty"`
+	Jobs        Jobs         `yaml:"jobs"`
+}
+
+// Triggers are the events that run a workflow
+type Triggers struct {
+	Push             *PushTrigger `yaml:"push,omitempty"`
+	WorkflowDispatch bool         `yaml:"-"`
+}
+
+// MarshalYAML writes workflow_dispatch as a key without a value
+func (t Triggers) MarshalYAML() (interface{}, error) {
+	node := &yaml.Node{Kind: yaml.MappingNode}
+	if t.Push != nil {
+		var push yaml.Node
+		if err := push.Encode(t.Push); err != nil {
+			return nil, err
+		}
+		node.Content = append(node.Content, scalar("push"), &push)
+	}
+	if t.WorkflowDispatch {
+		node.Content = append(node.Content, scalar("workflow_dispatch"), &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!null"})
+	}
+	return node, nil
+}
+
+// PushTrigger runs a workflow on pushes to branches or tags
+type PushTrigger struct {
+	Branches []string `yaml:"branches,omitempty,flow"`
+	Tags     []string `yaml:"tags,omitempty,flow"`
+	Paths    []string `yaml:"paths,omitempty"`
+}
+
+// Concurrency limits a workflow or job to one run per group
+type Concurrency struct {
+	Group            string `yaml:"group"`
+	CancelInProgress bool   `yaml:"cancel-in-progress"`
+}
+
+// Job is a workflow job. ID is its key under jobs.
+type Job struct {
+	ID          string       `yaml:"-"`
+	If          string       `yaml:"if,omitempty"`
+	Name        string       `yaml:"name,omitempty"`
+	RunsOn      string       `yaml:"runs-on"`
+	Environment *Environment `yaml:"environment,omitempty"`
+	Concurrency *Concurrency `yaml:"concurrency,omitempty"`
+	Steps       []Step       `yaml:"steps"`
+}
+
+// Jobs keeps jobs in order, unlike a map
+type Jobs []Job
+
+// MarshalYAML writes jobs as a mapping keyed by job ID
+func (j Jobs) MarshalYAML() (interface{}, error) {
+	node := &yaml.Node{Kind: yaml.MappingNode}
+	for _, job := range j {
+		var value yaml.Node
+		if err := value.Encode(job); err != nil {
+			return nil, err
+		}
+		node.Content = append(node.Content, scalar(job.ID), &value)
+	}
+	return node, nil
+}
+
+// Environment is the GitHub environment a job deploys to
+type Environment struct {
+	Name string `yaml:"name"`
+	URL  string `yaml:"url,omitempty"`
+}
+
+// MarshalYAML uses the short form when the environment has no URL
+func (e Environment) MarshalYAML() (interface{}, error) {
+	if e.URL == "" {
+		return e.Name, nil
+	}
+	type plain Environment
+	return plain(e), nil
+}
+
+// Step is a single step of a job
+type Step struct {
+	Name string     `yaml:"name"`
+	Uses string     `yaml:"uses,omitempty"`
+	With *yaml.Node `yaml:"with,omitempty"`
+}
+
+// BuildWorkflow builds a workflow with a single deploy job passing the given secrets to the action
+func BuildWorkflow(opts WorkflowOptions, secrets []string) *Workflow {
+	job := deployJob("deploy", "Deploy to Laravel Forge", opts.ConfigFileName, secrets, opts)
+	if opts.Environment != "" {
+		job.Environment = &Environment{Name: opts.Environment, URL: opts.EnvironmentURL}
+	}
+
+	return &Workflow{
+		Name:        opts.Name,
+		On:          triggers(opts),
+		Concurrency: concurrency(opts.Concurrency, opts),
+		Jobs:        Jobs{job},
+	}
+}
+
+// BuildEnvironmentsWorkflow builds a workflow with one deploy job per
+// environment, each running only for its own branch and in its own GitHub
+// environment. Branches, Tags, Environment and ConfigFileName of opts are
+// ignored since each job is selected by its branch.
+func BuildEnvironmentsWorkflow(targets []EnvironmentTarget, opts WorkflowOptions) *Workflow {
+	opts.Branches, opts.Tags = nil, nil
+	for _, target := range targets {
+		opts.Branches = append(opts.Branches, target.Branch)
+	}
+
+	workflow := &Workflow{Name: opts.Name, On: triggers(opts)}
+	for _, target := range targets {
+		job := deployJob("deploy-"+target.Name, fmt.Sprintf("Deploy %s to Laravel Forge", target.Name), target.ConfigFileName, target.Secrets, opts)
+		job.If = fmt.Sprintf("github.ref == 'refs/heads/%s'", target.Branch)
+		job.Environment = &Environment{Name: target.Name}
+		if opts.Concurrency != "" {
+			job.Concurrency = concurrency(opts.Concurrency+"-"+target.Name, opts)
+		}
+		workflow.Jobs = append(workflow.Jobs, job)
+	}
+	return workflow
+}
+
+// deployJob builds a job that checks out the repository and runs the deploy action
+func deployJob(id, name, configFileName string, secrets []string, opts WorkflowOptions) Job {
+	with := &yaml.Node{Kind: yaml.MappingNode}
+	with.Content = append(with.Content,
+		scalar("forge_api_token"), scalar("${{ secrets.FORGE_API_TOKEN }}"),
+		scalar("deployment_file"), scalar(configFileName),
+	)
+	if len(secrets) > 0 {
+		var lines strings.Builder
+		for _, secret := range secrets {
+			lines.WriteString(secret + "=${{ secrets." + secret + " }}\n")
+		}
+		value := scalar(lines.String())
+		value.Style = yaml.LiteralStyle
+		with.Content = append(with.Content, scalar("secrets"), value)
+	}
+
+	return Job{
+		ID:     id,
+		Name:   name,
+		RunsOn: orDefault(opts.Runner, DefaultRunner),
+		Steps: []Step{
+			{Name: "Checkout code", Uses: "actions/checkout@" + orDefault(opts.CheckoutVersion, DefaultCheckoutVersion)},
+			{Name: "Deploy to Forge", Uses: DeployAction + "@" + orDefault(opts.ActionVersion, DefaultActionVersion), With: with},
+		},
+	}
+}
+
+func triggers(opts WorkflowOptions) Triggers {
+	return Triggers{
+		Push:             &PushTrigger{Branches: opts.Branches, Tags: opts.Tags, Paths: opts.Paths},
+		WorkflowDispatch: true,
+	}
+}
+
+func concurrency(group string, opts WorkflowOptions) *Concurrency {
+	if group == "" {
+		return nil
+	}
+	return &Concurrency{Group: group, CancelInProgress: opts.CancelInProgress}
+}
+
+// Marshal renders a workflow with a header comment and a blank line between
+// top-level sections
+func (w *Workflow) Marshal(reviewers []string) (string, error) {
+	var root yaml.Node
+	if err := root.Encode(w); err != nil {
+		return "", fmt.Errorf("failed to encode workflow: %w", err)
+	}
+
+	for i := 0; i+1 < len(root.Content); i += 2 {
+		// "on" is a YAML 1.1 boolean; keep it plain as GitHub documents it
+		if key := root.Content[i]; key.Value == "on" {
+			key.Style = 0
+		}
+	}
+
+	if len(reviewers) > 0 {
+		annotateEnvironments(&root, reviewers)
+	}
+
+	var buf bytes.Buffer
+	enc := yaml.NewEncoder(&buf)
+	enc.SetIndent(2)
+	if err := enc.Encode(&root); err != nil {
+		return "", fmt.Errorf("failed to encode workflow: %w", err)
+	}
+	if err := enc.Close(); err != nil {
+		return "", fmt.Errorf("failed to encode workflow: %w", err)
+	}
+
+	header := `# GitHub Actions Workflow for Laravel Forge Deployment
+# Generated by forge-deploy-cli
+
+`
+	return header + spaceSections(buf.String()), nil
+}
+
+// spaceSections inserts a blank line before every top-level key but the first
+func spaceSections(content string) string {
+	lines := strings.Split(content, "\n")
+	var out []string
+	for i, line := range lines {
+		if i > 0 && line != "" && line[0] != ' ' && line[0] != '#' && line[0] != '-' {
+			out = append(out, "")
+		}
+		out = append(out, line)
+	}
+	return strings.Join(out, "\n")
+}
+
+// annotateEnvironments documents the required reviewers above each environment key
+func annotateEnvironments(node *yaml.Node, reviewers []string) {
+	if node.Kind == yaml.MappingNode {
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			if node.Content[i].Value == "environment" {
+				node.Content[i].HeadComment = fmt.Sprintf("Protected environment: deployments wait for approval from %s\n(configure required reviewers in Settings > Environments)", strings.Join(reviewers, ", "))
+			}
+		}
+	}
+	for _, child := range node.Content {
+		annotateEnvironments(child, reviewers)
+	}
+}
+
+func scalar(value string) *yaml.Node {
+	return &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: value}
+}
+
+func orDefault(value, def string) string {
+	if value == "" {
+		return def
+	}
+	return value
+}
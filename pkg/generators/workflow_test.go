@@ -0,0 +1,177 @@
+package generators
+
+import (
+	"flag"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"gopkg.in/yaml.v3"
+)
+
+var update = flag.Bool("update", false, "update the golden files in testdata")
+
+const fullSHA = "8f4b7f84864484a7bf31766abe9204da3cbe65b3"
+
+// checkGolden compares content with a golden file in testdata
+func checkGolden(t *testing.T, name, content string) {
+	t.Helper()
+	golden := filepath.Join("testdata", name+".golden")
+	if *update {
+		if err := os.WriteFile(golden, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	want, err := os.ReadFile(golden)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if content != string(want) {
+		t.Errorf("%s: got\n%s\nwant\n%s", golden, content, want)
+	}
+}
+
+func TestWorkflowGolden(t *testing.T) {
+	tests := []struct {
+		name    string
+		opts    WorkflowOptions
+		secrets []string
+	}{
+		{
+			name: "branch",
+			opts: WorkflowOptions{Name: "Deploy", Branches: []string{"main"}, ConfigFileName: "forge-deploy.yml"},
+		},
+		{
+			name:    "tags",
+			opts:    WorkflowOptions{Name: "Release", Tags: []string{"v*"}, Paths: []string{"app/**", "composer.lock"}, ConfigFileName: "forge-deploy.yml"},
+			secrets: []string{"APP_KEY", "STRIPE_SECRET"},
+		},
+		{
+			name: "environment",
+			opts: WorkflowOptions{
+				Name:             "Deploy production",
+				Branches:         []string{"main"},
+				Runner:           "self-hosted",
+				ActionVersion:    fullSHA,
+				CheckoutVersion:  fullSHA,
+				Concurrency:      "forge-production",
+				CancelInProgress: true,
+				Environment:      "production",
+				EnvironmentURL:   "https://app.example.com",
+				Reviewers:        []string{"alice", "ops-team"},
+				ConfigFileName:   "forge-deploy.yml",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.opts.Validate(); err != nil {
+				t.Fatal(err)
+			}
+			content, err := BuildWorkflow(tt.opts, tt.secrets).Marshal(tt.opts.Reviewers)
+			if err != nil {
+				t.Fatal(err)
+			}
+			checkGolden(t, "workflow-"+tt.name, content)
+		})
+	}
+}
+
+func TestEnvironmentsWorkflowGolden(t *testing.T) {
+	content, err := GenerateEnvironmentsWorkflow([]EnvironmentTarget{
+		{Name: "staging", Branch: "develop", ConfigFileName: "forge-deploy.staging.yml"},
+		{Name: "production", Branch: "main", ConfigFileName: "forge-deploy.production.yml", Secrets: []string{"APP_KEY"}},
+	}, WorkflowOptions{Name: "Deploy", Tags: []string{"v*"}, Concurrency: "forge"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	checkGolden(t, "workflow-environments", content)
+}
+
+func TestWorkflowStructure(t *testing.T) {
+	opts := WorkflowOptions{
+		Name:           "Deploy",
+		Branches:       []string{"main"},
+		Concurrency:    "forge",
+		Environment:    "production",
+		EnvironmentURL: "https://app.example.com",
+		ActionVersion:  fullSHA,
+	}
+	content, err := BuildWorkflow(opts, nil).Marshal(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var workflow struct {
+		On          map[string]interface{} `yaml:"on"`
+		Concurrency struct {
+			Group            string `yaml:"group"`
+			CancelInProgress bool   `yaml:"cancel-in-progress"`
+		} `yaml:"concurrency"`
+		Jobs map[string]struct {
+			Environment struct {
+				Name string `yaml:"name"`
+				URL  string `yaml:"url"`
+			} `yaml:"environment"`
+			Steps []struct {
+				Uses string `yaml:"uses"`
+			} `yaml:"steps"`
+		} `yaml:"jobs"`
+	}
+	if err := yaml.Unmarshal([]byte(content), &workflow); err != nil {
+		t.Fatalf("generated workflow is not valid YAML: %v\n%s", err, content)
+	}
+
+	if _, ok := workflow.On["push"]; !ok {
+		t.Error("no push trigger")
+	}
+	if _, ok := workflow.On["workflow_dispatch"]; !ok {
+		t.Error("no workflow_dispatch trigger")
+	}
+	if workflow.Concurrency.Group != "forge" || workflow.Concurrency.CancelInProgress {
+		t.Errorf("got concurrency %+v", workflow.Concurrency)
+	}
+	deploy := workflow.Jobs["deploy"]
+	if deploy.Environment.Name != "production" || deploy.Environment.URL != "https://app.example.com" {
+		t.Errorf("got environment %+v", deploy.Environment)
+	}
+	if len(deploy.Steps) != 2 || deploy.Steps[1].Uses != DeployAction+"@"+fullSHA {
+		t.Errorf("got steps %+v, want the action pinned to %s", deploy.Steps, fullSHA)
+	}
+}
+
+func TestWorkflowOptionsValidate(t *testing.T) {
+	valid := WorkflowOptions{Branches: []string{"main"}}
+
+	tests := []struct {
+		name    string
+		modify  func(o *WorkflowOptions)
+		message string
+	}{
+		{"defaults", func(o *WorkflowOptions) {}, ""},
+		{"tag", func(o *WorkflowOptions) { o.ActionVersion = "v2.1.0" }, ""},
+		{"full SHA", func(o *WorkflowOptions) { o.ActionVersion = fullSHA }, ""},
+		{"abbreviated SHA", func(o *WorkflowOptions) { o.ActionVersion = "8f4b7f8" }, "abbreviated commit SHA"},
+		{"abbreviated checkout SHA", func(o *WorkflowOptions) { o.CheckoutVersion = fullSHA[:12] }, "abbreviated commit SHA"},
+		{"invalid ref", func(o *WorkflowOptions) { o.ActionVersion = "v2 beta" }, "not a valid git ref"},
+		{"reviewers without environment", func(o *WorkflowOptions) { o.Reviewers = []string{"alice"} }, "need an environment"},
+		{"no trigger", func(o *WorkflowOptions) { o.Branches = nil }, "at least one branch or tag"},
+		{"tags only", func(o *WorkflowOptions) { o.Branches, o.Tags = nil, []string{"v*"} }, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			opts := valid
+			tt.modify(&opts)
+			err := opts.Validate()
+			switch {
+			case tt.message == "" && err != nil:
+				t.Errorf("got %v, want no error", err)
+			case tt.message != "" && (err == nil || !strings.Contains(err.Error(), tt.message)):
+				t.Errorf("got %v, want %q", err, tt.message)
+			}
+		})
+	}
+}
@@ -0,0 +1,181 @@
+// Package hostname validates the domain names of sites and their aliases,
+// including internationalized names and their punycode form.
+package hostname
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"strings"
+	"unicode"
+)
+
+// OnForgeSuffix is appended by Forge to the names of on-forge sites
+const OnForgeSuffix = ".on-forge.com"
+
+const (
+	maxLength      = 253
+	maxLabelLength = 63
+)
+
+// ToASCII lowercases name and converts its internationalized labels to
+// punycode ("xn--" labels)
+func ToASCII(name string) (string, error) {
+	labels := strings.Split(strings.ToLower(name), ".")
+	for i, label := range labels {
+		if isASCII(label) {
+			continue
+		}
+		encoded, err := encode(label)
+		if err != nil {
+			return "", fmt.Errorf("label %q cannot be converted to punycode: %w", label, err)
+		}
+		labels[i] = "xn--" + encoded
+	}
+	return strings.Join(labels, "."), nil
+}
+
+// Check validates a fully qualified hostname following RFC 1123. Unicode
+// names are checked in their punycode form. With allowWildcard, the leftmost
+// label may be "*", as in *.example.com.
+func Check(name string, allowWildcard bool) error {
+	if err := checkTypos(name); err != nil {
+		return err
+	}
+	if ip := net.ParseIP(name); ip != nil {
+		return errors.New("is an IP address, not a domain name")
+	}
+
+	ascii, err := ToASCII(name)
+	if err != nil {
+		return err
+	}
+	if strings.HasSuffix(ascii, ".") {
+		return errors.New("must not end with a dot")
+	}
+	if len(ascii) > maxLength {
+		return fmt.Errorf("is longer than %d characters", maxLength)
+	}
+
+	labels := strings.Split(ascii, ".")
+	if len(labels) < 2 {
+		return errors.New("must be a fully qualified domain name, e.g. example.com")
+	}
+	for i, label := range labels {
+		if label == "*" {
+			if !allowWildcard {
+				return errors.New("wildcards are only allowed in aliases")
+			}
+			if i != 0 {
+				return errors.New("a wildcard must be the leftmost label, e.g. *.example.com")
+			}
+			if len(labels) < 3 {
+				return errors.New("a wildcard must cover the subdomains of a domain, e.g. *.example.com")
+			}
+			continue
+		}
+		if err := checkLabel(label); err != nil {
+			return err
+		}
+	}
+
+	tld := labels[len(labels)-1]
+	if strings.Trim(tld, "0123456789") == "" {
+		return fmt.Errorf("top-level domain %q must not be numeric", tld)
+	}
+	return nil
+}
+
+// CheckOnForge validates the name of an on-forge site, which Forge serves as
+// a subdomain of on-forge.com
+func CheckOnForge(name string) error {
+	if strings.HasSuffix(strings.ToLower(name), OnForgeSuffix) {
+		return fmt.Errorf("must not include the %s suffix; Forge appends it", OnForgeSuffix)
+	}
+	if err := checkTypos(name); err != nil {
+		return err
+	}
+	if strings.Contains(name, ".") {
+		return errors.New("must be a single label without dots; use domain_mode custom for your own domain")
+	}
+	if !isASCII(name) {
+		return errors.New("must only contain ASCII letters, digits and hyphens")
+	}
+	if name != strings.ToLower(name) {
+		return errors.New("must be lowercase")
+	}
+	return checkLabel(name)
+}
+
+// checkTypos catches URLs and lists pasted where a single name is expected
+func checkTypos(name string) error {
+	switch {
+	case name == "":
+		return errors.New("is empty")
+	case strings.Contains(name, "://"):
+		return errors.New("must not include a scheme such as https://")
+	case strings.ContainsAny(name, ", \t"):
+		return errors.New("must be a single name; list several names as separate aliases")
+	case strings.Contains(name, "/"):
+		return errors.New("must not include a path")
+	case strings.Contains(name, ":") && net.ParseIP(name) == nil:
+		return errors.New("must not include a port")
+	}
+	return nil
+}
+
+// checkLabel validates a single ASCII label
+func checkLabel(label string) error {
+	if label == "" {
+		return errors.New("contains an empty label")
+	}
+	if len(label) > maxLabelLength {
+		return fmt.Errorf("label %q is longer than %d characters", label, maxLabelLength)
+	}
+	for _, c := range label {
+		switch {
+		case c >= 'a' && c <= 'z', c >= 'A' && c <= 'Z', c >= '0' && c <= '9', c == '-':
+		case c == '_':
+			return fmt.Errorf("label %q contains an underscore, which is not allowed in hostnames", label)
+		case c == '*':
+			return fmt.Errorf("label %q contains \"*\"; a wildcard must be a whole label, e.g. *.example.com", label)
+		default:
+			return fmt.Errorf("label %q contains %q, which is not allowed in hostnames", label, c)
+		}
+	}
+	if strings.HasPrefix(label, "-") || strings.HasSuffix(label, "-") {
+		return fmt.Errorf("label %q must not start or end with a hyphen", label)
+	}
+
+	if len(label) >= 4 && label[2:4] == "--" {
+		prefix := strings.ToLower(label[:4])
+		if prefix != "xn--" {
+			return fmt.Errorf("label %q has hyphens in the third and fourth positions, which are reserved for punycode", label)
+		}
+		decoded, err := decode(label[4:])
+		if err != nil {
+			return fmt.Errorf("label %q is not valid punycode: %v", label, err)
+		}
+		if isASCII(decoded) {
+			return fmt.Errorf("label %q is punycode for the ASCII label %q; use that instead", label, decoded)
+		}
+		for _, c := range decoded {
+			if c != '-' && !unicode.IsLetter(c) && !unicode.IsMark(c) && !unicode.IsDigit(c) {
+				return fmt.Errorf("label %q decodes to %q, which contains %q and is not allowed in hostnames", label, decoded, c)
+			}
+		}
+		if reencoded, err := encode(strings.ToLower(decoded)); err != nil || "xn--"+reencoded != strings.ToLower(label) {
+			return fmt.Errorf("label %q is not in canonical punycode form (it decodes to %q)", label, decoded)
+		}
+	}
+	return nil
+}
+
+func isASCII(s string) bool {
+	for _, c := range s {
+		if c > unicode.MaxASCII {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,174 @@
+package hostname
+
+import (
+	"errors"
+	"math"
+	"strings"
+	"unicode"
+)
+
+// Punycode parameters from RFC 3492
+const (
+	base        = 36
+	tmin        = 1
+	tmax        = 26
+	skew        = 38
+	damp        = 700
+	initialBias = 72
+	initialN    = 128
+)
+
+var errOverflow = errors.New("punycode overflow")
+
+// encode converts a Unicode label to punycode, without the "xn--" prefix
+func encode(s string) (string, error) {
+	runes := []rune(s)
+	var out []byte
+	for _, r := range runes {
+		if r < 0x80 {
+			out = append(out, byte(r))
+		}
+	}
+	b := len(out)
+	h := b
+	if b > 0 {
+		out = append(out, '-')
+	}
+
+	n, delta, bias := rune(initialN), 0, initialBias
+	for h < len(runes) {
+		m := rune(unicode.MaxRune + 1)
+		for _, r := range runes {
+			if r >= n && r < m {
+				m = r
+			}
+		}
+		if int(m-n) > (math.MaxInt32-delta)/(h+1) {
+			return "", errOverflow
+		}
+		delta += int(m-n) * (h + 1)
+		n = m
+
+		for _, r := range runes {
+			if r < n {
+				delta++
+			}
+			if r != n {
+				continue
+			}
+			q := delta
+			for k := base; ; k += base {
+				t := threshold(k, bias)
+				if q < t {
+					break
+				}
+				out = append(out, digit(t+(q-t)%(base-t)))
+				q = (q - t) / (base - t)
+			}
+			out = append(out, digit(q))
+			bias = adapt(delta, h+1, h == b)
+			delta = 0
+			h++
+		}
+		delta++
+		n++
+	}
+	return string(out), nil
+}
+
+// decode converts a punycode label, without the "xn--" prefix, to Unicode
+func decode(s string) (string, error) {
+	var output []rune
+	start := 0
+	if pos := strings.LastIndexByte(s, '-'); pos >= 0 {
+		for _, c := range []byte(s[:pos]) {
+			if c >= 0x80 {
+				return "", errors.New("non-ASCII character before the delimiter")
+			}
+			output = append(output, rune(c))
+		}
+		start = pos + 1
+	}
+
+	n, i, bias := rune(initialN), 0, initialBias
+	for in := start; in < len(s); {
+		oldi, w := i, 1
+		for k := base; ; k += base {
+			if in >= len(s) {
+				return "", errors.New("truncated input")
+			}
+			d := digitValue(s[in])
+			in++
+			if d < 0 {
+				return "", errors.New("invalid character")
+			}
+			if d > (math.MaxInt32-i)/w {
+				return "", errOverflow
+			}
+			i += d * w
+			t := threshold(k, bias)
+			if d < t {
+				break
+			}
+			if w > math.MaxInt32/(base-t) {
+				return "", errOverflow
+			}
+			w *= base - t
+		}
+
+		l := len(output) + 1
+		bias = adapt(i-oldi, l, oldi == 0)
+		if i/l > unicode.MaxRune-int(n) {
+			return "", errOverflow
+		}
+		n += rune(i / l)
+		i %= l
+		output = append(output[:i], append([]rune{n}, output[i:]...)...)
+		i++
+	}
+	return string(output), nil
+}
+
+func threshold(k, bias int) int {
+	switch {
+	case k <= bias:
+		return tmin
+	case k >= bias+tmax:
+		return tmax
+	}
+	return k - bias
+}
+
+func adapt(delta, numPoints int, first bool) int {
+	if first {
+		delta /= damp
+	} else {
+		delta /= 2
+	}
+	delta += delta / numPoints
+	k := 0
+	for delta > ((base-tmin)*tmax)/2 {
+		delta /= base - tmin
+		k += base
+	}
+	return k + (base-tmin+1)*delta/(delta+skew)
+}
+
+func digit(d int) byte {
+	if d < 26 {
+		return byte('a' + d)
+	}
+	return byte('0' + d - 26)
+}
+
+func digitValue(c byte) int {
+	switch {
+	case c >= '0' && c <= '9':
+		return int(c-'0') + 26
+	case c >= 'a' && c <= 'z':
+		return int(c - 'a')
+	case c >= 'A' && c <= 'Z':
+		return int(c - 'A')
+	}
+	return -1
+}
@@ -0,0 +1,195 @@
+package hostname
+
+import (
+	"strings"
+	"testing"
+)
+
+// Sample strings from RFC 3492 section 7.1, with errata 3026
+var rfc3492 = []struct {
+	name, unicode, encoded string
+}{
+	{"(A) Arabic (Egyptian)",
+		"ليهمابتكلموشعربي؟",
+		"egbpdaj6bu4bxfgehfvwxn"},
+	{"(B) Chinese (simplified)",
+		"他们为什么不说中文",
+		"ihqwcrb4cv8a8dqg056pqjye"},
+	{"(C) Chinese (traditional)",
+		"他們爲什麽不說中文",
+		"ihqwctvzc91f659drss3x8bo0yb"},
+	{"(D) Czech",
+		"Pročprostěnemluvíčesky",
+		"Proprostnemluvesky-uyb24dma41a"},
+	{"(E) Hebrew",
+		"למההםפשוטלאמדבריםעברית",
+		"4dbcagdahymbxekheh6e0a7fei0b"},
+	{"(F) Hindi (Devanagari)",
+		"यहलोगहिन्दीक्योंनहींबोलसकतेहैं",
+		"i1baa7eci9glrd9b2ae1bj0hfcgg6iyaf8o0a1dig0cd"},
+	{"(G) Japanese (kanji and hiragana)",
+		"なぜみんな日本語を話してくれないのか",
+		"n8jok5ay5dzabd5bym9f0cm5685rrjetr6pdxa"},
+	{"(H) Korean (Hangul syllables)",
+		"세계의모든사람들이한국어를이해한다면얼마나좋을까",
+		"989aomsvi5e83db1d2a355cv1e0vak1dwrv93d5xbh15a0dt30a5jpsd879ccm6fea98c"},
+	{"(I) Russian (Cyrillic)",
+		"почемужеонинеговорятпорусски",
+		"b1abfaaepdrnnbgefbadotcwatmq2g4l"},
+	{"(J) Spanish",
+		"PorquénopuedensimplementehablarenEspañol",
+		"PorqunopuedensimplementehablarenEspaol-fmd56a"},
+	{"(K) Vietnamese",
+		"TạisaohọkhôngthểchỉnóitiếngViệt",
+		"TisaohkhngthchnitingVit-kjcr8268qyxafd2f1b9g"},
+	{"(L) 3<nen>B<gumi><kinpachi><sensei>",
+		"3年B組金八先生",
+		"3B-ww4c5e180e575a65lsy2b"},
+	{"(M) <amuro><namie>-with-SUPER-MONKEYS",
+		"安室奈美恵-with-SUPER-MONKEYS",
+		"-with-SUPER-MONKEYS-pc58ag80a8qai00g7n9n"},
+	{"(N) Hello-Another-Way-<sorezore><no><basho>",
+		"Hello-Another-Way-それぞれの場所",
+		"Hello-Another-Way--fc4qua05auwb3674vfr0b"},
+	{"(O) <hitotsu><yane><no><shita>2",
+		"ひとつ屋根の下2",
+		"2-u9tlzr9756bt3uc0v"},
+	{"(P) Maji<de>Koi<suru>5<byou><mae>",
+		"MajiでKoiする5秒前",
+		"MajiKoi5-783gue6qz075azm5e"},
+	{"(Q) <pafii>de<runba>",
+		"パフィーdeルンバ",
+		"de-jg4avhby1noc0d"},
+	{"(R) <sono><supiido><de>",
+		"そのスピードで",
+		"d9juau41awczczp"},
+	{"(S) -> $1.00 <-",
+		"-> $1.00 <-",
+		"-> $1.00 <--"},
+}
+
+func TestEncode(t *testing.T) {
+	for _, tt := range rfc3492 {
+		got, err := encode(tt.unicode)
+		if err != nil {
+			t.Errorf("%s: %v", tt.name, err)
+			continue
+		}
+		if got != tt.encoded {
+			t.Errorf("%s: got %q, want %q", tt.name, got, tt.encoded)
+		}
+	}
+}
+
+func TestDecode(t *testing.T) {
+	for _, tt := range rfc3492 {
+		got, err := decode(tt.encoded)
+		if err != nil {
+			t.Errorf("%s: %v", tt.name, err)
+			continue
+		}
+		if got != tt.unicode {
+			t.Errorf("%s: got %q, want %q", tt.name, got, tt.unicode)
+		}
+	}
+
+	// Digits are case insensitive
+	if got, err := decode("IHQWCRB4CV8A8DQG056PQJYE"); err != nil || got != rfc3492[1].unicode {
+		t.Errorf("uppercase digits: got %q, %v", got, err)
+	}
+}
+
+func TestRoundTrip(t *testing.T) {
+	for _, s := range []string{"", "a", "ü", "üý", "bücher", "münchen", "a-b-ü", "例え", "\U0001F600"} {
+		encoded, err := encode(s)
+		if err != nil {
+			t.Errorf("encode(%q): %v", s, err)
+			continue
+		}
+		if decoded, err := decode(encoded); err != nil || decoded != s {
+			t.Errorf("%q encodes to %q, which decodes to %q, %v", s, encoded, decoded, err)
+		}
+	}
+}
+
+func TestDecodeErrors(t *testing.T) {
+	tests := []struct {
+		input, message string
+	}{
+		{"9", "truncated input"},
+		{"a-9", "truncated input"},
+		{"zzzzzzzzzzzzzzz", "truncated input"},
+		{"xyz-ab_", "invalid character"},
+		{"bü-tda", "non-ASCII character before the delimiter"},
+		{"99999999999a", "punycode overflow"},
+		{"-99999999999", "punycode overflow"},
+	}
+
+	for _, tt := range tests {
+		if _, err := decode(tt.input); err == nil || err.Error() != tt.message {
+			t.Errorf("decode(%q): got %v, want %s", tt.input, err, tt.message)
+		}
+	}
+}
+
+func TestToASCII(t *testing.T) {
+	tests := []struct {
+		name, want string
+	}{
+		{"example.com", "example.com"},
+		{"Example.COM", "example.com"},
+		{"bücher.de", "xn--bcher-kva.de"},
+		{"Bücher.DE", "xn--bcher-kva.de"},
+		{"shop.münchen.de", "shop.xn--mnchen-3ya.de"},
+		{"例え.テスト", "xn--r8jz45g.xn--zckzah"},
+		{"xn--bcher-kva.de", "xn--bcher-kva.de"},
+	}
+
+	for _, tt := range tests {
+		got, err := ToASCII(tt.name)
+		if err != nil {
+			t.Errorf("%s: %v", tt.name, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("%s: got %s, want %s", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestCheckPunycodeLabels(t *testing.T) {
+	tests := []struct {
+		name    string
+		message string
+	}{
+		{"xn--bcher-kva.de", ""},
+		{"XN--BCHER-KVA.de", ""},
+		{"xn--tda.de", ""},
+		{"bücher.de", ""},
+		// Uppercase Unicode has a different encoding than the lowercase name
+		{"xn--wca.de", "not in canonical punycode form"},
+		// An empty basic part must not have a delimiter
+		{"xn---tda.de", "not in canonical punycode form"},
+		{"xn--abc-.de", "must not start or end with a hyphen"},
+		{"xn--abc-b.de", "not valid punycode"},
+		{"xn--9.de", "not valid punycode: truncated input"},
+		{"xn--example-.de", "must not start or end with a hyphen"},
+		{"xn--example-a.de", "is not allowed in hostnames"},
+		{"xn--abc.de", "is not allowed in hostnames"},
+		{"xn--ab-fsx.de", "is not allowed in hostnames"},
+		{"a☃b.de", "is not allowed in hostnames"},
+		{"ab--c.de", "reserved for punycode"},
+	}
+
+	for _, tt := range tests {
+		err := Check(tt.name, false)
+		switch {
+		case tt.message == "" && err != nil:
+			t.Errorf("%s: %v", tt.name, err)
+		case tt.message != "" && err == nil:
+			t.Errorf("%s: got no error, want %q", tt.name, tt.message)
+		case tt.message != "" && !strings.Contains(err.Error(), tt.message):
+			t.Errorf("%s: got %q, want %q", tt.name, err, tt.message)
+		}
+	}
+}
@@ -0,0 +1,396 @@
+package importer
+
+import (
+	"context"
+	"fmt"
+	"path"
+	"sort"
+	"strings"
+
+	"github.com/the-trybe/forge-deploy-cli/pkg/forge"
+	"github.com/the-trybe/forge-deploy-cli/pkg/models"
+	"github.com/the-trybe/forge-deploy-cli/pkg/secrets"
+)
+
+// Options selects what to import from a Forge server
+type Options struct {
+	Organization    string
+	Server          string
+	Sites           []string
+	Repository      string
+	SkipEnvironment bool
+	// Environments limits the sites whose environment is read to these
+	// names, as Forge knows them. All are read when it is nil.
+	Environments []string
+}
+
+// Result is an imported configuration plus anything that could not be mapped
+type Result struct {
+	Config   *models.DeploymentConfig
+	Warnings []string
+}
+
+// Import reads the sites of a Forge server and maps them onto a deployment
+// configuration. Values of the imported environments that look like real
+// secrets are replaced with ${KEY} placeholders, which the deploy action fills
+// in from the GitHub secrets of the same name.
+func Import(ctx context.Context, client *forge.Client, opts Options) (*Result, error) {
+	result, err := importSites(ctx, client, opts)
+	if err != nil {
+		return nil, err
+	}
+	if len(result.Config.Sites) == 0 {
+		return nil, fmt.Errorf("no sites to import on server %s", opts.Server)
+	}
+
+	for i := range result.Config.Sites {
+		site := &result.Config.Sites[i]
+		findings := secrets.Scan(site.Environment)
+		if len(findings) == 0 {
+			continue
+		}
+		site.Environment = secrets.Replace(site.Environment, findings)
+
+		keys := make([]string, len(findings))
+		for j, finding := range findings {
+			keys[j] = finding.Key
+		}
+		result.Warnings = append(result.Warnings, fmt.Sprintf("site %s: replaced secrets in the environment with placeholders: %s", site.Name, strings.Join(keys, ", ")))
+	}
+	return result, nil
+}
+
+// Live reads every site of a Forge server as it currently is. Unlike Import it
+// succeeds with no sites when the server is empty. Only the environments of
+// the sites named in environments are read, and unlike Import their secrets
+// are not replaced with placeholders, since plan compares the live values with
+// the configured ones. Callers must not print environment values.
+func Live(ctx context.Context, client *forge.Client, organization, server string, environments []string) (*Result, error) {
+	return importSites(ctx, client, Options{
+		Organization:    organization,
+		Server:          server,
+		SkipEnvironment: len(environments) == 0,
+		Environments:    environments,
+	})
+}
+
+// importSites maps the sites selected by opts onto a deployment configuration
+func importSites(ctx context.Context, client *forge.Client, opts Options) (*Result, error) {
+	server, err := client.FindServer(ctx, opts.Organization, opts.Server)
+	if err != nil {
+		return nil, err
+	}
+
+	sites, err := client.ListSites(ctx, opts.Organization, server.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list sites: %w", err)
+	}
+
+	all := sites
+	sites, err = filterSites(sites, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	daemons, err := client.ListDaemons(ctx, opts.Organization, server.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list background processes: %w", err)
+	}
+
+	jobs, err := client.ListScheduledJobs(ctx, opts.Organization, server.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list scheduled jobs: %w", err)
+	}
+
+	result := &Result{
+		Config: &models.DeploymentConfig{
+			Organization:     opts.Organization,
+			Server:           server.Name,
+			GithubRepository: opts.Repository,
+			GithubBranch:     mostCommon(sites, func(s forge.Site) string { return s.Branch }),
+		},
+	}
+	if result.Config.GithubRepository == "" {
+		result.Config.GithubRepository = mostCommon(sites, func(s forge.Site) string { return s.Repository })
+	}
+	if result.Config.GithubBranch == "" {
+		result.Config.GithubBranch = "main"
+	}
+
+	// Process names are unique across the server's sites
+	names := make(map[string]int)
+	for _, site := range sites {
+		if site.Repository != "" && site.Repository != result.Config.GithubRepository {
+			result.Warnings = append(result.Warnings, fmt.Sprintf("site %s deploys %s, not %s", site.Name, site.Repository, result.Config.GithubRepository))
+		}
+
+		config, warnings, err := importSite(ctx, client, opts, server, site, daemons, jobs, names)
+		if err != nil {
+			return nil, fmt.Errorf("site %s: %w", site.Name, err)
+		}
+
+		if config.GithubBranch == result.Config.GithubBranch {
+			config.GithubBranch = ""
+		}
+
+		result.Config.Sites = append(result.Config.Sites, *config)
+		for _, warning := range warnings {
+			result.Warnings = append(result.Warnings, fmt.Sprintf("site %s: %s", site.Name, warning))
+		}
+	}
+
+	for _, daemon := range unattributed(daemons, all) {
+		result.Warnings = append(result.Warnings, fmt.Sprintf("background process %q (directory %s) belongs to no site and was not imported", daemon.Command, orNone(daemon.Directory)))
+	}
+
+	return result, nil
+}
+
+// unattributed returns the daemons that run in none of the sites' directories
+func unattributed(daemons []forge.Daemon, sites []forge.Site) []forge.Daemon {
+	var found []forge.Daemon
+	for _, daemon := range daemons {
+		attributed := false
+		for _, site := range sites {
+			if belongsTo(siteHome(site), daemon.Directory, daemon.Command) {
+				attributed = true
+				break
+			}
+		}
+		if !attributed {
+			found = append(found, daemon)
+		}
+	}
+	return found
+}
+
+func orNone(value string) string {
+	if value == "" {
+		return "none"
+	}
+	return value
+}
+
+// filterSites keeps the sites selected by name or repository
+func filterSites(sites []forge.Site, opts Options) ([]forge.Site, error) {
+	var filtered []forge.Site
+	found := make(map[string]bool)
+
+	for _, site := range sites {
+		if len(opts.Sites) > 0 && !contains(opts.Sites, site.Name) {
+			continue
+		}
+		if opts.Repository != "" && site.Repository != opts.Repository {
+			continue
+		}
+		found[site.Name] = true
+		filtered = append(filtered, site)
+	}
+
+	for _, name := range opts.Sites {
+		if !found[name] {
+			return nil, fmt.Errorf("site %q not found on server %s", name, opts.Server)
+		}
+	}
+
+	return filtered, nil
+}
+
+// importSite maps a single Forge site onto a site configuration
+func importSite(ctx context.Context, client *forge.Client, opts Options, server *forge.Server, site forge.Site, daemons []forge.Daemon, jobs []forge.ScheduledJob, names map[string]int) (*models.SiteConfig, []string, error) {
+	var warnings []string
+
+	config := &models.SiteConfig{
+		Name:                    site.Name,
+		DomainMode:              "custom",
+		WWWRedirectType:         site.WWWRedirectType,
+		GithubBranch:            site.Branch,
+		RootDir:                 relativeDir(site.RootDirectory),
+		WebDir:                  relativeDir(site.WebDirectory),
+		PHPVersion:              site.PHPVersion,
+		Aliases:                 site.Aliases,
+		NginxTemplate:           site.NginxTemplate,
+		Certificate:             site.Certificate,
+		Isolated:                site.Isolated,
+		ZeroDowntimeDeployments: site.ZeroDowntime,
+	}
+
+	if strings.HasSuffix(site.Name, ".on-forge.com") {
+		config.DomainMode = "on-forge"
+		config.Name = strings.TrimSuffix(site.Name, ".on-forge.com")
+	}
+
+	switch site.ProjectType {
+	case "laravel":
+		config.ProjectType = "laravel"
+	case "":
+	case "php", "html":
+		config.ProjectType = "other"
+	default:
+		config.ProjectType = "other"
+		warnings = append(warnings, fmt.Sprintf("project type %q has no equivalent and was imported as 'other'", site.ProjectType))
+	}
+
+	if site.Isolated {
+		config.IsolatedUser = site.User
+	}
+
+	script, err := client.GetDeploymentScript(ctx, opts.Organization, server.ID, site.ID)
+	if err != nil && !forge.IsNotFound(err) {
+		return nil, nil, fmt.Errorf("failed to fetch deployment script: %w", err)
+	}
+	config.DeploymentScript = strings.TrimSpace(script)
+
+	if !opts.SkipEnvironment && (opts.Environments == nil || contains(opts.Environments, site.Name)) {
+		env, err := client.GetEnvironment(ctx, opts.Organization, server.ID, site.ID)
+		if err != nil && !forge.IsNotFound(err) {
+			return nil, nil, fmt.Errorf("failed to fetch environment: %w", err)
+		}
+		config.Environment = env
+	}
+
+	home := siteHome(site)
+	for _, daemon := range daemons {
+		if !belongsTo(home, daemon.Directory, daemon.Command) {
+			continue
+		}
+		name := processName(daemon.Command)
+		names[name]++
+		if names[name] > 1 {
+			name = fmt.Sprintf("%s-%d", name, names[name])
+		}
+		config.Processes = append(config.Processes, importProcess(name, daemon, home, user(site)))
+	}
+
+	for _, job := range jobs {
+		if !belongsTo(home, "", job.Command) {
+			continue
+		}
+		if strings.Contains(job.Command, "artisan schedule:run") {
+			config.LaravelScheduler = true
+			if job.User != user(site) {
+				warnings = append(warnings, fmt.Sprintf("the Laravel scheduler runs as %s, but laravel_scheduler runs it as %s", job.User, user(site)))
+			}
+			continue
+		}
+		config.ScheduledJobs = append(config.ScheduledJobs, importJob(job, user(site)))
+	}
+
+	config.SetDefaults()
+	return config, warnings, nil
+}
+
+// siteHome returns the directory a site is deployed to on the server
+func siteHome(site forge.Site) string {
+	return path.Join("/home", user(site), site.Name)
+}
+
+// user returns the user a site runs as
+func user(site forge.Site) string {
+	if site.Isolated && site.User != "" {
+		return site.User
+	}
+	return "forge"
+}
+
+// Supervisor's defaults, which are left out of imported processes
+const (
+	defaultStartSecs    = 1
+	defaultStopWaitSecs = 10
+)
+
+// importProcess converts a daemon, keeping only the options that differ from
+// the defaults of a process of the site
+func importProcess(name string, daemon forge.Daemon, home, siteUser string) models.Process {
+	process := models.Process{Name: name, Command: daemon.Command}
+	if dir := path.Clean(daemon.Directory); daemon.Directory != "" && dir != home && dir != home+"/current" {
+		process.Directory = daemon.Directory
+	}
+	if daemon.User != siteUser {
+		process.User = daemon.User
+	}
+	if daemon.Processes > 1 {
+		process.Processes = daemon.Processes
+	}
+	if daemon.StartSecs != defaultStartSecs {
+		process.StartSecs = daemon.StartSecs
+	}
+	if daemon.StopWaitSecs != defaultStopWaitSecs {
+		process.StopWaitSecs = daemon.StopWaitSecs
+	}
+	return process
+}
+
+// belongsTo reports whether a daemon or job runs in the given site directory
+func belongsTo(home, directory, command string) bool {
+	if directory == home || strings.HasPrefix(directory, home+"/") {
+		return true
+	}
+	return strings.Contains(command, home+"/") || strings.HasSuffix(command, home)
+}
+
+// processName derives a process name from its command, e.g. "queue-work"
+func processName(command string) string {
+	fields := strings.Fields(command)
+	for i, field := range fields {
+		if path.Base(field) == "artisan" && i+1 < len(fields) {
+			return strings.ReplaceAll(fields[i+1], ":", "-")
+		}
+	}
+	if len(fields) > 0 {
+		return path.Base(fields[0])
+	}
+	return "process"
+}
+
+// importJob converts a scheduled job, leaving out the site's own user
+func importJob(job forge.ScheduledJob, siteUser string) models.ScheduledJob {
+	imported := models.ScheduledJob{Command: job.Command, Frequency: job.Frequency}
+	if job.User != siteUser {
+		imported.User = job.User
+	}
+	if job.Frequency == "custom" || job.Frequency == "" {
+		imported.Frequency, imported.Cron = "custom", job.Cron
+	}
+	return imported
+}
+
+// relativeDir converts a Forge directory such as "/public" into a repository path
+func relativeDir(dir string) string {
+	dir = strings.Trim(dir, "/")
+	if dir == "" {
+		return "."
+	}
+	return dir
+}
+
+// mostCommon returns the most common non-empty value, preferring the first seen on ties
+func mostCommon(sites []forge.Site, value func(forge.Site) string) string {
+	counts := make(map[string]int)
+	var order []string
+	for _, site := range sites {
+		v := value(site)
+		if v == "" {
+			continue
+		}
+		if counts[v] == 0 {
+			order = append(order, v)
+		}
+		counts[v]++
+	}
+
+	sort.SliceStable(order, func(i, j int) bool { return counts[order[i]] > counts[order[j]] })
+	if len(order) == 0 {
+		return ""
+	}
+	return order[0]
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
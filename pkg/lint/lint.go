@@ -0,0 +1,185 @@
+// Package lint runs static checks on Forge deployment scripts.
+//
+// It only takes primitive arguments so that the models package can use it
+// without an import cycle.
+package lint
+
+import (
+	"fmt"
+	"path"
+	"regexp"
+	"strings"
+)
+
+// Severity is the severity of a finding
+type Severity string
+
+const (
+	Error   Severity = "error"
+	Warning Severity = "warning"
+)
+
+// Finding is a problem found in a deployment script. Line is 0 for findings
+// about the script as a whole.
+type Finding struct {
+	// Code identifies the check, e.g. missing-set-e
+	Code     string
+	Severity Severity
+	Line     int
+	Message  string
+}
+
+func (f Finding) String() string {
+	if f.Line == 0 {
+		return "deployment_script: " + f.Message
+	}
+	return fmt.Sprintf("deployment_script line %d: %s", f.Line, f.Message)
+}
+
+// Errors returns the findings with error severity
+func Errors(findings []Finding) []Finding {
+	var errors []Finding
+	for _, f := range findings {
+		if f.Severity == Error {
+			errors = append(errors, f)
+		}
+	}
+	return errors
+}
+
+const (
+	createRelease   = "$CREATE_RELEASE()"
+	activateRelease = "$ACTIVATE_RELEASE()"
+)
+
+var (
+	setErrexit = regexp.MustCompile(`^set\s+(-[a-zA-Z]*e[a-zA-Z]*|-o\s+errexit)\b`)
+	shebangE   = regexp.MustCompile(`^#!.*\s-[a-zA-Z]*e`)
+	command    = `(?:^|[;&|(]\s*|\bthen\s+|\bdo\s+)(?:sudo\s+(?:-\S+\s+)*)?(?:/usr/(?:local/)?bin/)?`
+	hardPHP    = regexp.MustCompile(command + `php[0-9.]*(?:\s|$)`)
+	hardComp   = regexp.MustCompile(command + `composer(?:\.phar)?(?:\s|$)`)
+	migrate    = regexp.MustCompile(`artisan\s+migrate(?::(\w+))?\b`)
+	cd         = regexp.MustCompile(`(?:^|[;&|(]\s*)cd\s+("[^"]*"|'[^']*'|[^\s;&|)]+)`)
+	siteHome   = regexp.MustCompile(`^/home/[^/]+/[^/]+`)
+)
+
+// Script checks a deployment script of a site with the given zero-downtime
+// setting and root_dir
+func Script(script string, zeroDowntime bool, rootDir string) []Finding {
+	var findings []Finding
+	add := func(code string, severity Severity, line int, format string, args ...interface{}) {
+		findings = append(findings, Finding{Code: code, Severity: severity, Line: line, Message: fmt.Sprintf(format, args...)})
+	}
+
+	rootDir = path.Clean(rootDir)
+	if rootDir == "" {
+		rootDir = "."
+	}
+
+	var createLine, activateLine int
+	hasSetE, changesIntoRoot, runsArtisan := false, rootDir == ".", false
+
+	for i, raw := range strings.Split(strings.ReplaceAll(script, "\r\n", "\n"), "\n") {
+		n := i + 1
+		line := strings.TrimSpace(raw)
+		if i == 0 && shebangE.MatchString(line) {
+			hasSetE = true
+		}
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		if setErrexit.MatchString(line) {
+			hasSetE = true
+		}
+		if strings.Contains(line, createRelease) && createLine == 0 {
+			createLine = n
+		}
+		if strings.Contains(line, activateRelease) && activateLine == 0 {
+			activateLine = n
+		}
+
+		if hardPHP.MatchString(line) {
+			add("hard-coded-php", Warning, n, "hard-coded php; use $FORGE_PHP so the site's PHP version is used")
+		}
+		if hardComp.MatchString(line) {
+			add("hard-coded-composer", Warning, n, "hard-coded composer; use $FORGE_COMPOSER so it runs with the site's PHP version")
+		}
+
+		if m := migrate.FindStringSubmatch(line); m != nil && m[1] != "status" && !strings.Contains(line, "--force") {
+			add("migrate-without-force", Error, n, "'artisan migrate' without --force asks for confirmation in production and fails the deployment")
+		}
+		if strings.Contains(line, "artisan") {
+			runsArtisan = true
+		}
+
+		for _, m := range cd.FindAllStringSubmatch(line, -1) {
+			target := strings.Trim(m[1], `"'`)
+			base, sub := splitTarget(target)
+
+			if zeroDowntime && base == "FORGE_SITE_PATH" {
+				add("cd-site-path", Warning, n, "cd into $FORGE_SITE_PATH on a zero-downtime site; build in $FORGE_RELEASE_DIRECTORY")
+			}
+			if base == "home" {
+				add("hard-coded-path", Warning, n, "cd into the hard-coded path %s; use $FORGE_SITE_PATH or $FORGE_RELEASE_DIRECTORY", target)
+			}
+
+			switch {
+			case sub == "":
+			case sub == rootDir || strings.HasPrefix(sub, rootDir+"/"):
+				changesIntoRoot = true
+			case rootDir != "." && !strings.HasPrefix(rootDir, sub+"/"):
+				add("cd-outside-root-dir", Warning, n, "cd into %s, which is outside root_dir %s", target, rootDir)
+			}
+		}
+	}
+
+	if !hasSetE {
+		add("missing-set-e", Warning, 0, "missing 'set -e'; a failing command will not stop the deployment")
+	}
+
+	switch {
+	case zeroDowntime && createLine == 0:
+		add("missing-release-macro", Error, 0, "zero-downtime deployments need %s to create the release", createRelease)
+	case zeroDowntime && activateLine == 0:
+		add("missing-release-macro", Error, 0, "zero-downtime deployments need %s to make the release live", activateRelease)
+	case zeroDowntime && activateLine < createLine:
+		add("release-macro-order", Error, activateLine, "%s is used before %s", activateRelease, createRelease)
+	case !zeroDowntime && createLine != 0:
+		add("release-macro-without-zero-downtime", Error, createLine, "%s is only available with zero_downtime_deployments", createRelease)
+	case !zeroDowntime && activateLine != 0:
+		add("release-macro-without-zero-downtime", Error, activateLine, "%s is only available with zero_downtime_deployments", activateRelease)
+	}
+
+	if runsArtisan && !changesIntoRoot {
+		add("artisan-outside-root-dir", Warning, 0, "root_dir is %s but the script never changes into it before running artisan", rootDir)
+	}
+
+	return findings
+}
+
+// splitTarget splits a cd target into its base (the Forge variable without
+// "$", "home" for /home/<user>/<site> or "other") and the path below the site
+// directory. Relative targets have no base.
+func splitTarget(target string) (base, sub string) {
+	for _, macro := range []string{"$FORGE_SITE_PATH", "${FORGE_SITE_PATH}", "$FORGE_RELEASE_DIRECTORY", "${FORGE_RELEASE_DIRECTORY}"} {
+		if rest, ok := strings.CutPrefix(target, macro); ok {
+			return strings.Trim(macro, "${}"), cleanSub(rest)
+		}
+	}
+	if home := siteHome.FindString(target); home != "" {
+		return "home", cleanSub(strings.TrimPrefix(target, home))
+	}
+	if strings.HasPrefix(target, "/") || strings.HasPrefix(target, "$") || strings.HasPrefix(target, "~") || target == "-" {
+		return "other", ""
+	}
+	return "", cleanSub(target)
+}
+
+func cleanSub(sub string) string {
+	sub = path.Clean(strings.TrimPrefix(sub, "/"))
+	if sub == "." || sub == "/" {
+		return ""
+	}
+	return sub
+}
@@ -0,0 +1,173 @@
+package lint
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// codes formats findings as "code@line" for comparison
+func codes(findings []Finding) []string {
+	var out []string
+	for _, f := range findings {
+		out = append(out, fmt.Sprintf("%s@%d", f.Code, f.Line))
+	}
+	return out
+}
+
+func TestScript(t *testing.T) {
+	tests := []struct {
+		name         string
+		script       []string
+		zeroDowntime bool
+		rootDir      string
+		want         []string
+	}{
+		{
+			name:   "clean",
+			script: []string{"set -e", "cd $FORGE_SITE_PATH", "$FORGE_COMPOSER install --no-dev", "$FORGE_PHP artisan migrate --force"},
+			want:   nil,
+		},
+		{
+			name:   "set -e in the shebang",
+			script: []string{"#!/bin/bash -e", "git pull"},
+			want:   nil,
+		},
+		{
+			name:   "set -o errexit",
+			script: []string{"set -o errexit", "git pull"},
+			want:   nil,
+		},
+		{
+			name:   "missing set -e",
+			script: []string{"git pull"},
+			want:   []string{"missing-set-e@0"},
+		},
+		{
+			name:   "set -e only in a comment",
+			script: []string{"# set -e", "git pull"},
+			want:   []string{"missing-set-e@0"},
+		},
+		{
+			name:   "hard-coded binaries",
+			script: []string{"set -e", "composer install", "php8.3 artisan optimize", "sudo -S /usr/bin/php artisan queue:restart"},
+			want:   []string{"hard-coded-composer@2", "hard-coded-php@3", "hard-coded-php@4"},
+		},
+		{
+			name:   "php in a word is not a command",
+			script: []string{"set -e", "echo deploying my-php-app", "rm -rf storage/php-cache"},
+			want:   nil,
+		},
+		{
+			name:   "migrate without force",
+			script: []string{"set -e", "$FORGE_PHP artisan migrate", "$FORGE_PHP artisan migrate:fresh", "$FORGE_PHP artisan migrate:status"},
+			want:   []string{"migrate-without-force@2", "migrate-without-force@3"},
+		},
+		{
+			name:   "hard-coded site path",
+			script: []string{"set -e", "cd /home/forge/app.example.com"},
+			want:   []string{"hard-coded-path@2"},
+		},
+		{
+			name:         "zero downtime",
+			script:       []string{"set -e", "$CREATE_RELEASE()", "cd $FORGE_RELEASE_DIRECTORY", "$ACTIVATE_RELEASE()"},
+			zeroDowntime: true,
+			want:         nil,
+		},
+		{
+			name:         "zero downtime building in the site path",
+			script:       []string{"set -e", "$CREATE_RELEASE()", "cd $FORGE_SITE_PATH", "$ACTIVATE_RELEASE()"},
+			zeroDowntime: true,
+			want:         []string{"cd-site-path@3"},
+		},
+		{
+			name:         "zero downtime without create",
+			script:       []string{"set -e", "$ACTIVATE_RELEASE()"},
+			zeroDowntime: true,
+			want:         []string{"missing-release-macro@0"},
+		},
+		{
+			name:         "zero downtime without activate",
+			script:       []string{"set -e", "$CREATE_RELEASE()"},
+			zeroDowntime: true,
+			want:         []string{"missing-release-macro@0"},
+		},
+		{
+			name:         "release macros in the wrong order",
+			script:       []string{"set -e", "$ACTIVATE_RELEASE()", "$CREATE_RELEASE()"},
+			zeroDowntime: true,
+			want:         []string{"release-macro-order@2"},
+		},
+		{
+			name:   "release macro without zero downtime",
+			script: []string{"set -e", "$CREATE_RELEASE()"},
+			want:   []string{"release-macro-without-zero-downtime@2"},
+		},
+		{
+			name:    "artisan in root_dir",
+			script:  []string{"set -e", "cd $FORGE_SITE_PATH/backend", "$FORGE_PHP artisan optimize"},
+			rootDir: "backend",
+			want:    nil,
+		},
+		{
+			name:    "artisan outside root_dir",
+			script:  []string{"set -e", "cd $FORGE_SITE_PATH", "$FORGE_PHP artisan optimize"},
+			rootDir: "backend",
+			want:    []string{"artisan-outside-root-dir@0"},
+		},
+		{
+			name:    "relative cd into root_dir",
+			script:  []string{"set -e", "cd backend && $FORGE_PHP artisan optimize"},
+			rootDir: "backend/",
+			want:    nil,
+		},
+		{
+			name:    "cd outside root_dir",
+			script:  []string{"set -e", "cd $FORGE_SITE_PATH/frontend", "cd $FORGE_SITE_PATH/backend", "$FORGE_PHP artisan optimize"},
+			rootDir: "backend",
+			want:    []string{"cd-outside-root-dir@2"},
+		},
+		{
+			name:   "crlf line endings",
+			script: []string{"set -e\r", "composer install\r"},
+			want:   []string{"hard-coded-composer@2"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rootDir := tt.rootDir
+			if rootDir == "" {
+				rootDir = "."
+			}
+			got := codes(Script(strings.Join(tt.script, "\n"), tt.zeroDowntime, rootDir))
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestErrors(t *testing.T) {
+	findings := Script("$FORGE_PHP artisan migrate", false, ".")
+	if got, want := codes(Errors(findings)), []string{"migrate-without-force@1"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+}
+
+func TestFindingString(t *testing.T) {
+	tests := []struct {
+		finding Finding
+		want    string
+	}{
+		{Finding{Line: 0, Message: "missing 'set -e'"}, "deployment_script: missing 'set -e'"},
+		{Finding{Line: 3, Message: "hard-coded php"}, "deployment_script line 3: hard-coded php"},
+	}
+
+	for _, tt := range tests {
+		if got := tt.finding.String(); got != tt.want {
+			t.Errorf("got %q, want %q", got, tt.want)
+		}
+	}
+}
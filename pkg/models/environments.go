@@ -0,0 +1,222 @@
+package models
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+)
+
+// SiteOverlay overrides settings of a base site for one environment
+type SiteOverlay struct {
+	Site         string    `yaml:"site"`
+	Name         string    `yaml:"name,omitempty"`
+	GithubBranch string    `yaml:"github_branch,omitempty"`
+	Environment  string    `yaml:"environment,omitempty"`
+	EnvFile      string    `yaml:"env_file,omitempty"`
+	Aliases      *[]string `yaml:"aliases,omitempty"`
+}
+
+// Environment is an overlay applied on top of the base deployment configuration
+type Environment struct {
+	Name         string        `yaml:"name"`
+	Branch       string        `yaml:"branch"`
+	Organization string        `yaml:"organization,omitempty"`
+	Server       string        `yaml:"server,omitempty"`
+	Sites        []SiteOverlay `yaml:"sites,omitempty"`
+}
+
+// ProjectConfig is a base deployment configuration with per-environment overlays
+type ProjectConfig struct {
+	DeploymentConfig `yaml:",inline"`
+	Environments     []Environment `yaml:"environments"`
+}
+
+var environmentName = regexp.MustCompile(`^[a-z0-9][a-z0-9_-]*$`)
+
+// Environment returns the environment with the given name, or nil
+func (p *ProjectConfig) Environment(name string) *Environment {
+	for i := range p.Environments {
+		if p.Environments[i].Name == name {
+			return &p.Environments[i]
+		}
+	}
+	return nil
+}
+
+// Resolve returns the deployment configuration of an environment, with its
+// overlay applied on top of a copy of the base configuration
+func (p *ProjectConfig) Resolve(name string) (*DeploymentConfig, error) {
+	env := p.Environment(name)
+	if env == nil {
+		return nil, fmt.Errorf("environment %q not found", name)
+	}
+
+	resolved := p.DeploymentConfig
+	resolved.Sites = make([]SiteConfig, len(p.Sites))
+	for i, site := range p.Sites {
+		resolved.Sites[i] = site.clone()
+	}
+
+	if env.Organization != "" {
+		resolved.Organization = env.Organization
+	}
+	if env.Server != "" {
+		resolved.Server = env.Server
+	}
+	if env.Branch != "" {
+		resolved.GithubBranch = env.Branch
+	}
+
+	for _, overlay := range env.Sites {
+		index := -1
+		for i, site := range p.Sites {
+			if site.Name == overlay.Site {
+				index = i
+				break
+			}
+		}
+		if index < 0 {
+			return nil, fmt.Errorf("environment %q: site %q is not defined in the base configuration", name, overlay.Site)
+		}
+
+		site := &resolved.Sites[index]
+		if overlay.Name != "" {
+			site.Name = overlay.Name
+		}
+		if overlay.GithubBranch != "" {
+			site.GithubBranch = overlay.GithubBranch
+		}
+		if overlay.Environment != "" || overlay.EnvFile != "" {
+			site.Environment = overlay.Environment
+			site.EnvFile = overlay.EnvFile
+		}
+		if overlay.Aliases != nil {
+			site.Aliases = append([]string(nil), (*overlay.Aliases)...)
+		}
+	}
+
+	return &resolved, nil
+}
+
+// Validate validates the environments, the base configuration and every
+// resolved configuration. Issues of the base configuration are reported once,
+// with the field paths of the base configuration, when at least one resolved
+// configuration still has them; an environment can fix them, e.g. by setting
+// the server. Issues an overlay introduces are reported under the environment.
+func (p *ProjectConfig) Validate() Issues {
+	var issues, introduced Issues
+
+	base := p.DeploymentConfig.Validate()
+	inherited := make([]bool, len(base))
+	if len(p.Environments) == 0 {
+		issues = append(issues, newIssue("environments", "required", "At least one environment must be configured"))
+		for i := range inherited {
+			inherited[i] = true
+		}
+	}
+
+	names := make(map[string]bool)
+	branches := make(map[string]string)
+	for i, env := range p.Environments {
+		field := fmt.Sprintf("environments[%d]", i)
+		switch {
+		case env.Name == "":
+			issues = append(issues, newIssue(field+".name", "required", "Environment %d: name is required", i+1))
+			continue
+		case !environmentName.MatchString(env.Name):
+			issues = append(issues, newIssue(field+".name", "invalid-format", "Environment %d (%s): name may only contain lowercase letters, digits, '-' and '_'", i+1, env.Name))
+		case names[env.Name]:
+			issues = append(issues, newIssue(field+".name", "duplicate-environment", "Environment %d (%s): name is used more than once", i+1, env.Name))
+		}
+		names[env.Name] = true
+
+		if env.Branch == "" {
+			issues = append(issues, newIssue(field+".branch", "required", "Environment %d (%s): branch is required", i+1, env.Name))
+		} else if other, ok := branches[env.Branch]; ok {
+			issues = append(issues, newIssue(field+".branch", "duplicate-branch", "Environment %d (%s): branch '%s' already deploys environment '%s'", i+1, env.Name, env.Branch, other))
+		} else {
+			branches[env.Branch] = env.Name
+		}
+
+		resolved, err := p.Resolve(env.Name)
+		if err != nil {
+			issues = append(issues, newIssue(field, "invalid-environment", "%s", err))
+			continue
+		}
+		matched := make([]bool, len(base))
+		for _, issue := range resolved.Validate() {
+			if j := matchIssue(base, matched, issue); j >= 0 {
+				inherited[j] = true
+				continue
+			}
+			issue.Field = p.OverlayField(i, issue.Field)
+			issue.Message = fmt.Sprintf("Environment %s: %s", env.Name, issue.Message)
+			introduced = append(introduced, issue)
+		}
+	}
+
+	for i, issue := range base {
+		if inherited[i] {
+			issues = append(issues, issue)
+		}
+	}
+	return append(issues, introduced...)
+}
+
+// matchIssue returns the index of the first issue of base that is not
+// matched yet and has the field and code of issue, or -1. Messages are not
+// compared since they name sites, which overlays may rename.
+func matchIssue(base Issues, matched []bool, issue Issue) int {
+	for i, b := range base {
+		if !matched[i] && b.Field == issue.Field && b.Code == issue.Code {
+			matched[i] = true
+			return i
+		}
+	}
+	return -1
+}
+
+var siteField = regexp.MustCompile(`^sites\[(\d+)\](.*)$`)
+
+// OverlayField returns the path of the setting of environment i that a field
+// of its resolved configuration comes from: the site overlay for fields of an
+// overlaid site, the environment's own setting for organization, server and
+// branch, and the environment itself otherwise
+func (p *ProjectConfig) OverlayField(i int, field string) string {
+	env := &p.Environments[i]
+	prefix := fmt.Sprintf("environments[%d]", i)
+
+	if m := siteField.FindStringSubmatch(field); m != nil {
+		index, _ := strconv.Atoi(m[1])
+		for j, overlay := range env.Sites {
+			if index < len(p.Sites) && overlay.Site == p.Sites[index].Name {
+				return fmt.Sprintf("%s.sites[%d]%s", prefix, j, m[2])
+			}
+		}
+		return prefix
+	}
+
+	switch field {
+	case "organization", "server":
+		return prefix + "." + field
+	case "github_branch":
+		return prefix + ".branch"
+	}
+	return prefix
+}
+
+// clone returns a copy of the site that shares no slices or maps with it
+func (s SiteConfig) clone() SiteConfig {
+	s.Processes = append([]Process(nil), s.Processes...)
+	s.ScheduledJobs = append([]ScheduledJob(nil), s.ScheduledJobs...)
+	s.Aliases = append([]string(nil), s.Aliases...)
+	s.SharedPaths = append([]SharedPath(nil), s.SharedPaths...)
+	if s.NginxTemplateVariables != nil {
+		variables := make(map[string]string, len(s.NginxTemplateVariables))
+		for k, v := range s.NginxTemplateVariables {
+			variables[k] = v
+		}
+		s.NginxTemplateVariables = variables
+	}
+	return s
+}
@@ -0,0 +1,130 @@
+package models
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestProjectConfigValidate(t *testing.T) {
+	base := func() DeploymentConfig {
+		return DeploymentConfig{
+			Organization:     "acme",
+			Server:           "web-1",
+			GithubRepository: "acme/app",
+			GithubBranch:     "main",
+			Sites: []SiteConfig{
+				{Name: "app", RootDir: "app"},
+				{Name: "admin", RootDir: "admin"},
+			},
+		}
+	}
+	environments := []Environment{
+		{Name: "staging", Branch: "develop"},
+		{Name: "production", Branch: "main"},
+	}
+
+	tests := []struct {
+		name    string
+		project func() ProjectConfig
+		want    []siteIssue
+	}{
+		{
+			name: "valid",
+			project: func() ProjectConfig {
+				return ProjectConfig{DeploymentConfig: base(), Environments: environments}
+			},
+		},
+		{
+			name: "base issue reported once",
+			project: func() ProjectConfig {
+				cfg := base()
+				cfg.Sites[0].PHPVersion = "8.4"
+				return ProjectConfig{DeploymentConfig: cfg, Environments: environments}
+			},
+			want: []siteIssue{{"sites[0].php_version", "invalid-format", SeverityError}},
+		},
+		{
+			name: "base issue fixed by every environment",
+			project: func() ProjectConfig {
+				cfg := base()
+				cfg.Server = ""
+				return ProjectConfig{DeploymentConfig: cfg, Environments: []Environment{
+					{Name: "staging", Branch: "develop", Server: "staging-1"},
+					{Name: "production", Branch: "main", Server: "web-1"},
+				}}
+			},
+		},
+		{
+			name: "base issue fixed by one environment",
+			project: func() ProjectConfig {
+				cfg := base()
+				cfg.Server = ""
+				return ProjectConfig{DeploymentConfig: cfg, Environments: []Environment{
+					{Name: "staging", Branch: "develop", Server: "staging-1"},
+					{Name: "production", Branch: "main"},
+				}}
+			},
+			want: []siteIssue{{"server", "required", SeverityError}},
+		},
+		{
+			name: "issue introduced by a site overlay",
+			project: func() ProjectConfig {
+				aliases := []string{"ok.example.com", "bad_alias"}
+				return ProjectConfig{DeploymentConfig: base(), Environments: []Environment{
+					{Name: "staging", Branch: "develop"},
+					{Name: "production", Branch: "main", Sites: []SiteOverlay{{Site: "admin", Aliases: &aliases}}},
+				}}
+			},
+			want: []siteIssue{{"environments[1].sites[0].aliases[1]", "invalid-hostname", SeverityError}},
+		},
+		{
+			name: "cross-site issue introduced by a renamed site",
+			project: func() ProjectConfig {
+				return ProjectConfig{DeploymentConfig: base(), Environments: []Environment{
+					{Name: "staging", Branch: "develop", Sites: []SiteOverlay{{Site: "admin", Name: "app"}}},
+					{Name: "production", Branch: "main"},
+				}}
+			},
+			want: []siteIssue{{"environments[0].sites[0].name", "duplicate-site-name", SeverityError}},
+		},
+		{
+			name: "environment issues",
+			project: func() ProjectConfig {
+				return ProjectConfig{DeploymentConfig: base(), Environments: []Environment{
+					{Name: "Staging", Branch: "develop"},
+					{Name: "production", Branch: "develop"},
+				}}
+			},
+			want: []siteIssue{
+				{"environments[0].name", "invalid-format", SeverityError},
+				{"environments[1].branch", "duplicate-branch", SeverityError},
+			},
+		},
+		{
+			name: "no environments",
+			project: func() ProjectConfig {
+				cfg := base()
+				cfg.Sites[0].PHPVersion = "8.4"
+				return ProjectConfig{DeploymentConfig: cfg}
+			},
+			want: []siteIssue{
+				{"environments", "required", SeverityError},
+				{"sites[0].php_version", "invalid-format", SeverityError},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			project := tt.project()
+
+			var got []siteIssue
+			for _, issue := range project.Validate() {
+				got = append(got, siteIssue{issue.Field, issue.Code, issue.Severity})
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
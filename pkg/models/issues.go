@@ -0,0 +1,87 @@
+package models
+
+import "fmt"
+
+// Severity is how serious a validation issue is
+type Severity string
+
+const (
+	SeverityError   Severity = "error"
+	SeverityWarning Severity = "warning"
+	SeverityInfo    Severity = "info"
+)
+
+// Issue is a single problem found when validating a configuration
+type Issue struct {
+	// Field is the path of the field the issue is about, relative to the
+	// validated value, e.g. sites[2].php_version
+	Field string `json:"field,omitempty"`
+	// Code identifies the kind of issue, e.g. invalid-hostname
+	Code     string   `json:"code"`
+	Severity Severity `json:"severity"`
+	Message  string   `json:"message"`
+	// Fix suggests how to resolve the issue, if there is an obvious way
+	Fix string `json:"fix,omitempty"`
+}
+
+func (i Issue) String() string {
+	if i.Fix == "" {
+		return i.Message
+	}
+	return fmt.Sprintf("%s (fix: %s)", i.Message, i.Fix)
+}
+
+// Issues is the result of validating a configuration
+type Issues []Issue
+
+// Errors returns the issues with error severity
+func (is Issues) Errors() Issues {
+	var errors Issues
+	for _, issue := range is {
+		if issue.Severity == SeverityError {
+			errors = append(errors, issue)
+		}
+	}
+	return errors
+}
+
+// HasErrors reports whether any issue has error severity
+func (is Issues) HasErrors() bool {
+	return len(is.Errors()) > 0
+}
+
+// within nests issues found in a part of a configuration: field is prefixed
+// to their field paths and context to their messages
+func (is Issues) within(field, context string) Issues {
+	nested := make(Issues, len(is))
+	for i, issue := range is {
+		switch {
+		case issue.Field == "":
+			issue.Field = field
+		case issue.Field[0] == '[':
+			issue.Field = field + issue.Field
+		default:
+			issue.Field = field + "." + issue.Field
+		}
+		issue.Message = context + ": " + issue.Message
+		nested[i] = issue
+	}
+	return nested
+}
+
+// newIssue builds an issue with error severity
+func newIssue(field, code, format string, args ...interface{}) Issue {
+	return Issue{Field: field, Code: code, Severity: SeverityError, Message: fmt.Sprintf(format, args...)}
+}
+
+// withFix returns the issue with a fix suggestion
+func (i Issue) withFix(format string, args ...interface{}) Issue {
+	i.Fix = fmt.Sprintf(format, args...)
+	return i
+}
+
+// withSeverity returns the issue with another severity
+func (i Issue) withSeverity(severity Severity) Issue {
+	i.Severity = severity
+	return i
+}
@@ -2,7 +2,14 @@ package models
 
 import (
 	"fmt"
+	"regexp"
 	"strings"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/the-trybe/forge-deploy-cli/pkg/cron"
+	"github.com/the-trybe/forge-deploy-cli/pkg/hostname"
+	"github.com/the-trybe/forge-deploy-cli/pkg/lint"
 )
 
 // SharedPath represents a shared path for zero-downtime deployments
@@ -22,10 +29,149 @@ func (sp SharedPath) MarshalYAML() (interface{}, error) {
 	}, nil
 }
 
-// Process represents a background process
+// UnmarshalYAML implements custom YAML unmarshaling for both the string and {from, to} forms
+func (sp *SharedPath) UnmarshalYAML(value *yaml.Node) error {
+	switch value.Kind {
+	case yaml.ScalarNode:
+		sp.From = value.Value
+		sp.To = ""
+		return nil
+	case yaml.MappingNode:
+		*sp = SharedPath{}
+		for i := 0; i+1 < len(value.Content); i += 2 {
+			key, field := value.Content[i], value.Content[i+1]
+			var target *string
+			switch key.Value {
+			case "from":
+				target = &sp.From
+			case "to":
+				target = &sp.To
+			default:
+				return &NodeError{Node: key, Message: fmt.Sprintf("unknown field %q in shared path (expected 'from' and 'to')", key.Value)}
+			}
+			if field.Kind != yaml.ScalarNode {
+				return &NodeError{Node: field, Message: fmt.Sprintf("shared path '%s' must be a string", key.Value)}
+			}
+			*target = field.Value
+		}
+		if sp.From == "" {
+			return &NodeError{Node: value, Message: "shared path is missing 'from'"}
+		}
+		return nil
+	}
+	return fmt.Errorf("shared path must be a string or a mapping with 'from' and 'to'")
+}
+
+// NodeError is an error about a specific node of a YAML document, so that
+// it can be reported at that node's position
+type NodeError struct {
+	Node    *yaml.Node
+	Message string
+}
+
+func (e *NodeError) Error() string {
+	return fmt.Sprintf("line %d: %s", e.Node.Line, e.Message)
+}
+
+// Process represents a background process. Zero values of the daemon
+// options leave them to Forge's defaults.
 type Process struct {
 	Name    string `yaml:"name"`
 	Command string `yaml:"command"`
+	// Directory is the absolute working directory of the process
+	Directory string `yaml:"directory,omitempty"`
+	// User runs the process, by default the site's user
+	User string `yaml:"user,omitempty"`
+	// Processes is the number of copies to run
+	Processes int `yaml:"processes,omitempty"`
+	// StartSecs is how long the process must stay up to count as started
+	StartSecs int `yaml:"startsecs,omitempty"`
+	// StopWaitSecs is how long the process may take to stop before it is killed
+	StopWaitSecs int `yaml:"stopwaitsecs,omitempty"`
+}
+
+var unixUser = regexp.MustCompile(`^[a-z_][a-z0-9_-]{0,31}$`)
+
+// Validate validates the daemon options of the process
+func (p *Process) Validate() Issues {
+	var issues Issues
+
+	if p.Directory != "" && !strings.HasPrefix(p.Directory, "/") {
+		issues = append(issues, newIssue("directory", "absolute-path-required", "directory must be an absolute path"))
+	}
+
+	if p.User != "" && !unixUser.MatchString(p.User) {
+		issues = append(issues, newIssue("user", "invalid-user", "user '%s' is not a valid user name", p.User))
+	}
+
+	if p.Processes < 0 {
+		issues = append(issues, newIssue("processes", "negative-number", "processes must not be negative; leave it out to run one process"))
+	}
+
+	if p.StartSecs < 0 {
+		issues = append(issues, newIssue("startsecs", "negative-number", "startsecs must not be negative"))
+	}
+
+	if p.StopWaitSecs < 0 {
+		issues = append(issues, newIssue("stopwaitsecs", "negative-number", "stopwaitsecs must not be negative"))
+	}
+
+	return issues
+}
+
+// ScheduledJob represents a Forge scheduled job (cron job) of a site
+type ScheduledJob struct {
+	Command string `yaml:"command"`
+	// User runs the job, by default the site's user
+	User string `yaml:"user,omitempty"`
+	// Frequency is one of cron.FrequencyNames. It may be left out when Cron is set.
+	Frequency string `yaml:"frequency,omitempty"`
+	// Cron is the five-field cron expression of custom jobs
+	Cron string `yaml:"cron,omitempty"`
+}
+
+// Expression returns the job's cron expression, or an empty string for jobs
+// that run on reboot
+func (j *ScheduledJob) Expression() string {
+	return cron.Expression(j.Frequency, j.Cron)
+}
+
+// Schedule describes when the job runs, e.g. "nightly" or "*/5 * * * *"
+func (j *ScheduledJob) Schedule() string {
+	if j.Frequency == "" || j.Frequency == "custom" {
+		return j.Cron
+	}
+	return j.Frequency
+}
+
+// Validate validates the command, user and schedule of the job
+func (j *ScheduledJob) Validate() Issues {
+	var issues Issues
+
+	if strings.TrimSpace(j.Command) == "" {
+		issues = append(issues, newIssue("command", "required", "command is required"))
+	}
+
+	if j.User != "" && !unixUser.MatchString(j.User) {
+		issues = append(issues, newIssue("user", "invalid-user", "user '%s' is not a valid user name", j.User))
+	}
+
+	_, known := cron.Frequencies[j.Frequency]
+	switch {
+	case j.Frequency != "" && !known:
+		issues = append(issues, newIssue("frequency", "invalid-value", "frequency must be one of: %s", strings.Join(cron.FrequencyNames, ", ")))
+	case (j.Frequency == "" || j.Frequency == "custom") && j.Cron == "":
+		issues = append(issues, newIssue("cron", "required", "cron is required unless frequency is set"))
+	case j.Frequency != "" && j.Frequency != "custom" && j.Cron != "":
+		issues = append(issues, newIssue("cron", "cron-without-custom-frequency", "cron is only used with frequency 'custom', not '%s'", j.Frequency).
+			withFix("set frequency: custom, or remove cron"))
+	case j.Cron != "":
+		if _, err := cron.Parse(j.Cron); err != nil {
+			issues = append(issues, newIssue("cron", "invalid-cron", "%s", err))
+		}
+	}
+
+	return issues
 }
 
 // SiteConfig represents configuration for a single site
@@ -42,6 +188,7 @@ type SiteConfig struct {
 	DeploymentScript            string            `yaml:"deployment_script,omitempty"`
 	Processes                   []Process         `yaml:"processes,omitempty"`
 	LaravelScheduler            bool              `yaml:"laravel_scheduler,omitempty"`
+	ScheduledJobs               []ScheduledJob    `yaml:"scheduled_jobs,omitempty"`
 	Environment                 string            `yaml:"environment,omitempty"`
 	EnvFile                     string            `yaml:"env_file,omitempty"`
 	Aliases                     []string          `yaml:"aliases,omitempty"`
@@ -57,34 +204,97 @@ type SiteConfig struct {
 }
 
 // Validate validates the site configuration
-func (s *SiteConfig) Validate() []string {
-	var errors []string
+func (s *SiteConfig) Validate() Issues {
+	var issues Issues
 
 	if s.Name == "" {
-		errors = append(errors, "Site name is required")
+		issues = append(issues, newIssue("name", "required", "Site name is required"))
 	}
 
 	if s.DomainMode != "" && s.DomainMode != "on-forge" && s.DomainMode != "custom" {
-		errors = append(errors, "domain_mode must be 'on-forge' or 'custom'")
+		issues = append(issues, newIssue("domain_mode", "invalid-value", "domain_mode must be 'on-forge' or 'custom'"))
+	}
+
+	if s.Name != "" {
+		if s.DomainMode == "" || s.DomainMode == "on-forge" {
+			if err := hostname.CheckOnForge(s.Name); err != nil {
+				issue := newIssue("name", "invalid-hostname", "name %q is not a valid on-forge subdomain: %v", s.Name, err)
+				if strings.Contains(s.Name, ".") {
+					issue = issue.withFix("set domain_mode: custom for your own domain")
+				}
+				issues = append(issues, issue)
+			}
+		} else if err := hostname.Check(s.Name, false); err != nil {
+			issues = append(issues, newIssue("name", "invalid-hostname", "name %q is not a valid hostname: %v", s.Name, err))
+		}
 	}
 
 	if s.WWWRedirectType != "" && s.WWWRedirectType != "none" && s.WWWRedirectType != "from-www" && s.WWWRedirectType != "to-www" {
-		errors = append(errors, "www_redirect_type must be 'none', 'from-www', or 'to-www'")
+		issues = append(issues, newIssue("www_redirect_type", "invalid-value", "www_redirect_type must be 'none', 'from-www', or 'to-www'"))
+	} else if s.WWWRedirectType != "" && s.WWWRedirectType != "none" && (s.DomainMode == "" || s.DomainMode == "on-forge") {
+		issues = append(issues, newIssue("www_redirect_type", "www-redirect-on-forge", "www_redirect_type must be 'none' for on-forge domains, which have no www variant").
+			withFix("set www_redirect_type: none"))
+	}
+
+	for i, alias := range s.Aliases {
+		if err := hostname.Check(alias, true); err != nil {
+			issues = append(issues, newIssue(fmt.Sprintf("aliases[%d]", i), "invalid-hostname", "alias %d (%s) is not a valid hostname: %v", i+1, alias, err))
+		}
 	}
 
 	if s.ProjectType != "" && s.ProjectType != "laravel" && s.ProjectType != "other" {
-		errors = append(errors, "project_type must be 'laravel' or 'other'")
+		issues = append(issues, newIssue("project_type", "invalid-value", "project_type must be 'laravel' or 'other'"))
 	}
 
 	if s.Isolated && s.IsolatedUser == "" {
-		errors = append(errors, "isolated_user is required when isolated is true")
+		issues = append(issues, newIssue("isolated_user", "required", "isolated_user is required when isolated is true").
+			withFix("set isolated_user, or set isolated: false"))
 	}
 
 	if s.PHPVersion != "" && !strings.HasPrefix(s.PHPVersion, "php") {
-		errors = append(errors, "php_version must start with 'php' (e.g., 'php81', 'php84')")
+		issue := newIssue("php_version", "invalid-format", "php_version must start with 'php' (e.g., 'php81', 'php84')")
+		if version := strings.ReplaceAll(s.PHPVersion, ".", ""); strings.Trim(version, "0123456789") == "" {
+			issue = issue.withFix("set php_version: php%s", version)
+		}
+		issues = append(issues, issue)
+	}
+
+	for i, process := range s.Processes {
+		issues = append(issues, process.Validate().within(fmt.Sprintf("processes[%d]", i), fmt.Sprintf("process %d (%s)", i+1, process.Name))...)
+	}
+
+	for i, job := range s.ScheduledJobs {
+		issues = append(issues, job.Validate().within(fmt.Sprintf("scheduled_jobs[%d]", i), fmt.Sprintf("scheduled job %d", i+1))...)
 	}
 
-	return errors
+	if s.DeploymentScript != "" {
+		for _, finding := range lint.Errors(lint.Script(s.DeploymentScript, s.ZeroDowntimeDeployments, s.RootDir)) {
+			issues = append(issues, newIssue("deployment_script", finding.Code, "%s", finding))
+		}
+	}
+
+	return issues
+}
+
+// Domain returns the site's primary domain, including the on-forge suffix
+func (s *SiteConfig) Domain() string {
+	if s.DomainMode == "" || s.DomainMode == "on-forge" {
+		return s.Name + ".on-forge.com"
+	}
+	if s.WWWRedirectType == "to-www" && !strings.HasPrefix(s.Name, "www.") {
+		return "www." + s.Name
+	}
+	return s.Name
+}
+
+// URL returns the site's public URL, suitable as its APP_URL. On-forge
+// domains always serve HTTPS; custom domains only with a certificate.
+func (s *SiteConfig) URL() string {
+	scheme := "http"
+	if s.Certificate || s.DomainMode == "" || s.DomainMode == "on-forge" {
+		scheme = "https"
+	}
+	return scheme + "://" + s.Domain()
 }
 
 // DeploymentConfig represents the complete deployment configuration
@@ -97,35 +307,70 @@ type DeploymentConfig struct {
 }
 
 // Validate validates the deployment configuration
-func (d *DeploymentConfig) Validate() []string {
-	var errors []string
+func (d *DeploymentConfig) Validate() Issues {
+	var issues Issues
 
 	if d.Organization == "" {
-		errors = append(errors, "Organization is required")
+		issues = append(issues, newIssue("organization", "required", "Organization is required"))
 	}
 
 	if d.Server == "" {
-		errors = append(errors, "Server is required")
+		issues = append(issues, newIssue("server", "required", "Server is required"))
 	}
 
 	if d.GithubRepository == "" {
-		errors = append(errors, "GitHub repository is required")
+		issues = append(issues, newIssue("github_repository", "required", "GitHub repository is required"))
 	} else if !strings.Contains(d.GithubRepository, "/") {
-		errors = append(errors, "GitHub repository must be in format 'owner/repo'")
+		issues = append(issues, newIssue("github_repository", "invalid-format", "GitHub repository must be in format 'owner/repo'"))
 	}
 
 	if len(d.Sites) == 0 {
-		errors = append(errors, "At least one site must be configured")
+		issues = append(issues, newIssue("sites", "required", "At least one site must be configured"))
 	}
 
 	for i, site := range d.Sites {
-		siteErrors := site.Validate()
-		for _, err := range siteErrors {
-			errors = append(errors, fmt.Sprintf("Site %d (%s): %s", i+1, site.Name, err))
-		}
+		issues = append(issues, site.Validate().within(fmt.Sprintf("sites[%d]", i), fmt.Sprintf("Site %d (%s)", i+1, site.Name))...)
 	}
 
-	return errors
+	issues = append(issues, d.validateSites()...)
+
+	return issues
+}
+
+// Hostname is a name a site answers for
+type Hostname struct {
+	Name string
+	// Source is where the name comes from: "name", "www_redirect_type" or
+	// "alias N" for the Nth alias
+	Source string
+	// Field is the path of the field that sets it, e.g. aliases[0]
+	Field string
+}
+
+// Hostnames returns the site's domain, its www variant when it redirects
+// between the two, and its aliases
+func (s *SiteConfig) Hostnames() []Hostname {
+	hosts := []Hostname{{Name: s.Domain(), Source: "name", Field: "name"}}
+	if www := s.WWWVariant(); www != "" {
+		hosts = append(hosts, Hostname{Name: www, Source: "www_redirect_type", Field: "www_redirect_type"})
+	}
+	for i, alias := range s.Aliases {
+		hosts = append(hosts, Hostname{Name: alias, Source: fmt.Sprintf("alias %d", i+1), Field: fmt.Sprintf("aliases[%d]", i)})
+	}
+	return hosts
+}
+
+// WWWVariant returns the other side of the site's www redirect, e.g.
+// www.example.com for example.com, or an empty string without a redirect
+func (s *SiteConfig) WWWVariant() string {
+	if s.WWWRedirectType != "from-www" && s.WWWRedirectType != "to-www" || s.DomainMode == "" || s.DomainMode == "on-forge" {
+		return ""
+	}
+	domain := s.Domain()
+	if rest, ok := strings.CutPrefix(domain, "www."); ok {
+		return rest
+	}
+	return "www." + domain
 }
 
 // SetDefaults sets default values for optional fields
@@ -149,3 +394,30 @@ func (s *SiteConfig) SetDefaults() {
 		s.CloneRepository = true
 	}
 }
+
+// ClearDefaults resets the fields that hold the value SetDefaults gives
+// them when original leaves them unset, so that a site edited from original
+// does not spell out defaults it never set
+func (s *SiteConfig) ClearDefaults(original *SiteConfig) {
+	var defaults SiteConfig
+	defaults.SetDefaults()
+
+	if original.DomainMode == "" && s.DomainMode == defaults.DomainMode {
+		s.DomainMode = ""
+	}
+	if original.WWWRedirectType == "" && s.WWWRedirectType == defaults.WWWRedirectType {
+		s.WWWRedirectType = ""
+	}
+	if original.RootDir == "" && s.RootDir == defaults.RootDir {
+		s.RootDir = ""
+	}
+	if original.WebDir == "" && s.WebDir == defaults.WebDir {
+		s.WebDir = ""
+	}
+	if original.ProjectType == "" && s.ProjectType == defaults.ProjectType {
+		s.ProjectType = ""
+	}
+	if !original.CloneRepository && s.CloneRepository == defaults.CloneRepository {
+		s.CloneRepository = false
+	}
+}
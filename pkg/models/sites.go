@@ -0,0 +1,177 @@
+package models
+
+import (
+	"fmt"
+	"path"
+	"strings"
+
+	"github.com/the-trybe/forge-deploy-cli/pkg/hostname"
+)
+
+// validateSites checks the sites against each other. Sites share a server, so
+// names, hostnames, isolated users and process names must not collide.
+func (d *DeploymentConfig) validateSites() Issues {
+	var issues Issues
+	add := func(i int, issue Issue) {
+		issues = append(issues, Issues{issue}.within(fmt.Sprintf("sites[%d]", i), fmt.Sprintf("Site %d (%s)", i+1, d.Sites[i].Name))...)
+	}
+
+	names := make(map[string]int)
+	duplicateName := make(map[int]bool)
+	for i, site := range d.Sites {
+		if site.Name == "" {
+			continue
+		}
+		key := strings.ToLower(site.Name)
+		if first, ok := names[key]; ok {
+			add(i, newIssue("name", "duplicate-site-name", "name %q is already used by site %d; site names must be unique", site.Name, first+1))
+			duplicateName[i] = true
+			continue
+		}
+		names[key] = i
+	}
+
+	// A certificate covers the site's aliases, so an alias that is another
+	// site's name breaks the certificate request
+	type pair struct{ a, b int }
+	reported := make(map[pair]bool)
+	for i, site := range d.Sites {
+		if !site.Certificate {
+			continue
+		}
+		for a, alias := range site.Aliases {
+			for j := range d.Sites {
+				if j != i && strings.EqualFold(alias, d.Sites[j].Domain()) {
+					add(i, newIssue(fmt.Sprintf("aliases[%d]", a), "certificate-alias-conflict",
+						"alias %d (%s) is the name of site %d (%s); the certificate requested for this site cannot cover it",
+						a+1, alias, j+1, d.Sites[j].Name).withFix("remove the alias"))
+					reported[pair{i, j}], reported[pair{j, i}] = true, true
+				}
+			}
+		}
+	}
+
+	// A hostname served by two sites of a server conflicts in nginx and in
+	// certificate requests
+	owners := make(map[string]int)
+	for i := range d.Sites {
+		site := &d.Sites[i]
+		for _, host := range site.Hostnames() {
+			key, err := hostname.ToASCII(host.Name)
+			if err != nil {
+				key = strings.ToLower(host.Name)
+			}
+			owner, used := owners[key]
+			if !used {
+				owners[key] = i
+				continue
+			}
+			if owner == i || reported[pair{i, owner}] || host.Source == "name" && duplicateName[i] {
+				continue
+			}
+			add(i, newIssue(host.Field, "duplicate-hostname", "%s (%s) is already used by site %d (%s)", host.Source, host.Name, owner+1, d.Sites[owner].Name))
+		}
+	}
+
+	// Isolated sites sharing a user share its PHP-FPM pool and CLI
+	users := make(map[string]int)
+	for i, site := range d.Sites {
+		if !site.Isolated || site.IsolatedUser == "" {
+			continue
+		}
+		first, ok := users[site.IsolatedUser]
+		if !ok {
+			users[site.IsolatedUser] = i
+			continue
+		}
+		other := d.Sites[first]
+		if site.PHPVersion != "" && other.PHPVersion != "" && site.PHPVersion != other.PHPVersion {
+			add(i, newIssue("isolated_user", "isolated-user-php-conflict",
+				"isolated_user %s is shared with site %d (%s), which uses %s instead of %s; use one PHP version per isolated user",
+				site.IsolatedUser, first+1, other.Name, other.PHPVersion, site.PHPVersion).withFix("set php_version: %s", other.PHPVersion))
+		}
+	}
+
+	// In a monorepo each site serves its own project; two sites of the same
+	// branch serving the same directory, or one inside the other, are usually
+	// a copy-paste of root_dir and web_dir, and expose one site's files through
+	// the other. Sites deploying the whole repository may share it.
+	for i := range d.Sites {
+		site := &d.Sites[i]
+		dir := site.servedDir()
+		for j := 0; j < i; j++ {
+			other := &d.Sites[j]
+			if orDefault(site.GithubBranch, d.GithubBranch) != orDefault(other.GithubBranch, d.GithubBranch) ||
+				site.deploysRepository() && other.deploysRepository() {
+				continue
+			}
+			var relation string
+			switch otherDir := other.servedDir(); {
+			case dir == otherDir:
+				relation = "the same directory"
+			case isWithin(otherDir, dir):
+				relation = "a parent of the directory"
+			case isWithin(dir, otherDir):
+				relation = "a directory inside the one"
+			default:
+				continue
+			}
+			add(i, newIssue("root_dir", "served-directory-conflict", "root_dir %s and web_dir %s serve %s, %s served by site %d (%s)",
+				orDefault(site.RootDir, "."), orDefault(site.WebDir, "public"), dir, relation, j+1, other.Name))
+			break
+		}
+	}
+
+	// A site branch that repeats the default branch has no effect
+	for i, site := range d.Sites {
+		if site.GithubBranch != "" && site.GithubBranch == d.GithubBranch {
+			add(i, newIssue("github_branch", "redundant-branch", "github_branch %s is the default branch", site.GithubBranch).
+				withSeverity(SeverityInfo).withFix("remove github_branch so the site follows github_branch at the top level"))
+		}
+	}
+
+	// Process names identify daemons in plans and logs across the server
+	processes := make(map[string]int)
+	for i, site := range d.Sites {
+		seen := make(map[string]bool)
+		for p, process := range site.Processes {
+			if process.Name == "" || seen[process.Name] {
+				continue
+			}
+			seen[process.Name] = true
+			if first, ok := processes[process.Name]; ok {
+				add(i, newIssue(fmt.Sprintf("processes[%d].name", p), "duplicate-process-name", "process %d (%s): name is already used by site %d (%s)",
+					p+1, process.Name, first+1, d.Sites[first].Name))
+				continue
+			}
+			processes[process.Name] = i
+		}
+	}
+
+	return issues
+}
+
+// servedDir is the repository directory the site serves, relative to the
+// repository root
+func (s *SiteConfig) servedDir() string {
+	return path.Join(orDefault(s.RootDir, "."), orDefault(s.WebDir, "public"))
+}
+
+// deploysRepository reports whether the site is deployed from the
+// repository root
+func (s *SiteConfig) deploysRepository() bool {
+	return path.Clean(orDefault(s.RootDir, ".")) == "."
+}
+
+// isWithin reports whether dir is a subdirectory of parent. Both are clean
+// paths relative to the repository root.
+func isWithin(dir, parent string) bool {
+	return parent == "." || strings.HasPrefix(dir, parent+"/")
+}
+
+func orDefault(value, def string) string {
+	if value == "" {
+		return def
+	}
+	return value
+}
@@ -0,0 +1,173 @@
+package models
+
+import (
+	"reflect"
+	"testing"
+)
+
+// siteIssue is the part of an issue the cross-site tests compare
+type siteIssue struct {
+	Field    string
+	Code     string
+	Severity Severity
+}
+
+func TestValidateSites(t *testing.T) {
+	tests := []struct {
+		name   string
+		branch string
+		sites  []SiteConfig
+		want   []siteIssue
+	}{
+		{
+			name: "distinct sites",
+			sites: []SiteConfig{
+				{Name: "app", RootDir: "app"},
+				{Name: "admin", RootDir: "admin", Aliases: []string{"admin.example.com"}},
+			},
+		},
+		{
+			name:  "duplicate site name",
+			sites: []SiteConfig{{Name: "app"}, {Name: "App"}},
+			want:  []siteIssue{{"sites[1].name", "duplicate-site-name", SeverityError}},
+		},
+		{
+			name: "alias is another site's domain",
+			sites: []SiteConfig{
+				{Name: "example.com", DomainMode: "custom", RootDir: "a"},
+				{Name: "shop.example.com", DomainMode: "custom", RootDir: "b", Aliases: []string{"example.com"}},
+			},
+			want: []siteIssue{{"sites[1].aliases[0]", "duplicate-hostname", SeverityError}},
+		},
+		{
+			name: "alias is the www variant of another site",
+			sites: []SiteConfig{
+				{Name: "example.com", DomainMode: "custom", WWWRedirectType: "from-www", RootDir: "a"},
+				{Name: "shop.example.com", DomainMode: "custom", RootDir: "b", Aliases: []string{"www.example.com"}},
+			},
+			want: []siteIssue{{"sites[1].aliases[0]", "duplicate-hostname", SeverityError}},
+		},
+		{
+			name: "certificate alias conflict",
+			sites: []SiteConfig{
+				{Name: "example.com", DomainMode: "custom", RootDir: "a"},
+				{Name: "shop.example.com", DomainMode: "custom", RootDir: "b", Certificate: true, Aliases: []string{"Example.com"}},
+			},
+			want: []siteIssue{{"sites[1].aliases[0]", "certificate-alias-conflict", SeverityError}},
+		},
+		{
+			name: "IDN alias matching another site's punycode domain",
+			sites: []SiteConfig{
+				{Name: "xn--bcher-kva.example", DomainMode: "custom", RootDir: "a"},
+				{Name: "shop.example", DomainMode: "custom", RootDir: "b", Aliases: []string{"bücher.example"}},
+			},
+			want: []siteIssue{{"sites[1].aliases[0]", "duplicate-hostname", SeverityError}},
+		},
+		{
+			name: "isolated user with two PHP versions",
+			sites: []SiteConfig{
+				{Name: "app", RootDir: "a", Isolated: true, IsolatedUser: "acme", PHPVersion: "php84"},
+				{Name: "admin", RootDir: "b", Isolated: true, IsolatedUser: "acme", PHPVersion: "php83"},
+			},
+			want: []siteIssue{{"sites[1].isolated_user", "isolated-user-php-conflict", SeverityError}},
+		},
+		{
+			name: "isolated user with one PHP version",
+			sites: []SiteConfig{
+				{Name: "app", RootDir: "a", Isolated: true, IsolatedUser: "acme", PHPVersion: "php84"},
+				{Name: "admin", RootDir: "b", Isolated: true, IsolatedUser: "acme", PHPVersion: "php84"},
+			},
+		},
+		{
+			name: "same served directory",
+			sites: []SiteConfig{
+				{Name: "app", RootDir: "app"},
+				{Name: "admin", RootDir: "app/"},
+			},
+			want: []siteIssue{{"sites[1].root_dir", "served-directory-conflict", SeverityError}},
+		},
+		{
+			name: "served directory inside another",
+			sites: []SiteConfig{
+				{Name: "app", RootDir: "app", WebDir: "."},
+				{Name: "admin", RootDir: "app", WebDir: "admin/public"},
+			},
+			want: []siteIssue{{"sites[1].root_dir", "served-directory-conflict", SeverityError}},
+		},
+		{
+			name: "served directories of other branches",
+			sites: []SiteConfig{
+				{Name: "app", RootDir: "app"},
+				{Name: "staging", RootDir: "app", GithubBranch: "develop"},
+			},
+		},
+		{
+			name: "sites deploying the whole repository",
+			sites: []SiteConfig{
+				{Name: "app"},
+				{Name: "admin", RootDir: "."},
+			},
+		},
+		{
+			name:   "redundant branch",
+			branch: "main",
+			sites:  []SiteConfig{{Name: "app", GithubBranch: "main"}},
+			want:   []siteIssue{{"sites[0].github_branch", "redundant-branch", SeverityInfo}},
+		},
+		{
+			name: "duplicate process name across sites",
+			sites: []SiteConfig{
+				{Name: "app", RootDir: "a", Processes: []Process{{Name: "queue", Command: "php artisan queue:work"}}},
+				{Name: "admin", RootDir: "b", Processes: []Process{{Name: "horizon", Command: "php artisan horizon"}, {Name: "queue", Command: "php artisan queue:work"}}},
+			},
+			want: []siteIssue{{"sites[1].processes[1].name", "duplicate-process-name", SeverityError}},
+		},
+		{
+			// Reported by the checks of a single site
+			name: "duplicate process name within a site",
+			sites: []SiteConfig{
+				{Name: "app", Processes: []Process{{Name: "queue", Command: "a"}, {Name: "queue", Command: "b"}}},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := &DeploymentConfig{GithubBranch: tt.branch, Sites: tt.sites}
+
+			var got []siteIssue
+			for _, issue := range cfg.validateSites() {
+				got = append(got, siteIssue{issue.Field, issue.Code, issue.Severity})
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestValidateReportsCrossSiteIssues(t *testing.T) {
+	cfg := &DeploymentConfig{
+		Organization:     "acme",
+		Server:           "web-1",
+		GithubRepository: "acme/app",
+		GithubBranch:     "main",
+		Sites:            []SiteConfig{{Name: "app", GithubBranch: "main"}, {Name: "app"}},
+	}
+
+	issues := cfg.Validate()
+	var codes []string
+	for _, issue := range issues {
+		codes = append(codes, issue.Code)
+	}
+	want := []string{"duplicate-site-name", "redundant-branch"}
+	if !reflect.DeepEqual(codes, want) {
+		t.Errorf("got %q, want %q", codes, want)
+	}
+	if !issues.HasErrors() {
+		t.Error("a duplicate site name must be an error")
+	}
+	if got := issues[1].Message; got != "Site 1 (app): github_branch main is the default branch" {
+		t.Errorf("got message %q", got)
+	}
+}
@@ -0,0 +1,217 @@
+package nginx
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Directive is a simple directive ("name args;") or a block directive
+// ("name args { ... }") of an nginx configuration
+type Directive struct {
+	Name  string
+	Args  []string
+	Line  int
+	Block []Directive
+	// HasBlock distinguishes an empty block from a simple directive
+	HasBlock bool
+}
+
+// SyntaxError is a problem that prevents a configuration from loading
+type SyntaxError struct {
+	Line    int
+	Message string
+}
+
+func (e *SyntaxError) Error() string {
+	return fmt.Sprintf("line %d: %s", e.Line, e.Message)
+}
+
+type token struct {
+	text string
+	line int
+	// quoted tokens are never braces or semicolons
+	quoted bool
+}
+
+// tokenize splits a configuration into words, quoted strings, braces and
+// semicolons, dropping comments
+func tokenize(content string) ([]token, error) {
+	var tokens []token
+	line := 1
+	var word strings.Builder
+	wordLine := 0
+
+	flush := func() {
+		if word.Len() > 0 {
+			tokens = append(tokens, token{text: word.String(), line: wordLine})
+			word.Reset()
+		}
+	}
+
+	for i := 0; i < len(content); i++ {
+		c := content[i]
+		switch {
+		case c == '\n':
+			flush()
+			line++
+		case c == ' ' || c == '\t' || c == '\r':
+			flush()
+		case c == '#' && word.Len() == 0:
+			for i < len(content) && content[i] != '\n' {
+				i++
+			}
+			i--
+		case c == '{' || c == '}' || c == ';':
+			// "${var}" is part of a word, not a block
+			if c == '{' && word.Len() > 0 && strings.HasSuffix(word.String(), "$") {
+				word.WriteByte(c)
+				continue
+			}
+			if c == '}' && strings.Contains(word.String(), "${") && !strings.HasSuffix(word.String(), "}") {
+				word.WriteByte(c)
+				continue
+			}
+			flush()
+			tokens = append(tokens, token{text: string(c), line: line})
+		case (c == '"' || c == '\'') && word.Len() == 0:
+			start := line
+			var s strings.Builder
+			i++
+			for ; i < len(content) && content[i] != c; i++ {
+				if content[i] == '\\' && i+1 < len(content) {
+					i++
+				}
+				if content[i] == '\n' {
+					line++
+				}
+				s.WriteByte(content[i])
+			}
+			if i >= len(content) {
+				return nil, &SyntaxError{Line: start, Message: fmt.Sprintf("unterminated %c-quoted string", c)}
+			}
+			tokens = append(tokens, token{text: s.String(), line: start, quoted: true})
+		default:
+			if word.Len() == 0 {
+				wordLine = line
+			}
+			if c == '\\' && i+1 < len(content) {
+				word.WriteByte(c)
+				i++
+				c = content[i]
+			}
+			word.WriteByte(c)
+		}
+	}
+	flush()
+	return tokens, nil
+}
+
+// Parse parses an nginx configuration into its top-level directives. It
+// checks the structure only: balanced braces and terminated directives.
+func Parse(content string) ([]Directive, error) {
+	tokens, err := tokenize(content)
+	if err != nil {
+		return nil, err
+	}
+
+	p := &parser{tokens: tokens}
+	directives, err := p.block(0)
+	if err != nil {
+		return nil, err
+	}
+	return directives, nil
+}
+
+type parser struct {
+	tokens []token
+	pos    int
+}
+
+// block parses directives until the closing brace of a block opened on
+// openLine, or until the end of the input at the top level (openLine 0)
+func (p *parser) block(openLine int) ([]Directive, error) {
+	var directives []Directive
+	for p.pos < len(p.tokens) {
+		t := p.tokens[p.pos]
+		p.pos++
+
+		if !t.quoted {
+			switch t.text {
+			case "}":
+				if openLine == 0 {
+					return nil, &SyntaxError{Line: t.line, Message: "unexpected \"}\""}
+				}
+				return directives, nil
+			case ";":
+				return nil, &SyntaxError{Line: t.line, Message: "unexpected \";\""}
+			case "{":
+				return nil, &SyntaxError{Line: t.line, Message: "unexpected \"{\" without a directive name"}
+			}
+		}
+
+		d := Directive{Name: t.text, Line: t.line}
+		for {
+			if p.pos >= len(p.tokens) {
+				return nil, &SyntaxError{Line: d.Line, Message: fmt.Sprintf("directive %q is not terminated by \";\"", d.Name)}
+			}
+			next := p.tokens[p.pos]
+			p.pos++
+			if next.quoted {
+				d.Args = append(d.Args, next.text)
+				continue
+			}
+			switch next.text {
+			case ";":
+			case "{":
+				block, err := p.block(next.line)
+				if err != nil {
+					return nil, err
+				}
+				d.Block, d.HasBlock = block, true
+			case "}":
+				return nil, &SyntaxError{Line: d.Line, Message: fmt.Sprintf("directive %q is not terminated by \";\"", d.Name)}
+			default:
+				d.Args = append(d.Args, next.text)
+				continue
+			}
+			break
+		}
+		directives = append(directives, d)
+	}
+
+	if openLine != 0 {
+		return nil, &SyntaxError{Line: openLine, Message: "block opened here is never closed with \"}\""}
+	}
+	return directives, nil
+}
+
+// TopLevelDirectives are the directives allowed at the top level of a site's
+// configuration, which nginx includes in its http block
+var TopLevelDirectives = map[string]bool{
+	"server": true, "upstream": true, "map": true, "geo": true, "split_clients": true,
+	"include": true, "limit_req_zone": true, "limit_conn_zone": true, "log_format": true,
+	"proxy_cache_path": true, "fastcgi_cache_path": true, "fastcgi_cache_key": true,
+	"proxy_cache_key": true, "add_header": true, "access_log": true, "error_log": true,
+	"client_max_body_size": true, "gzip": true, "gzip_types": true, "resolver": true,
+	"ssl_protocols": true, "ssl_ciphers": true, "ssl_session_cache": true,
+	"server_names_hash_bucket_size": true, "map_hash_bucket_size": true,
+	"sendfile": true, "tcp_nopush": true, "keepalive_timeout": true, "charset": true,
+	"proxy_buffer_size": true, "proxy_buffers": true, "fastcgi_buffers": true,
+	"fastcgi_buffer_size": true, "limit_req_status": true, "real_ip_header": true,
+	"set_real_ip_from": true,
+}
+
+// Walk calls fn for every directive, depth first, with the names of the
+// blocks it is nested in
+func Walk(directives []Directive, fn func(d Directive, parents []string)) {
+	var walk func([]Directive, []string)
+	walk = func(directives []Directive, parents []string) {
+		for _, d := range directives {
+			fn(d, parents)
+			if d.HasBlock {
+				walk(d.Block, append(parents[:len(parents):len(parents)], d.Name))
+			}
+		}
+	}
+	walk(directives, nil)
+}
@@ -0,0 +1,84 @@
+package nginx
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	content := strings.Join([]string{
+		"# comment",
+		"upstream app { server 127.0.0.1:8000; }",
+		"server {",
+		"    server_name example.com 'www.example.com';",
+		`    add_header X-Frame-Options "SAMEORIGIN; always";`,
+		"    location ~ ^/(a|b)/${var} {",
+		"        return 301 https://$host$request_uri;",
+		"    }",
+		"    location /empty {}",
+		"}",
+	}, "\n")
+
+	directives, err := Parse(content)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var got []string
+	Walk(directives, func(d Directive, parents []string) {
+		got = append(got, strings.Join(append(parents, d.Name), ">")+" "+strings.Join(d.Args, "|"))
+	})
+	want := []string{
+		"upstream app",
+		"upstream>server 127.0.0.1:8000",
+		"server ",
+		"server>server_name example.com|www.example.com",
+		"server>add_header X-Frame-Options|SAMEORIGIN; always",
+		"server>location ~|^/(a|b)/${var}",
+		"server>location>return 301|https://$host$request_uri",
+		"server>location /empty",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got\n  %s\nwant\n  %s", strings.Join(got, "\n  "), strings.Join(want, "\n  "))
+	}
+
+	if server := directives[1]; server.Line != 3 || !server.HasBlock {
+		t.Errorf("got server on line %d with block %v, want line 3 with a block", server.Line, server.HasBlock)
+	}
+	if empty := directives[1].Block[3]; !empty.HasBlock || len(empty.Block) != 0 {
+		t.Errorf("got %+v, want an empty block", empty)
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		line    int
+		message string
+	}{
+		{"unclosed block", "server {\n    listen 80;\n", 1, `block opened here is never closed with "}"`},
+		{"unclosed nested block", "server {\n    location / {\n}\n", 1, `block opened here is never closed with "}"`},
+		{"unexpected closing brace", "server {\n}\n}\n", 3, `unexpected "}"`},
+		{"unterminated directive", "server {\n    listen 80\n}\n", 2, `directive "listen" is not terminated by ";"`},
+		{"unterminated at the end", "gzip on", 1, `directive "gzip" is not terminated by ";"`},
+		{"stray semicolon", "server {\n    ;\n}\n", 2, `unexpected ";"`},
+		{"block without a name", "{\n}\n", 1, `unexpected "{" without a directive name`},
+		{"unterminated double quote", "server {\n    add_header X \"value;\n}\n", 2, `unterminated "-quoted string`},
+		{"unterminated single quote", "server_name 'example.com;", 1, "unterminated '-quoted string"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Parse(tt.content)
+			syntax, ok := err.(*SyntaxError)
+			if !ok {
+				t.Fatalf("got %v, want a syntax error", err)
+			}
+			if syntax.Line != tt.line || syntax.Message != tt.message {
+				t.Errorf("got line %d: %s, want line %d: %s", syntax.Line, syntax.Message, tt.line, tt.message)
+			}
+		})
+	}
+}
@@ -0,0 +1,249 @@
+// Package nginx reads the local copies of Forge nginx templates, lists the
+// {{VARIABLES}} they expect and renders them for a site.
+package nginx
+
+import (
+	"errors"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/the-trybe/forge-deploy-cli/pkg/models"
+	"github.com/the-trybe/forge-deploy-cli/pkg/processes"
+)
+
+// DefaultTemplatesDir is where templates are looked up, relative to the repository root
+const DefaultTemplatesDir = ".forge/nginx-templates"
+
+// TemplateExt is the extension of template files; the template name is the
+// file name without it
+const TemplateExt = ".conf"
+
+// ForgeVariables are the variables Forge fills in itself. SITE_ID and the
+// server IP addresses are only known to Forge and are not rendered locally.
+var ForgeVariables = map[string]string{
+	"SITE":              "the site's domain",
+	"DOMAINS":           "the site's domain and aliases",
+	"USER":              "the user the site runs as",
+	"ROOT_PATH":         "the site directory, e.g. /home/forge/example.com",
+	"PATH":              "the web directory served, e.g. /home/forge/example.com/public",
+	"DIRECTORY":         "the web directory relative to the site, e.g. /public",
+	"PORT":              "the IPv4 listen port",
+	"PORT_V6":           "the IPv6 listen address",
+	"PROXY_PASS":        "the PHP-FPM socket of the site's PHP version",
+	"SITE_ID":           "the Forge site ID",
+	"SERVER_PUBLIC_IP":  "the server's public IP address",
+	"SERVER_PRIVATE_IP": "the server's private IP address",
+}
+
+var placeholder = regexp.MustCompile(`\{\{\s*([A-Za-z0-9_]+)\s*\}\}`)
+
+// Placeholder is a {{NAME}} reference in a template
+type Placeholder struct {
+	Name string
+	Line int
+}
+
+// Template is a local nginx template
+type Template struct {
+	Name    string
+	Path    string
+	Content string
+}
+
+// Placeholders returns the placeholders of the template in order of first use
+func (t *Template) Placeholders() []Placeholder {
+	seen := make(map[string]bool)
+	var placeholders []Placeholder
+	for i, line := range strings.Split(t.Content, "\n") {
+		for _, m := range placeholder.FindAllStringSubmatch(line, -1) {
+			if !seen[m[1]] {
+				seen[m[1]] = true
+				placeholders = append(placeholders, Placeholder{Name: m[1], Line: i + 1})
+			}
+		}
+	}
+	return placeholders
+}
+
+// Variables returns the placeholders that are not Forge variables and must be
+// set with nginx_template_variables
+func (t *Template) Variables() []Placeholder {
+	var variables []Placeholder
+	for _, p := range t.Placeholders() {
+		if _, ok := ForgeVariables[p.Name]; !ok {
+			variables = append(variables, p)
+		}
+	}
+	return variables
+}
+
+// Check compares nginx_template_variables with the template. Missing are the
+// template's variables that are not set; unknown are the variables the
+// template does not use.
+func (t *Template) Check(variables map[string]string) (missing []Placeholder, unknown []string) {
+	used := make(map[string]bool)
+	for _, p := range t.Variables() {
+		used[p.Name] = true
+		if _, ok := variables[p.Name]; !ok {
+			missing = append(missing, p)
+		}
+	}
+	for name := range variables {
+		if !used[name] {
+			unknown = append(unknown, name)
+		}
+	}
+	sort.Strings(unknown)
+	return missing, unknown
+}
+
+// LoadTemplates reads the templates of dir. A missing directory has no templates.
+func LoadTemplates(dir string) ([]Template, error) {
+	entries, err := os.ReadDir(dir)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var templates []Template
+	for _, entry := range entries {
+		if entry.IsDir() || !strings.HasSuffix(entry.Name(), TemplateExt) {
+			continue
+		}
+		p := filepath.Join(dir, entry.Name())
+		data, err := os.ReadFile(p)
+		if err != nil {
+			return nil, err
+		}
+		templates = append(templates, Template{
+			Name:    strings.TrimSuffix(entry.Name(), TemplateExt),
+			Path:    p,
+			Content: string(data),
+		})
+	}
+	return templates, nil
+}
+
+// Find returns the template with the given name
+func Find(templates []Template, name string) (*Template, bool) {
+	for i := range templates {
+		if templates[i].Name == name {
+			return &templates[i], true
+		}
+	}
+	return nil, false
+}
+
+// SiteVariables returns the values of the Forge variables for a site, as far
+// as they can be known without Forge
+func SiteVariables(site *models.SiteConfig) map[string]string {
+	user := "forge"
+	socket := processes.PHPBinary(site.PHPVersion) + "-fpm"
+	if site.Isolated && site.IsolatedUser != "" {
+		user = site.IsolatedUser
+		socket += "-" + user
+	}
+
+	root := path.Join("/home", user, site.Domain())
+	served := root
+	if site.ZeroDowntimeDeployments {
+		served = path.Join(served, "current")
+	}
+	directory := "/" + path.Join(site.RootDir, site.WebDir)
+	if directory == "/." {
+		directory = "/"
+	}
+
+	return map[string]string{
+		"SITE":       site.Domain(),
+		"DOMAINS":    strings.Join(append([]string{site.Domain()}, site.Aliases...), " "),
+		"USER":       user,
+		"ROOT_PATH":  root,
+		"PATH":       strings.TrimSuffix(path.Join(served, directory), "/"),
+		"DIRECTORY":  directory,
+		"PORT":       "80",
+		"PORT_V6":    "[::]:80",
+		"PROXY_PASS": "unix:/var/run/php/" + socket + ".sock",
+	}
+}
+
+// Render replaces the placeholders of the template for a site. It returns the
+// names of the placeholders left as they are because no value is known.
+func (t *Template) Render(site *models.SiteConfig) (string, []string) {
+	values := SiteVariables(site)
+	for name, value := range site.NginxTemplateVariables {
+		if _, forge := ForgeVariables[name]; !forge {
+			values[name] = value
+		}
+	}
+
+	var unresolved []string
+	seen := make(map[string]bool)
+	rendered := placeholder.ReplaceAllStringFunc(t.Content, func(ref string) string {
+		name := placeholder.FindStringSubmatch(ref)[1]
+		if value, ok := values[name]; ok {
+			return value
+		}
+		if !seen[name] {
+			seen[name] = true
+			unresolved = append(unresolved, name)
+		}
+		return ref
+	})
+	return rendered, unresolved
+}
+
+// Suggest returns the candidate closest to name when it looks like a typo of
+// it: one edit away, or two for names longer than five characters
+func Suggest(name string, candidates []string) string {
+	best, bestDistance := "", 2
+	if len(name) > 5 {
+		bestDistance = 3
+	}
+	for _, candidate := range candidates {
+		if candidate == name {
+			continue
+		}
+		if d := distance(strings.ToUpper(name), strings.ToUpper(candidate)); d < bestDistance {
+			best, bestDistance = candidate, d
+		}
+	}
+	return best
+}
+
+// ForgeVariableNames returns the names of the Forge variables, sorted
+func ForgeVariableNames() []string {
+	names := make([]string, 0, len(ForgeVariables))
+	for name := range ForgeVariables {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// distance is the Levenshtein distance between a and b
+func distance(a, b string) int {
+	prev := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		cur := make([]int, len(b)+1)
+		cur[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			cur[j] = min(prev[j]+1, cur[j-1]+1, prev[j-1]+cost)
+		}
+		prev = cur
+	}
+	return prev[len(b)]
+}
@@ -0,0 +1,145 @@
+package nginx
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/the-trybe/forge-deploy-cli/pkg/models"
+)
+
+const octane = `server {
+    listen {{PORT}};
+    server_name {{DOMAINS}};
+    root {{PATH}};
+
+    location / {
+        proxy_pass http://127.0.0.1:{{ OCTANE_PORT }};
+        proxy_read_timeout {{TIMEOUT}};
+    }
+    # {{OCTANE_PORT}} again
+}
+`
+
+func TestPlaceholdersAndVariables(t *testing.T) {
+	template := &Template{Name: "octane", Content: octane}
+
+	want := []Placeholder{{"PORT", 2}, {"DOMAINS", 3}, {"PATH", 4}, {"OCTANE_PORT", 7}, {"TIMEOUT", 8}}
+	if got := template.Placeholders(); !reflect.DeepEqual(got, want) {
+		t.Errorf("got placeholders %v, want %v", got, want)
+	}
+
+	want = []Placeholder{{"OCTANE_PORT", 7}, {"TIMEOUT", 8}}
+	if got := template.Variables(); !reflect.DeepEqual(got, want) {
+		t.Errorf("got variables %v, want %v", got, want)
+	}
+}
+
+func TestCheck(t *testing.T) {
+	template := &Template{Name: "octane", Content: octane}
+
+	tests := []struct {
+		name      string
+		variables map[string]string
+		missing   []string
+		unknown   []string
+	}{
+		{"all set", map[string]string{"OCTANE_PORT": "8000", "TIMEOUT": "60"}, nil, nil},
+		{"none set", nil, []string{"OCTANE_PORT", "TIMEOUT"}, nil},
+		{"unused and Forge variables", map[string]string{"OCTANE_PORT": "8000", "TIMEOUT": "60", "WORKERS": "4", "SITE": "x"}, nil, []string{"SITE", "WORKERS"}},
+		{"typo", map[string]string{"OCTANE_PROT": "8000", "TIMEOUT": "60"}, []string{"OCTANE_PORT"}, []string{"OCTANE_PROT"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			missing, unknown := template.Check(tt.variables)
+			var names []string
+			for _, p := range missing {
+				names = append(names, p.Name)
+			}
+			if !reflect.DeepEqual(names, tt.missing) {
+				t.Errorf("got missing %v, want %v", names, tt.missing)
+			}
+			if !reflect.DeepEqual(unknown, tt.unknown) {
+				t.Errorf("got unknown %v, want %v", unknown, tt.unknown)
+			}
+		})
+	}
+}
+
+func TestRender(t *testing.T) {
+	template := &Template{Name: "octane", Content: octane}
+	site := &models.SiteConfig{
+		Name:                   "app.example.com",
+		DomainMode:             "custom",
+		RootDir:                ".",
+		WebDir:                 "public",
+		PHPVersion:             "php84",
+		Aliases:                []string{"www.example.com"},
+		NginxTemplateVariables: map[string]string{"OCTANE_PORT": "8000", "PATH": "/ignored"},
+	}
+
+	rendered, unresolved := template.Render(site)
+	for _, want := range []string{
+		"listen 80;",
+		"server_name app.example.com www.example.com;",
+		"root /home/forge/app.example.com/public;",
+		"proxy_pass http://127.0.0.1:8000;",
+		"proxy_read_timeout {{TIMEOUT}};",
+	} {
+		if !strings.Contains(rendered, want) {
+			t.Errorf("rendered template lacks %q:\n%s", want, rendered)
+		}
+	}
+	if !reflect.DeepEqual(unresolved, []string{"TIMEOUT"}) {
+		t.Errorf("got unresolved %v, want [TIMEOUT]", unresolved)
+	}
+}
+
+func TestLoadTemplates(t *testing.T) {
+	dir := t.TempDir()
+	for name, content := range map[string]string{"octane.conf": octane, "notes.md": "not a template"} {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	templates, err := LoadTemplates(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(templates) != 1 || templates[0].Name != "octane" {
+		t.Fatalf("got %+v, want only octane", templates)
+	}
+	if _, ok := Find(templates, "octane"); !ok {
+		t.Error("octane not found")
+	}
+	if _, ok := Find(templates, "default"); ok {
+		t.Error("found a template that does not exist")
+	}
+
+	if templates, err := LoadTemplates(filepath.Join(dir, "missing")); err != nil || templates != nil {
+		t.Errorf("missing directory: got %v, %v, want no templates", templates, err)
+	}
+}
+
+func TestSuggest(t *testing.T) {
+	tests := []struct {
+		name       string
+		candidates []string
+		want       string
+	}{
+		{"OCTANE_PROT", []string{"OCTANE_PORT", "TIMEOUT"}, "OCTANE_PORT"},
+		{"site", ForgeVariableNames(), "SITE"},
+		{"PROT", []string{"PORT"}, ""},
+		{"WORKERS", []string{"OCTANE_PORT", "TIMEOUT"}, ""},
+	}
+
+	for _, tt := range tests {
+		if got := Suggest(tt.name, tt.candidates); got != tt.want {
+			t.Errorf("Suggest(%s): got %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,443 @@
+// Package plan compares a deployment configuration with the live state of its Forge server.
+package plan
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/the-trybe/forge-deploy-cli/pkg/diff"
+	"github.com/the-trybe/forge-deploy-cli/pkg/dotenv"
+	"github.com/the-trybe/forge-deploy-cli/pkg/forge"
+	"github.com/the-trybe/forge-deploy-cli/pkg/hostname"
+	"github.com/the-trybe/forge-deploy-cli/pkg/importer"
+	"github.com/the-trybe/forge-deploy-cli/pkg/models"
+)
+
+// Action is what deploying a site would do to it
+type Action string
+
+const (
+	Create Action = "create"
+	Update Action = "update"
+	NoOp   Action = "no-op"
+)
+
+// Op marks a single change
+type Op string
+
+const (
+	Add    Op = "+"
+	Remove Op = "-"
+	Change Op = "~"
+)
+
+// FieldChange is a single difference between the configuration and the live site.
+// Sensitive changes never carry their values.
+type FieldChange struct {
+	Op        Op
+	Field     string
+	Old       string
+	New       string
+	Sensitive bool
+	Diff      string
+}
+
+// SitePlan lists the changes a deployment would make to one site
+type SitePlan struct {
+	Name    string
+	Action  Action
+	Changes []FieldChange
+}
+
+// Plan lists the changes a deployment would make to every configured site
+type Plan struct {
+	Organization string
+	Server       string
+	Sites        []SitePlan
+	Unmanaged    []string
+}
+
+// Counts returns the number of sites to create and to update
+func (p *Plan) Counts() (create, update int) {
+	for _, site := range p.Sites {
+		switch site.Action {
+		case Create:
+			create++
+		case Update:
+			update++
+		}
+	}
+	return create, update
+}
+
+// HasChanges reports whether deploying would change anything
+func (p *Plan) HasChanges() bool {
+	create, update := p.Counts()
+	return create+update > 0
+}
+
+// Build fetches the live state of cfg's server and compares it with cfg.
+// Relative env_file paths are resolved against baseDir.
+func Build(ctx context.Context, client *forge.Client, cfg *models.DeploymentConfig, baseDir string) (*Plan, error) {
+	desiredSites := make([]models.SiteConfig, len(cfg.Sites))
+	envs := make([]map[string]string, len(cfg.Sites))
+	var withEnvironment []string
+	for i, site := range cfg.Sites {
+		desired := site
+		desired.SetDefaults()
+
+		env, err := desiredEnvironment(desired, baseDir)
+		if err != nil {
+			return nil, fmt.Errorf("site %s: %w", site.Name, err)
+		}
+		if env != nil {
+			withEnvironment = append(withEnvironment, forgeName(desired))
+		}
+		desiredSites[i], envs[i] = desired, env
+	}
+
+	// Environments are secret, so only those that are compared are fetched
+	live, err := importer.Live(ctx, client, cfg.Organization, cfg.Server, withEnvironment)
+	if err != nil {
+		return nil, err
+	}
+
+	p := &Plan{Organization: cfg.Organization, Server: cfg.Server}
+	managed := make(map[string]bool)
+
+	for i, desired := range desiredSites {
+		env := envs[i]
+		managed[desired.DomainMode+"/"+desired.Name] = true
+
+		current := findSite(live.Config, desired)
+		if current == nil {
+			p.Sites = append(p.Sites, SitePlan{Name: desired.Name, Action: Create, Changes: createChanges(desired, cfg.GithubBranch, env)})
+			continue
+		}
+
+		changes := compareSite(desired, *current, branch(desired, cfg), branch(*current, live.Config), env)
+		action := NoOp
+		if len(changes) > 0 {
+			action = Update
+		}
+		p.Sites = append(p.Sites, SitePlan{Name: desired.Name, Action: action, Changes: changes})
+	}
+
+	for _, site := range live.Config.Sites {
+		if !managed[site.DomainMode+"/"+site.Name] {
+			p.Unmanaged = append(p.Unmanaged, site.Name)
+		}
+	}
+
+	return p, nil
+}
+
+// forgeName returns the name Forge knows a site by
+func forgeName(site models.SiteConfig) string {
+	if site.DomainMode == "on-forge" {
+		return site.Name + hostname.OnForgeSuffix
+	}
+	return site.Name
+}
+
+// findSite returns the live site with the same name and domain mode
+func findSite(live *models.DeploymentConfig, site models.SiteConfig) *models.SiteConfig {
+	for i := range live.Sites {
+		if live.Sites[i].Name == site.Name && live.Sites[i].DomainMode == site.DomainMode {
+			return &live.Sites[i]
+		}
+	}
+	return nil
+}
+
+// branch returns the branch a site deploys, falling back to the config's branch
+func branch(site models.SiteConfig, cfg *models.DeploymentConfig) string {
+	if site.GithubBranch != "" {
+		return site.GithubBranch
+	}
+	return cfg.GithubBranch
+}
+
+// desiredEnvironment returns the environment a deployment would write, or nil
+// when the site does not manage its environment
+func desiredEnvironment(site models.SiteConfig, baseDir string) (map[string]string, error) {
+	if site.EnvFile != "" {
+		path := site.EnvFile
+		if !filepath.IsAbs(path) {
+			path = filepath.Join(baseDir, path)
+		}
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read env_file: %w", err)
+		}
+		return envValues(string(data)), nil
+	}
+	if site.Environment != "" {
+		return envValues(site.Environment), nil
+	}
+	return nil, nil
+}
+
+// createChanges lists the settings of a site that does not exist yet
+func createChanges(site models.SiteConfig, defaultBranch string, env map[string]string) []FieldChange {
+	var changes []FieldChange
+	add := func(field, value string) {
+		if value != "" {
+			changes = append(changes, FieldChange{Op: Add, Field: field, New: value})
+		}
+	}
+
+	add("domain_mode", site.DomainMode)
+	if site.GithubBranch != "" {
+		add("github_branch", site.GithubBranch)
+	} else {
+		add("github_branch", defaultBranch)
+	}
+	add("php_version", site.PHPVersion)
+	add("project_type", site.ProjectType)
+	add("root_dir", site.RootDir)
+	add("web_dir", site.WebDir)
+	add("nginx_template", site.NginxTemplate)
+	if site.Isolated {
+		add("isolated_user", site.IsolatedUser)
+	}
+	for _, alias := range site.Aliases {
+		add("aliases", alias)
+	}
+	for _, process := range site.Processes {
+		add("processes."+process.Name, process.Command)
+	}
+	for _, job := range site.ScheduledJobs {
+		add("scheduled_jobs", describeJob(job))
+	}
+	for _, key := range sortedKeys(env) {
+		changes = append(changes, FieldChange{Op: Add, Field: "environment." + key, Sensitive: true})
+	}
+	return changes
+}
+
+// compareSite lists the differences between a configured site and its live state
+func compareSite(desired, current models.SiteConfig, desiredBranch, currentBranch string, env map[string]string) []FieldChange {
+	var changes []FieldChange
+	scalar := func(field, old, new string) {
+		if old != new {
+			changes = append(changes, FieldChange{Op: Change, Field: field, Old: old, New: new})
+		}
+	}
+	flag := func(field string, old, new bool) {
+		if old != new {
+			changes = append(changes, FieldChange{Op: Change, Field: field, Old: fmt.Sprint(old), New: fmt.Sprint(new)})
+		}
+	}
+
+	scalar("github_branch", currentBranch, desiredBranch)
+	if desired.PHPVersion != "" {
+		scalar("php_version", current.PHPVersion, desired.PHPVersion)
+	}
+	scalar("project_type", current.ProjectType, desired.ProjectType)
+	scalar("root_dir", current.RootDir, desired.RootDir)
+	scalar("web_dir", current.WebDir, desired.WebDir)
+	scalar("www_redirect_type", current.WWWRedirectType, desired.WWWRedirectType)
+	if desired.NginxTemplate != "" {
+		scalar("nginx_template", current.NginxTemplate, desired.NginxTemplate)
+	}
+	flag("isolated", current.Isolated, desired.Isolated)
+	if desired.Isolated && current.Isolated {
+		scalar("isolated_user", current.IsolatedUser, desired.IsolatedUser)
+	}
+	flag("zero_downtime_deployments", current.ZeroDowntimeDeployments, desired.ZeroDowntimeDeployments)
+	flag("laravel_scheduler", current.LaravelScheduler, desired.LaravelScheduler)
+	if desired.Certificate && !current.Certificate {
+		changes = append(changes, FieldChange{Op: Add, Field: "certificate", New: "true"})
+	}
+
+	changes = append(changes, compareLists("aliases", current.Aliases, desired.Aliases)...)
+	changes = append(changes, compareProcesses(current.Processes, desired.Processes)...)
+	changes = append(changes, compareScheduledJobs(current.ScheduledJobs, desired.ScheduledJobs)...)
+
+	if script := strings.TrimSpace(normalizeNewlines(desired.DeploymentScript)); script != "" {
+		old := strings.TrimSpace(normalizeNewlines(current.DeploymentScript))
+		if old != script {
+			changes = append(changes, FieldChange{Op: Change, Field: "deployment_script", Diff: unifiedDiff(old, script)})
+		}
+	}
+
+	if env != nil {
+		changes = append(changes, compareEnvironment(envValues(current.Environment), env)...)
+	}
+
+	return changes
+}
+
+// compareLists reports values added to or removed from a list
+func compareLists(field string, old, new []string) []FieldChange {
+	var changes []FieldChange
+	for _, value := range new {
+		if !contains(old, value) {
+			changes = append(changes, FieldChange{Op: Add, Field: field, New: value})
+		}
+	}
+	for _, value := range old {
+		if !contains(new, value) {
+			changes = append(changes, FieldChange{Op: Remove, Field: field, Old: value})
+		}
+	}
+	return changes
+}
+
+// compareProcesses matches processes by command, since live names are derived
+func compareProcesses(old, new []models.Process) []FieldChange {
+	var changes []FieldChange
+	hasCommand := func(processes []models.Process, command string) bool {
+		for _, process := range processes {
+			if process.Command == command {
+				return true
+			}
+		}
+		return false
+	}
+
+	for _, process := range new {
+		if !hasCommand(old, process.Command) {
+			changes = append(changes, FieldChange{Op: Add, Field: "processes." + process.Name, New: process.Command})
+			continue
+		}
+		for _, current := range old {
+			if current.Command == process.Command {
+				changes = append(changes, compareDaemonOptions(process.Name, current, process)...)
+				break
+			}
+		}
+	}
+	for _, process := range old {
+		if !hasCommand(new, process.Command) {
+			changes = append(changes, FieldChange{Op: Remove, Field: "processes." + process.Name, Old: process.Command})
+		}
+	}
+	return changes
+}
+
+// compareScheduledJobs matches scheduled jobs by command and compares when
+// and as whom they run. Frequencies equal to a cron expression are unchanged.
+func compareScheduledJobs(old, new []models.ScheduledJob) []FieldChange {
+	var changes []FieldChange
+	find := func(jobs []models.ScheduledJob, command string) *models.ScheduledJob {
+		for i := range jobs {
+			if jobs[i].Command == command {
+				return &jobs[i]
+			}
+		}
+		return nil
+	}
+
+	for _, job := range new {
+		current := find(old, job.Command)
+		switch {
+		case current == nil:
+			changes = append(changes, FieldChange{Op: Add, Field: "scheduled_jobs", New: describeJob(job)})
+		case current.Expression() != job.Expression() || current.User != job.User:
+			changes = append(changes, FieldChange{Op: Change, Field: "scheduled_jobs", Old: describeJob(*current), New: describeJob(job)})
+		}
+	}
+	for _, job := range old {
+		if find(new, job.Command) == nil {
+			changes = append(changes, FieldChange{Op: Remove, Field: "scheduled_jobs", Old: describeJob(job)})
+		}
+	}
+	return changes
+}
+
+// describeJob formats a scheduled job as "<schedule> [as <user>]: <command>"
+func describeJob(job models.ScheduledJob) string {
+	schedule := job.Schedule()
+	if job.User != "" {
+		schedule += " as " + job.User
+	}
+	return schedule + ": " + job.Command
+}
+
+// compareDaemonOptions lists the changed options of a process whose command is unchanged
+func compareDaemonOptions(name string, old, new models.Process) []FieldChange {
+	var changes []FieldChange
+	for _, option := range []struct {
+		field    string
+		old, new string
+	}{
+		{"directory", old.Directory, new.Directory},
+		{"user", old.User, new.User},
+		{"processes", itoa(old.Processes), itoa(new.Processes)},
+		{"startsecs", itoa(old.StartSecs), itoa(new.StartSecs)},
+		{"stopwaitsecs", itoa(old.StopWaitSecs), itoa(new.StopWaitSecs)},
+	} {
+		if option.old != option.new {
+			changes = append(changes, FieldChange{Op: Change, Field: "processes." + name + "." + option.field, Old: option.old, New: option.new})
+		}
+	}
+	return changes
+}
+
+// itoa formats a daemon option, leaving zero (the default) empty
+func itoa(n int) string {
+	if n == 0 {
+		return ""
+	}
+	return strconv.Itoa(n)
+}
+
+// compareEnvironment reports added, removed and changed keys without their values.
+// Values referencing secrets (${NAME}) are only known at deploy time and are not compared.
+func compareEnvironment(old, new map[string]string) []FieldChange {
+	var changes []FieldChange
+	for _, key := range sortedKeys(new) {
+		value, ok := old[key]
+		switch {
+		case !ok:
+			changes = append(changes, FieldChange{Op: Add, Field: "environment." + key, Sensitive: true})
+		case value != new[key] && !strings.Contains(new[key], "${"):
+			changes = append(changes, FieldChange{Op: Change, Field: "environment." + key, Sensitive: true})
+		}
+	}
+	for _, key := range sortedKeys(old) {
+		if _, ok := new[key]; !ok {
+			changes = append(changes, FieldChange{Op: Remove, Field: "environment." + key, Sensitive: true})
+		}
+	}
+	return changes
+}
+
+// envValues returns the raw values of an env file
+func envValues(content string) map[string]string {
+	return dotenv.Parse(content).Map()
+}
+
+// unifiedDiff returns the diff from the live deployment script to the configured one
+func unifiedDiff(old, new string) string {
+	return diff.Unified("forge", "forge-deploy.yml", old+"\n", new+"\n", 1)
+}
+
+func normalizeNewlines(s string) string {
+	return strings.ReplaceAll(s, "\r\n", "\n")
+}
+
+func sortedKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,200 @@
+package plan_test
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/the-trybe/forge-deploy-cli/pkg/forge"
+	"github.com/the-trybe/forge-deploy-cli/pkg/forge/forgetest"
+	"github.com/the-trybe/forge-deploy-cli/pkg/models"
+	"github.com/the-trybe/forge-deploy-cli/pkg/plan"
+)
+
+const token = "test-token"
+
+// fakeForge starts a fake Forge API with one server and records the paths it serves
+func fakeForge(t *testing.T) (*forgetest.Server, func() []string) {
+	t.Helper()
+
+	server := forgetest.NewServer(token, &forgetest.Data{
+		Organizations: []forge.Organization{{ID: "1", Name: "Acme", Slug: "acme"}},
+		Servers: map[string][]forge.Server{
+			"acme": {{ID: "10", Name: "web-1", PHPVersion: "php84"}},
+		},
+		Sites: map[forge.ID][]forge.Site{
+			"10": {
+				{ID: "100", Name: "app.example.com", PHPVersion: "php84", ProjectType: "laravel", RootDirectory: "/", WebDirectory: "/public", Branch: "main", WWWRedirectType: "none", Aliases: []string{"www.example.com"}},
+				{ID: "101", Name: "blog.example.com", PHPVersion: "php83", ProjectType: "laravel", RootDirectory: "/", WebDirectory: "/public", Branch: "main", WWWRedirectType: "none"},
+				{ID: "102", Name: "legacy.example.com", PHPVersion: "php74", Branch: "main"},
+			},
+		},
+		Daemons: map[forge.ID][]forge.Daemon{
+			"10": {{ID: "1000", Command: "php /home/forge/app.example.com/artisan queue:work", Directory: "/home/forge/app.example.com", User: "forge", Processes: 1, StartSecs: 1, StopWaitSecs: 10}},
+		},
+		Environments: map[forge.ID]string{
+			"100": "APP_ENV=production\nAPP_DEBUG=false\nOLD_KEY=1\n",
+			"101": "APP_ENV=production\n",
+			"102": "DB_PASSWORD=secret\n",
+		},
+	})
+	t.Cleanup(server.Close)
+
+	var mu sync.Mutex
+	var paths []string
+	handler := server.Config.Handler
+	server.Config.Handler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		paths = append(paths, r.URL.Path)
+		mu.Unlock()
+		handler.ServeHTTP(w, r)
+	})
+
+	return server, func() []string {
+		mu.Lock()
+		defer mu.Unlock()
+		return append([]string(nil), paths...)
+	}
+}
+
+func config() *models.DeploymentConfig {
+	return &models.DeploymentConfig{
+		Organization:     "acme",
+		Server:           "web-1",
+		GithubRepository: "acme/app",
+		GithubBranch:     "main",
+		Sites: []models.SiteConfig{
+			{
+				Name:       "app.example.com",
+				DomainMode: "custom",
+				PHPVersion: "php84",
+				Aliases:    []string{"www.example.com"},
+				Processes:  []models.Process{{Name: "queue", Command: "php /home/forge/app.example.com/artisan queue:work"}},
+			},
+			{
+				// No php_version: the live version is kept
+				Name:       "blog.example.com",
+				DomainMode: "custom",
+			},
+		},
+	}
+}
+
+func site(t *testing.T, p *plan.Plan, name string) plan.SitePlan {
+	t.Helper()
+	for _, s := range p.Sites {
+		if s.Name == name {
+			return s
+		}
+	}
+	t.Fatalf("site %s not in plan", name)
+	return plan.SitePlan{}
+}
+
+func fields(changes []plan.FieldChange) string {
+	var out []string
+	for _, change := range changes {
+		out = append(out, string(change.Op)+change.Field)
+	}
+	return strings.Join(out, " ")
+}
+
+func TestNoChanges(t *testing.T) {
+	server, _ := fakeForge(t)
+
+	p, err := plan.Build(context.Background(), server.Client(), config(), t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, s := range p.Sites {
+		if s.Action != plan.NoOp {
+			t.Errorf("site %s: got %s with changes %s, want no-op", s.Name, s.Action, fields(s.Changes))
+		}
+	}
+	if p.HasChanges() {
+		t.Error("plan has changes")
+	}
+	if got := strings.Join(p.Unmanaged, ","); got != "legacy.example.com" {
+		t.Errorf("got unmanaged %q, want legacy.example.com", got)
+	}
+}
+
+func TestDrift(t *testing.T) {
+	server, _ := fakeForge(t)
+
+	cfg := config()
+	app := &cfg.Sites[0]
+	app.PHPVersion = "php85"
+	app.Aliases = []string{"example.com"}
+	app.Processes = append(app.Processes, models.Process{Name: "horizon", Command: "php artisan horizon"})
+	app.Environment = "APP_ENV=production\nAPP_DEBUG=true\nNEW_KEY=${NEW_KEY}\n"
+	cfg.Sites = append(cfg.Sites, models.SiteConfig{Name: "shop.example.com", DomainMode: "custom", PHPVersion: "php84"})
+
+	p, err := plan.Build(context.Background(), server.Client(), cfg, t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got := site(t, p, "app.example.com")
+	if got.Action != plan.Update {
+		t.Fatalf("got %s, want update", got.Action)
+	}
+	want := "~php_version +aliases -aliases +processes.horizon ~environment.APP_DEBUG +environment.NEW_KEY -environment.OLD_KEY"
+	if fields(got.Changes) != want {
+		t.Errorf("got changes\n  %s\nwant\n  %s", fields(got.Changes), want)
+	}
+	for _, change := range got.Changes {
+		if change.Field == "php_version" && (change.Old != "php84" || change.New != "php85") {
+			t.Errorf("php_version: got %s -> %s", change.Old, change.New)
+		}
+		if strings.HasPrefix(change.Field, "environment.") && (!change.Sensitive || change.Old != "" || change.New != "") {
+			t.Errorf("%s: environment values must not be carried", change.Field)
+		}
+	}
+
+	if s := site(t, p, "shop.example.com"); s.Action != plan.Create {
+		t.Errorf("shop.example.com: got %s, want create", s.Action)
+	}
+	if s := site(t, p, "blog.example.com"); s.Action != plan.NoOp {
+		t.Errorf("blog.example.com: got %s with changes %s, want no-op", s.Action, fields(s.Changes))
+	}
+
+	create, update := p.Counts()
+	if create != 1 || update != 1 {
+		t.Errorf("got %d to create and %d to update, want 1 and 1", create, update)
+	}
+}
+
+func TestOnlyComparedEnvironmentsAreFetched(t *testing.T) {
+	server, paths := fakeForge(t)
+
+	cfg := config()
+	cfg.Sites[0].Environment = "APP_ENV=production\n"
+
+	if _, err := plan.Build(context.Background(), server.Client(), cfg, t.TempDir()); err != nil {
+		t.Fatal(err)
+	}
+
+	var fetched []string
+	for _, path := range paths() {
+		if strings.HasSuffix(path, "/environment") {
+			fetched = append(fetched, path)
+		}
+	}
+	if got, want := strings.Join(fetched, ","), "/orgs/acme/servers/10/sites/100/environment"; got != want {
+		t.Errorf("got environments fetched %q, want %q", got, want)
+	}
+}
+
+func TestUnknownServer(t *testing.T) {
+	server, _ := fakeForge(t)
+
+	cfg := config()
+	cfg.Server = "web-2"
+	if _, err := plan.Build(context.Background(), server.Client(), cfg, t.TempDir()); err == nil {
+		t.Error("got no error for an unknown server")
+	}
+}
@@ -0,0 +1,67 @@
+package plan
+
+import (
+	"fmt"
+	"io"
+	"strings"
+)
+
+// Render writes a plan in a Terraform-like format
+func Render(w io.Writer, p *Plan) {
+	fmt.Fprintf(w, "Plan for %s/%s:\n\n", p.Organization, p.Server)
+
+	for _, site := range p.Sites {
+		switch site.Action {
+		case Create:
+			fmt.Fprintf(w, "  + site %s (will be created)\n", site.Name)
+		case Update:
+			fmt.Fprintf(w, "  ~ site %s\n", site.Name)
+		default:
+			fmt.Fprintf(w, "    site %s (no changes)\n", site.Name)
+		}
+
+		for _, change := range site.Changes {
+			fmt.Fprintf(w, "      %s\n", formatChange(change))
+			if change.Diff != "" {
+				for _, line := range strings.Split(strings.TrimSuffix(change.Diff, "\n"), "\n") {
+					fmt.Fprintf(w, "          %s\n", line)
+				}
+			}
+		}
+		fmt.Fprintln(w)
+	}
+
+	if len(p.Unmanaged) > 0 {
+		fmt.Fprintf(w, "Sites on the server not in the configuration (left untouched): %s\n\n", strings.Join(p.Unmanaged, ", "))
+	}
+
+	create, update := p.Counts()
+	if create+update == 0 {
+		fmt.Fprintln(w, "No changes. The Forge sites match the configuration.")
+		return
+	}
+	fmt.Fprintf(w, "Plan: %d to create, %d to change.\n", create, update)
+}
+
+// formatChange formats a single field change, masking sensitive values
+func formatChange(c FieldChange) string {
+	if c.Sensitive {
+		switch c.Op {
+		case Change:
+			return fmt.Sprintf("~ %s: (sensitive value changed)", c.Field)
+		default:
+			return fmt.Sprintf("%s %s (sensitive)", c.Op, c.Field)
+		}
+	}
+
+	switch {
+	case c.Diff != "":
+		return fmt.Sprintf("~ %s:", c.Field)
+	case c.Op == Add:
+		return fmt.Sprintf("+ %s: %q", c.Field, c.New)
+	case c.Op == Remove:
+		return fmt.Sprintf("- %s: %q", c.Field, c.Old)
+	default:
+		return fmt.Sprintf("~ %s: %q -> %q", c.Field, c.Old, c.New)
+	}
+}
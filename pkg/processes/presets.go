@@ -0,0 +1,108 @@
+// Package processes provides presets for the background processes (Forge
+// daemons) that Laravel applications commonly run.
+package processes
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/the-trybe/forge-deploy-cli/pkg/models"
+)
+
+// Preset is a background process from the library
+type Preset struct {
+	Name        string
+	Description string
+	// Artisan is the artisan command and its arguments
+	Artisan string
+	// StopWaitSecs gives running work time to finish before the process is killed
+	StopWaitSecs int
+}
+
+// Presets is the library of background processes
+var Presets = []Preset{
+	{
+		Name:         "queue",
+		Description:  "Queue worker (queue:work)",
+		Artisan:      "queue:work --sleep=3 --tries=3 --max-time=3600",
+		StopWaitSecs: 3600,
+	},
+	{
+		Name:         "horizon",
+		Description:  "Laravel Horizon queue supervisor",
+		Artisan:      "horizon",
+		StopWaitSecs: 3600,
+	},
+	{
+		Name:        "reverb",
+		Description: "Laravel Reverb WebSocket server",
+		Artisan:     "reverb:start --no-interaction --port=8080",
+	},
+	{
+		Name:        "octane",
+		Description: "Laravel Octane application server",
+		Artisan:     "octane:start --no-interaction --port=8000",
+	},
+	{
+		Name:        "pulse",
+		Description: "Laravel Pulse server monitoring (pulse:check)",
+		Artisan:     "pulse:check",
+	},
+}
+
+// Names returns the names of the presets in library order
+func Names() []string {
+	names := make([]string, len(Presets))
+	for i, p := range Presets {
+		names[i] = p.Name
+	}
+	return names
+}
+
+// Find returns the preset with the given name
+func Find(name string) (*Preset, bool) {
+	for i := range Presets {
+		if Presets[i].Name == name {
+			return &Presets[i], true
+		}
+	}
+	return nil, false
+}
+
+// Command returns the preset's command for a site's php_version, e.g.
+// "php8.3 artisan horizon" for php83
+func (p *Preset) Command(phpVersion string) string {
+	return PHPBinary(phpVersion) + " artisan " + p.Artisan
+}
+
+// Process returns the preset as a process of a site with the given php_version
+func (p *Preset) Process(phpVersion string) models.Process {
+	return models.Process{
+		Name:         p.Name,
+		Command:      p.Command(phpVersion),
+		StopWaitSecs: p.StopWaitSecs,
+	}
+}
+
+var phpVersion = regexp.MustCompile(`^php(\d)(\d+)$`)
+
+// PHPBinary returns the PHP binary Forge installs for a php_version such as
+// "php83", or "php" when the version is unknown
+func PHPBinary(version string) string {
+	if m := phpVersion.FindStringSubmatch(version); m != nil {
+		return fmt.Sprintf("php%s.%s", m[1], m[2])
+	}
+	return "php"
+}
+
+var commandBinary = regexp.MustCompile(`^(?:\S*/)?(php[0-9.]*)\s+\S*artisan\b`)
+
+// Binary returns the PHP binary an artisan command runs with, if any
+func Binary(command string) (string, bool) {
+	m := commandBinary.FindStringSubmatch(strings.TrimSpace(command))
+	if m == nil {
+		return "", false
+	}
+	return m[1], true
+}
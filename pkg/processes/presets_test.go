@@ -0,0 +1,84 @@
+package processes
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/the-trybe/forge-deploy-cli/pkg/models"
+)
+
+func TestPHPBinary(t *testing.T) {
+	tests := []struct {
+		version string
+		want    string
+	}{
+		{"php83", "php8.3"},
+		{"php74", "php7.4"},
+		{"php810", "php8.10"},
+		{"8.3", "php"},
+		{"php", "php"},
+		{"php8", "php"},
+		{"", "php"},
+	}
+
+	for _, tt := range tests {
+		if got := PHPBinary(tt.version); got != tt.want {
+			t.Errorf("PHPBinary(%q): got %q, want %q", tt.version, got, tt.want)
+		}
+	}
+}
+
+func TestBinary(t *testing.T) {
+	tests := []struct {
+		command string
+		binary  string
+		ok      bool
+	}{
+		{"php8.3 artisan horizon", "php8.3", true},
+		{"php artisan queue:work", "php", true},
+		{"  /usr/bin/php8.2 artisan queue:work", "php8.2", true},
+		{"php8.3 /home/forge/app.example.com/artisan horizon", "php8.3", true},
+		{"php8.3 worker.php", "", false},
+		{"node server.js", "", false},
+		{"artisan horizon", "", false},
+		{"", "", false},
+	}
+
+	for _, tt := range tests {
+		binary, ok := Binary(tt.command)
+		if binary != tt.binary || ok != tt.ok {
+			t.Errorf("Binary(%q): got %q, %v, want %q, %v", tt.command, binary, ok, tt.binary, tt.ok)
+		}
+	}
+}
+
+func TestPresetProcess(t *testing.T) {
+	want := map[string]models.Process{
+		"queue":   {Name: "queue", Command: "php8.3 artisan queue:work --sleep=3 --tries=3 --max-time=3600", StopWaitSecs: 3600},
+		"horizon": {Name: "horizon", Command: "php8.3 artisan horizon", StopWaitSecs: 3600},
+		"reverb":  {Name: "reverb", Command: "php8.3 artisan reverb:start --no-interaction --port=8080"},
+		"octane":  {Name: "octane", Command: "php8.3 artisan octane:start --no-interaction --port=8000"},
+		"pulse":   {Name: "pulse", Command: "php8.3 artisan pulse:check"},
+	}
+
+	if got := Names(); len(got) != len(want) {
+		t.Fatalf("got presets %v, want %d", got, len(want))
+	}
+	for _, name := range Names() {
+		preset, ok := Find(name)
+		if !ok {
+			t.Fatalf("preset %s not found", name)
+		}
+		if got := preset.Process("php83"); !reflect.DeepEqual(got, want[name]) {
+			t.Errorf("%s: got %+v, want %+v", name, got, want[name])
+		}
+		// Without a known version the preset runs the default php
+		if binary, _ := Binary(preset.Command("")); binary != "php" {
+			t.Errorf("%s: got binary %q without a php_version, want php", name, binary)
+		}
+	}
+
+	if _, ok := Find("scheduler"); ok {
+		t.Error("found a preset that does not exist")
+	}
+}
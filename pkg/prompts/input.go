@@ -0,0 +1,75 @@
+package prompts
+
+import (
+	"fmt"
+
+	"github.com/AlecAivazis/survey/v2"
+)
+
+// NoInput disables interactive prompts. Questions without a preset answer fall
+// back to their default, and fail with a MissingAnswerError when there is none.
+var NoInput bool
+
+// MissingAnswerError is returned when a required answer is absent and prompting is disabled
+type MissingAnswerError struct {
+	Key string
+}
+
+func (e *MissingAnswerError) Error() string {
+	return fmt.Sprintf("missing answer for %q (input is disabled)", e.Key)
+}
+
+// askString returns the preset answer if there is one, and otherwise asks the prompt
+func askString(key string, preset *string, prompt survey.Prompt, opts ...survey.AskOpt) (string, error) {
+	if preset != nil {
+		return *preset, nil
+	}
+
+	if NoInput {
+		if def, ok := promptDefault(prompt); ok {
+			return def, nil
+		}
+		return "", &MissingAnswerError{Key: key}
+	}
+
+	var value string
+	if err := survey.AskOne(prompt, &value, opts...); err != nil {
+		return "", err
+	}
+	return value, nil
+}
+
+// askBool returns the preset answer if there is one, and otherwise asks the confirm prompt
+func askBool(preset *bool, prompt *survey.Confirm) (bool, error) {
+	if preset != nil {
+		return *preset, nil
+	}
+
+	if NoInput {
+		return prompt.Default, nil
+	}
+
+	var value bool
+	if err := survey.AskOne(prompt, &value); err != nil {
+		return false, err
+	}
+	return value, nil
+}
+
+// promptDefault returns the value a prompt would submit without any input
+func promptDefault(prompt survey.Prompt) (string, bool) {
+	switch p := prompt.(type) {
+	case *survey.Input:
+		return p.Default, p.Default != ""
+	case *survey.Multiline:
+		return p.Default, true
+	case *survey.Select:
+		if def, ok := p.Default.(string); ok && def != "" {
+			return def, true
+		}
+		if len(p.Options) > 0 {
+			return p.Options[0], true
+		}
+	}
+	return "", false
+}
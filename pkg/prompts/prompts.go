@@ -1,99 +1,190 @@
 package prompts
 
 import (
+	"context"
 	"fmt"
 	"os"
+	"path/filepath"
+	"strconv"
 	"strings"
+	"time"
 
 	"github.com/AlecAivazis/survey/v2"
+
+	"github.com/the-trybe/forge-deploy-cli/pkg/answers"
+	"github.com/the-trybe/forge-deploy-cli/pkg/cron"
+	"github.com/the-trybe/forge-deploy-cli/pkg/detect"
+	"github.com/the-trybe/forge-deploy-cli/pkg/dotenv"
+	"github.com/the-trybe/forge-deploy-cli/pkg/forge"
+	"github.com/the-trybe/forge-deploy-cli/pkg/hostname"
+	"github.com/the-trybe/forge-deploy-cli/pkg/lint"
 	"github.com/the-trybe/forge-deploy-cli/pkg/models"
+	"github.com/the-trybe/forge-deploy-cli/pkg/nginx"
+	presets "github.com/the-trybe/forge-deploy-cli/pkg/processes"
+	"github.com/the-trybe/forge-deploy-cli/pkg/scripts"
+	"github.com/the-trybe/forge-deploy-cli/pkg/secrets"
 )
 
-// PromptBaseConfig prompts for base deployment configuration
-func PromptBaseConfig() (*models.DeploymentConfig, error) {
+// PromptBaseConfig prompts for base deployment configuration.
+// Questions answered in preset are not asked; preset may be nil.
+// When client is not nil, organizations and servers are offered as lists.
+func PromptBaseConfig(preset *answers.Answers, client *forge.Client) (*models.DeploymentConfig, error) {
 	fmt.Println("\nLaravel Forge Deployment Configuration Generator")
 	fmt.Println()
 	fmt.Println("Base Configuration")
 	fmt.Println(strings.Repeat("-", 50))
 
+	if preset == nil {
+		preset = &answers.Answers{}
+	}
+
 	config := &models.DeploymentConfig{}
+	required := survey.WithValidator(survey.Required)
 
-	questions := []*survey.Question{
-		{
-			Name:     "organization",
-			Prompt:   &survey.Input{Message: "Forge organization name:"},
-			Validate: survey.Required,
-		},
-		{
-			Name:     "server",
-			Prompt:   &survey.Input{Message: "Forge server name:"},
-			Validate: survey.Required,
-		},
-		{
-			Name:     "repository",
-			Prompt:   &survey.Input{Message: "GitHub repository (owner/repo):"},
-			Validate: survey.Required,
-		},
-		{
-			Name:     "branch",
-			Prompt:   &survey.Input{Message: "Default branch:", Default: "main"},
-			Validate: survey.Required,
-		},
+	var err error
+	if config.Organization, err = askString("organization", preset.Organization,
+		organizationPrompt(client, preset.Organization), required); err != nil {
+		return nil, err
 	}
 
-	answers := struct {
-		Organization string
-		Server       string
-		Repository   string
-		Branch       string
-	}{}
+	if config.Server, err = askString("server", preset.Server,
+		serverPrompt(client, preset.Server, config.Organization), required); err != nil {
+		return nil, err
+	}
 
-	if err := survey.Ask(questions, &answers); err != nil {
+	if config.GithubRepository, err = askString("github_repository", preset.GithubRepository,
+		&survey.Input{Message: "GitHub repository (owner/repo):"}, required); err != nil {
 		return nil, err
 	}
 
-	config.Organization = answers.Organization
-	config.Server = answers.Server
-	config.GithubRepository = answers.Repository
-	config.GithubBranch = answers.Branch
+	if config.GithubBranch, err = askString("github_branch", preset.GithubBranch,
+		&survey.Input{Message: "Default branch:", Default: "main"}, required); err != nil {
+		return nil, err
+	}
 
 	return config, nil
 }
 
-// PromptSiteBasicInfo prompts for basic site information
-func PromptSiteBasicInfo(siteNumber int) (map[string]interface{}, error) {
-	fmt.Printf("\nSite %d Configuration\n", siteNumber)
-	fmt.Println(strings.Repeat("-", 50))
+// organizationPrompt returns a list of the account's organizations when they
+// can be fetched, and a free text input otherwise
+func organizationPrompt(client *forge.Client, preset *string) survey.Prompt {
+	input := &survey.Input{Message: "Forge organization name:"}
+	if client == nil || preset != nil || NoInput {
+		return input
+	}
 
-	var name, domainMode, wwwRedirect string
+	orgs, err := client.ListOrganizations(context.Background())
+	if err != nil {
+		fmt.Printf("  Warning: could not list Forge organizations: %v\n", err)
+		return input
+	}
+	if len(orgs) == 0 {
+		return input
+	}
 
-	questions := []*survey.Question{
-		{
-			Name: "domainMode",
-			Prompt: &survey.Select{
-				Message: "Domain mode:",
-				Options: []string{"on-forge", "custom"},
-				Default: "on-forge",
-			},
-		},
-		{
-			Name:     "name",
-			Prompt:   &survey.Input{Message: "Site name:"},
-			Validate: survey.Required,
-		},
+	options := make([]string, len(orgs))
+	names := make(map[string]string, len(orgs))
+	for i, org := range orgs {
+		options[i] = org.Slug
+		names[org.Slug] = org.Name
+	}
+
+	return &survey.Select{
+		Message:     "Forge organization:",
+		Options:     options,
+		Description: func(value string, index int) string { return names[value] },
+	}
+}
+
+// serverPrompt returns a list of the organization's servers when they can be
+// fetched, and a free text input otherwise
+func serverPrompt(client *forge.Client, preset *string, organization string) survey.Prompt {
+	input := &survey.Input{Message: "Forge server name:"}
+	if client == nil || preset != nil || NoInput {
+		return input
+	}
+
+	servers, err := client.ListServers(context.Background(), organization)
+	if err != nil {
+		fmt.Printf("  Warning: could not list Forge servers: %v\n", err)
+		return input
+	}
+	if len(servers) == 0 {
+		return input
+	}
+
+	options := make([]string, len(servers))
+	addresses := make(map[string]string, len(servers))
+	for i, server := range servers {
+		options[i] = server.Name
+		addresses[server.Name] = server.IPAddress
 	}
 
-	answers := struct {
-		Name       string
-		DomainMode string
-	}{}
+	return &survey.Select{
+		Message:     "Forge server:",
+		Options:     options,
+		Description: func(value string, index int) string { return addresses[value] },
+	}
+}
+
+// PromptSiteCount prompts for the number of sites to configure.
+// The count defaults to the number of sites in preset when it is not given explicitly.
+func PromptSiteCount(preset *answers.Answers) (int, error) {
+	if preset != nil {
+		if preset.SiteCount != nil {
+			return *preset.SiteCount, nil
+		}
+		if len(preset.Sites) > 0 {
+			return len(preset.Sites), nil
+		}
+	}
+
+	value, err := askString("site_count", nil, &survey.Input{
+		Message: "How many sites do you want to configure?",
+		Default: "1",
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	count, err := strconv.Atoi(strings.TrimSpace(value))
+	if err != nil || count < 1 {
+		return 0, fmt.Errorf("invalid site count %q", value)
+	}
+
+	return count, nil
+}
+
+// PromptSiteBasicInfo prompts for basic site information. taken holds the
+// names of the other sites, which the site name must not reuse.
+func PromptSiteBasicInfo(siteNumber int, preset *answers.Site, defaults *models.SiteConfig, taken map[string]bool) (map[string]interface{}, error) {
+	fmt.Printf("\nSite %d Configuration\n", siteNumber)
+	fmt.Println(strings.Repeat("-", 50))
+
+	if preset == nil {
+		preset = &answers.Site{}
+	}
+	if defaults == nil {
+		defaults = &models.SiteConfig{}
+	}
 
-	if err := survey.Ask(questions, &answers); err != nil {
+	domainMode, err := askString("domain_mode", preset.DomainMode, &survey.Select{
+		Message: "Domain mode:",
+		Options: []string{"on-forge", "custom"},
+		Default: orDefault(defaults.DomainMode, "on-forge"),
+	})
+	if err != nil {
 		return nil, err
 	}
 
-	name = answers.Name
-	domainMode = answers.DomainMode
+	name, err := askString("name", preset.Name, &survey.Input{Message: "Site name:", Default: defaults.Name}, survey.WithValidator(survey.Required), survey.WithValidator(siteNameValidator(domainMode)), survey.WithValidator(unusedSiteNameValidator(taken)))
+	if err != nil {
+		return nil, err
+	}
+	// Answers and defaults skip the validators
+	if err := unusedSiteNameValidator(taken)(name); err != nil {
+		return nil, err
+	}
 
 	domainPreview := name
 	if domainMode == "on-forge" {
@@ -101,13 +192,17 @@ func PromptSiteBasicInfo(siteNumber int) (map[string]interface{}, error) {
 	}
 	fmt.Printf("  -> Domain will be: %s\n", domainPreview)
 
-	wwwQuestion := &survey.Select{
-		Message: "WWW redirect type:",
-		Options: []string{"none", "from-www", "to-www"},
-		Default: "none",
-	}
-	if err := survey.AskOne(wwwQuestion, &wwwRedirect); err != nil {
-		return nil, err
+	// On-forge domains have no www variant to redirect
+	wwwRedirect := "none"
+	if domainMode != "on-forge" || preset.WWWRedirectType != nil {
+		wwwRedirect, err = askString("www_redirect_type", preset.WWWRedirectType, &survey.Select{
+			Message: "WWW redirect type:",
+			Options: []string{"none", "from-www", "to-www"},
+			Default: orDefault(defaults.WWWRedirectType, "none"),
+		})
+		if err != nil {
+			return nil, err
+		}
 	}
 
 	return map[string]interface{}{
@@ -117,58 +212,95 @@ func PromptSiteBasicInfo(siteNumber int) (map[string]interface{}, error) {
 	}, nil
 }
 
+// siteNameValidator checks a site name against the rules of its domain mode
+func siteNameValidator(domainMode string) survey.Validator {
+	return func(ans interface{}) error {
+		name := strings.TrimSpace(ans.(string))
+		if domainMode == "on-forge" {
+			if err := hostname.CheckOnForge(name); err != nil {
+				return fmt.Errorf("on-forge subdomain %s", err)
+			}
+			return nil
+		}
+		if err := hostname.Check(name, false); err != nil {
+			return fmt.Errorf("hostname %s", err)
+		}
+		return nil
+	}
+}
+
+// unusedSiteNameValidator rejects the name of another site
+func unusedSiteNameValidator(taken map[string]bool) survey.Validator {
+	return func(ans interface{}) error {
+		if name := strings.TrimSpace(ans.(string)); taken[name] {
+			return fmt.Errorf("site %q already exists", name)
+		}
+		return nil
+	}
+}
+
+// aliasValidator checks an alias, which may be a wildcard
+func aliasValidator(ans interface{}) error {
+	if err := hostname.Check(strings.TrimSpace(ans.(string)), true); err != nil {
+		return fmt.Errorf("hostname %s", err)
+	}
+	return nil
+}
+
 // PromptSiteRepositorySettings prompts for repository settings
-func PromptSiteRepositorySettings(defaultBranch string) (map[string]interface{}, error) {
+func PromptSiteRepositorySettings(defaultBranch string, preset *answers.Site, defaults *models.SiteConfig) (map[string]interface{}, error) {
 	fmt.Println("\nRepository Settings")
 
-	var useCustomBranch bool
-	if err := survey.AskOne(&survey.Confirm{
-		Message: fmt.Sprintf("Use different branch than default (%s)?", defaultBranch),
-		Default: false,
-	}, &useCustomBranch); err != nil {
-		return nil, err
+	if preset == nil {
+		preset = &answers.Site{}
+	}
+	if defaults == nil {
+		defaults = &models.SiteConfig{}
 	}
 
-	var githubBranch string
-	if useCustomBranch {
-		if err := survey.AskOne(&survey.Input{
-			Message: "Branch name:",
-		}, &githubBranch, survey.WithValidator(survey.Required)); err != nil {
+	githubBranch := ""
+	if preset.GithubBranch != nil {
+		githubBranch = *preset.GithubBranch
+	} else {
+		useCustomBranch, err := askBool(nil, &survey.Confirm{
+			Message: fmt.Sprintf("Use different branch than default (%s)?", defaultBranch),
+			Default: defaults.GithubBranch != "",
+		})
+		if err != nil {
 			return nil, err
 		}
-	}
 
-	var rootDir, webDir string
-	var cloneRepo bool
+		if useCustomBranch {
+			if githubBranch, err = askString("github_branch", nil, &survey.Input{
+				Message: "Branch name:",
+				Default: defaults.GithubBranch,
+			}, survey.WithValidator(survey.Required)); err != nil {
+				return nil, err
+			}
+		}
+	}
 
-	questions := []*survey.Question{
-		{
-			Name:   "rootDir",
-			Prompt: &survey.Input{Message: "Root directory:", Default: "."},
-		},
-		{
-			Name:   "webDir",
-			Prompt: &survey.Input{Message: "Public/web directory:", Default: "public"},
-		},
-		{
-			Name:   "cloneRepo",
-			Prompt: &survey.Confirm{Message: "Clone repository during site creation?", Default: true},
-		},
+	rootDir, err := askString("root_dir", preset.RootDir, &survey.Input{Message: "Root directory:", Default: orDefault(defaults.RootDir, ".")})
+	if err != nil {
+		return nil, err
 	}
 
-	answers := struct {
-		RootDir   string
-		WebDir    string
-		CloneRepo bool
-	}{}
+	webDefault := defaults.WebDir
+	if webDefault == "" {
+		if project := detectProject(rootDir); project != nil {
+			webDefault = project.WebDir
+		}
+	}
 
-	if err := survey.Ask(questions, &answers); err != nil {
+	webDir, err := askString("web_dir", preset.WebDir, &survey.Input{Message: "Public/web directory:", Default: orDefault(webDefault, "public")})
+	if err != nil {
 		return nil, err
 	}
 
-	rootDir = answers.RootDir
-	webDir = answers.WebDir
-	cloneRepo = answers.CloneRepo
+	cloneRepo, err := askBool(preset.CloneRepository, &survey.Confirm{Message: "Clone repository during site creation?", Default: true})
+	if err != nil {
+		return nil, err
+	}
 
 	return map[string]interface{}{
 		"github_branch":    githubBranch,
@@ -179,41 +311,52 @@ func PromptSiteRepositorySettings(defaultBranch string) (map[string]interface{},
 }
 
 // PromptSitePHPSettings prompts for PHP settings
-func PromptSitePHPSettings() (map[string]interface{}, error) {
+func PromptSitePHPSettings(preset *answers.Site, defaults *models.SiteConfig) (map[string]interface{}, error) {
 	fmt.Println("\nPHP Settings")
 
-	var projectType, phpVersion string
-	var installComposer bool
+	if preset == nil {
+		preset = &answers.Site{}
+	}
+	if defaults == nil {
+		defaults = &models.SiteConfig{}
+	}
 
-	if err := survey.AskOne(&survey.Select{
+	projectType, err := askString("project_type", preset.ProjectType, &survey.Select{
 		Message: "Project type:",
 		Options: []string{"laravel", "other"},
-		Default: "laravel",
-	}, &projectType); err != nil {
-		return nil, err
-	}
-
-	var usePHPVersion bool
-	if err := survey.AskOne(&survey.Confirm{
-		Message: "Specify PHP version?",
-		Default: false,
-	}, &usePHPVersion); err != nil {
+		Default: orDefault(defaults.ProjectType, "laravel"),
+	})
+	if err != nil {
 		return nil, err
 	}
 
-	if usePHPVersion {
-		if err := survey.AskOne(&survey.Input{
-			Message: "PHP version (e.g., php81, php82, php83, php84):",
-			Default: "php84",
-		}, &phpVersion); err != nil {
+	phpVersion := ""
+	if preset.PHPVersion != nil {
+		phpVersion = *preset.PHPVersion
+	} else {
+		usePHPVersion, err := askBool(nil, &survey.Confirm{
+			Message: "Specify PHP version?",
+			Default: defaults.PHPVersion != "",
+		})
+		if err != nil {
 			return nil, err
 		}
+
+		if usePHPVersion {
+			if phpVersion, err = askString("php_version", nil, &survey.Input{
+				Message: "PHP version (e.g., php81, php82, php83, php84):",
+				Default: orDefault(defaults.PHPVersion, "php84"),
+			}); err != nil {
+				return nil, err
+			}
+		}
 	}
 
-	if err := survey.AskOne(&survey.Confirm{
+	installComposer, err := askBool(preset.InstallComposerDependencies, &survey.Confirm{
 		Message: "Install Composer dependencies during site creation?",
-		Default: false,
-	}, &installComposer); err != nil {
+		Default: defaults.InstallComposerDependencies,
+	})
+	if err != nil {
 		return nil, err
 	}
 
@@ -224,15 +367,33 @@ func PromptSitePHPSettings() (map[string]interface{}, error) {
 	}, nil
 }
 
-// PromptDeploymentScript prompts for deployment script
-func PromptDeploymentScript() (string, error) {
+// PromptDeploymentScript prompts for the deployment script, optionally starting
+// from a script of the built-in library rendered with options. The detected
+// project, which may be nil, helps suggest a script.
+func PromptDeploymentScript(preset *answers.Site, defaults *models.SiteConfig, project *detect.Project, options scripts.Options) (string, error) {
 	fmt.Println("\nDeployment Script")
 
-	var addScript bool
-	if err := survey.AskOne(&survey.Confirm{
+	if preset != nil && preset.DeploymentScript != nil {
+		printScriptFindings(lint.Script(*preset.DeploymentScript, options.ZeroDowntime, options.RootDir))
+		return *preset.DeploymentScript, nil
+	}
+	if preset != nil && preset.DeploymentScriptTemplate != nil {
+		script, err := scripts.Render(*preset.DeploymentScriptTemplate, options)
+		if err != nil {
+			return "", err
+		}
+		printScriptFindings(lint.Script(script, options.ZeroDowntime, options.RootDir))
+		return script, nil
+	}
+	if defaults == nil {
+		defaults = &models.SiteConfig{}
+	}
+
+	addScript, err := askBool(nil, &survey.Confirm{
 		Message: "Add custom deployment script?",
-		Default: false,
-	}, &addScript); err != nil {
+		Default: defaults.DeploymentScript != "",
+	})
+	if err != nil {
 		return "", err
 	}
 
@@ -240,46 +401,152 @@ func PromptDeploymentScript() (string, error) {
 		return "", nil
 	}
 
-	var script string
-	if err := survey.AskOne(&survey.Multiline{
-		Message: "Enter deployment script:",
-	}, &script); err != nil {
+	const current, blank = "current script", "blank"
+	var choices []string
+	def := suggestScriptTemplate(defaults, project)
+	if defaults.DeploymentScript != "" {
+		choices = append(choices, current)
+		def = current
+	}
+	choices = append(append(choices, scripts.Names()...), blank)
+
+	choice, err := askString("deployment_script", nil, &survey.Select{
+		Message: "Start from:",
+		Options: choices,
+		Default: def,
+		Description: func(value string, index int) string {
+			if t, ok := scripts.Find(value); ok {
+				return t.Description
+			}
+			return ""
+		},
+	})
+	if err != nil {
 		return "", err
 	}
 
-	return script, nil
+	script := ""
+	switch choice {
+	case current:
+		script = defaults.DeploymentScript
+	case blank:
+	default:
+		if script, err = scripts.Render(choice, options); err != nil {
+			return "", err
+		}
+	}
+
+	for {
+		script, err = askString("deployment_script", nil, &survey.Multiline{
+			Message: "Edit deployment script:",
+			Default: script,
+		})
+		if err != nil {
+			return "", err
+		}
+
+		findings := lint.Script(script, options.ZeroDowntime, options.RootDir)
+		if len(findings) == 0 {
+			return script, nil
+		}
+		printScriptFindings(findings)
+
+		again, err := askBool(nil, &survey.Confirm{
+			Message: "Edit the script again?",
+			Default: len(lint.Errors(findings)) > 0 && !NoInput,
+		})
+		if err != nil {
+			return "", err
+		}
+		if !again {
+			return script, nil
+		}
+	}
+}
+
+// printScriptFindings prints the lint findings of a deployment script
+func printScriptFindings(findings []lint.Finding) {
+	if len(findings) == 0 {
+		return
+	}
+	fmt.Printf("\nFound %d problem(s) in the deployment script:\n", len(findings))
+	for _, f := range findings {
+		fmt.Printf("  - %s: %s\n", f.Severity, f)
+	}
+}
+
+// suggestScriptTemplate picks the library script matching a site's project and
+// processes. The detected project, which may be nil, tells whether it builds
+// assets with npm.
+func suggestScriptTemplate(site *models.SiteConfig, project *detect.Project) string {
+	if site.ProjectType == "other" {
+		return "static"
+	}
+	for _, process := range site.Processes {
+		switch {
+		case strings.Contains(process.Command, "horizon"):
+			return "laravel-horizon"
+		case strings.Contains(process.Command, "octane"):
+			return "laravel-octane"
+		}
+	}
+	if project != nil && project.NpmBuild {
+		return "laravel-npm"
+	}
+	return "laravel"
 }
 
 // PromptEnvironmentVariables prompts for environment variables
-func PromptEnvironmentVariables() (string, string, error) {
+func PromptEnvironmentVariables(preset *answers.Site, defaults *models.SiteConfig) (string, string, error) {
 	fmt.Println("\nEnvironment Variables")
 
-	var envChoice string
-	if err := survey.AskOne(&survey.Select{
+	if preset != nil && (preset.Environment != nil || preset.EnvFile != nil) {
+		var environment, envFile string
+		if preset.Environment != nil {
+			environment = *preset.Environment
+		}
+		if preset.EnvFile != nil {
+			envFile = *preset.EnvFile
+		}
+		return replaceSecrets(environment), envFile, nil
+	}
+	if defaults == nil {
+		defaults = &models.SiteConfig{}
+	}
+
+	defaultChoice := "none"
+	if defaults.Environment != "" {
+		defaultChoice = "inline"
+	} else if defaults.EnvFile != "" {
+		defaultChoice = "file"
+	}
+
+	envChoice, err := askString("environment", nil, &survey.Select{
 		Message: "Environment configuration:",
 		Options: []string{"none", "inline", "file"},
-		Default: "none",
-	}, &envChoice); err != nil {
+		Default: defaultChoice,
+	})
+	if err != nil {
 		return "", "", err
 	}
 
 	switch envChoice {
 	case "inline":
-		var useTemplate bool
-		if err := survey.AskOne(&survey.Confirm{
+		useTemplate, err := askBool(nil, &survey.Confirm{
 			Message: "Copy environment variables from a template file (e.g., .env.example)?",
 			Default: false,
-		}, &useTemplate); err != nil {
+		})
+		if err != nil {
 			return "", "", err
 		}
 
-		var envVars string
+		envVars := defaults.Environment
 		if useTemplate {
-			var templatePath string
-			if err := survey.AskOne(&survey.Input{
+			templatePath, err := askString("environment", nil, &survey.Input{
 				Message: "Path to template file (relative to repository root):",
 				Default: ".env.example",
-			}, &templatePath, survey.WithValidator(survey.Required)); err != nil {
+			}, survey.WithValidator(survey.Required))
+			if err != nil {
 				return "", "", err
 			}
 
@@ -295,18 +562,22 @@ func PromptEnvironmentVariables() (string, string, error) {
 		}
 
 		// Allow editing or manual entry
-		if err := survey.AskOne(&survey.Multiline{
+		if envVars, err = askString("environment", nil, &survey.Multiline{
 			Message: "Enter/edit environment variables:",
 			Default: envVars,
-		}, &envVars); err != nil {
+		}); err != nil {
+			return "", "", err
+		}
+		if envVars, err = promptSecretPlaceholders(envVars); err != nil {
 			return "", "", err
 		}
 		return envVars, "", nil
 	case "file":
-		var envFile string
-		if err := survey.AskOne(&survey.Input{
+		envFile, err := askString("env_file", nil, &survey.Input{
 			Message: "Path to .env file (relative to repository root):",
-		}, &envFile, survey.WithValidator(survey.Required)); err != nil {
+			Default: defaults.EnvFile,
+		}, survey.WithValidator(survey.Required))
+		if err != nil {
 			return "", "", err
 		}
 		return "", envFile, nil
@@ -315,6 +586,29 @@ func PromptEnvironmentVariables() (string, string, error) {
 	return "", "", nil
 }
 
+// ProjectDir is the repository root that detectProject resolves root
+// directories against: the directory the configuration is written to
+var ProjectDir = "."
+
+// detectProject inspects the site's root directory, relative to ProjectDir.
+// It returns nil when nothing could be read.
+func detectProject(rootDir string) *detect.Project {
+	project, err := detect.Detect(filepath.Join(ProjectDir, rootDir))
+	if err != nil {
+		fmt.Printf("  Warning: could not detect project settings: %v\n", err)
+		return nil
+	}
+	return project
+}
+
+// orDefault returns value, or fallback when value is empty
+func orDefault(value, fallback string) string {
+	if value == "" {
+		return fallback
+	}
+	return value
+}
+
 // readFileContent reads a file and returns its content
 func readFileContent(path string) (string, error) {
 	content, err := os.ReadFile(path)
@@ -324,15 +618,93 @@ func readFileContent(path string) (string, error) {
 	return string(content), nil
 }
 
-// PromptProcesses prompts for background processes
-func PromptProcesses() ([]models.Process, error) {
+// KeepSecrets keeps values that look like real secrets in inline environments.
+// Otherwise they are replaced with ${KEY} placeholders, after asking when
+// prompting and without asking for answers and under NoInput.
+var KeepSecrets bool
+
+// replaceSecrets replaces the values that look like real secrets with ${KEY}
+// placeholders, unless KeepSecrets is set, in which case it only warns
+func replaceSecrets(env string) string {
+	findings := secrets.Scan(env)
+	for _, finding := range findings {
+		if KeepSecrets {
+			fmt.Printf("Warning: environment %s; consider replacing the value with %s\n", finding, finding.Placeholder())
+		} else {
+			fmt.Printf("Replacing the value of %s with %s: it looks like %s\n", finding.Key, finding.Placeholder(), finding.Reason)
+		}
+	}
+	if KeepSecrets || len(findings) == 0 {
+		return env
+	}
+	fmt.Println("Add the replaced values as GitHub secrets with the same names, or pass --keep-secrets to keep them.")
+	return secrets.Replace(env, findings)
+}
+
+// promptSecretPlaceholders offers to replace values that look like real secrets
+// with ${KEY} placeholders, which the workflow fills from GitHub secrets
+func promptSecretPlaceholders(env string) (string, error) {
+	if NoInput || KeepSecrets {
+		return replaceSecrets(env), nil
+	}
+
+	findings := secrets.Scan(env)
+	if len(findings) == 0 {
+		return env, nil
+	}
+
+	fmt.Printf("\nFound %d value(s) that look like real secrets. The environment is committed to git.\n", len(findings))
+
+	var replace []secrets.Finding
+	for _, finding := range findings {
+		fmt.Printf("  - %s\n", finding)
+		ok, err := askBool(nil, &survey.Confirm{
+			Message: fmt.Sprintf("Replace the value of %s with %s?", finding.Key, finding.Placeholder()),
+			Default: true,
+		})
+		if err != nil {
+			return "", err
+		}
+		if ok {
+			replace = append(replace, finding)
+		}
+	}
+
+	if len(replace) > 0 {
+		env = secrets.Replace(env, replace)
+		fmt.Println("Add the replaced values as GitHub secrets with the same names.")
+	}
+	return env, nil
+}
+
+// PromptProcesses prompts for background processes. Presets fill in the
+// artisan command for the site's phpVersion. Taken maps the process names of
+// the other sites to their site; names must not clash with them.
+func PromptProcesses(preset *answers.Site, defaults *models.SiteConfig, phpVersion string, taken map[string]string) ([]models.Process, error) {
 	fmt.Println("\nBackground Processes")
 
-	var addProcesses bool
-	if err := survey.AskOne(&survey.Confirm{
+	if preset != nil && preset.Processes != nil {
+		return *preset.Processes, nil
+	}
+
+	if defaults != nil && len(defaults.Processes) > 0 {
+		for _, process := range defaults.Processes {
+			fmt.Printf("  - %s: %s\n", process.Name, process.Command)
+		}
+		keep, err := askBool(nil, &survey.Confirm{
+			Message: fmt.Sprintf("Use these %d process(es)?", len(defaults.Processes)),
+			Default: true,
+		})
+		if err != nil || keep {
+			return defaults.Processes, err
+		}
+	}
+
+	addProcesses, err := askBool(nil, &survey.Confirm{
 		Message: "Add background processes?",
 		Default: false,
-	}, &addProcesses); err != nil {
+	})
+	if err != nil {
 		return nil, err
 	}
 
@@ -340,46 +712,70 @@ func PromptProcesses() ([]models.Process, error) {
 		return nil, nil
 	}
 
+	const custom = "custom"
 	var processes []models.Process
 
 	for {
-		var name, command string
-
-		questions := []*survey.Question{
-			{
-				Name:     "name",
-				Prompt:   &survey.Input{Message: "Process name:"},
-				Validate: survey.Required,
-			},
-			{
-				Name:     "command",
-				Prompt:   &survey.Input{Message: "Process command:"},
-				Validate: survey.Required,
+		choice, err := askString("processes", nil, &survey.Select{
+			Message: "Process:",
+			Options: append(presets.Names(), custom),
+			Description: func(value string, index int) string {
+				if p, ok := presets.Find(value); ok {
+					return p.Description
+				}
+				return "Any other command"
 			},
+		})
+		if err != nil {
+			return nil, err
 		}
 
-		answers := struct {
-			Name    string
-			Command string
-		}{}
+		var process models.Process
+		if p, ok := presets.Find(choice); ok {
+			process = p.Process(phpVersion)
+			process.Name = uniqueProcessName(process.Name, taken, processes)
+		}
 
-		if err := survey.Ask(questions, &answers); err != nil {
+		required := survey.WithValidator(survey.Required)
+		unique := survey.WithValidator(func(ans interface{}) error {
+			name := strings.TrimSpace(ans.(string))
+			if site, ok := taken[name]; ok {
+				return fmt.Errorf("process name %q is already used by site %s", name, site)
+			}
+			for _, other := range processes {
+				if other.Name == name {
+					return fmt.Errorf("process name %q is already used by this site", name)
+				}
+			}
+			return nil
+		})
+		if process.Name, err = askString("processes", nil, &survey.Input{Message: "Process name:", Default: process.Name}, required, unique); err != nil {
+			return nil, err
+		}
+		if process.Command, err = askString("processes", nil, &survey.Input{Message: "Process command:", Default: process.Command}, required); err != nil {
 			return nil, err
 		}
 
-		name = answers.Name
-		command = answers.Command
-
-		processes = append(processes, models.Process{
-			Name:    name,
-			Command: command,
+		options, err := askBool(nil, &survey.Confirm{
+			Message: "Set daemon options (directory, user, processes, startsecs, stopwaitsecs)?",
+			Default: false,
 		})
+		if err != nil {
+			return nil, err
+		}
+		if options {
+			if err := promptDaemonOptions(&process); err != nil {
+				return nil, err
+			}
+		}
+
+		processes = append(processes, process)
 
-		var addAnother bool
-		if err := survey.AskOne(&survey.Confirm{
+		addAnother, err := askBool(nil, &survey.Confirm{
 			Message: "Add another process?",
 			Default: false,
-		}, &addAnother); err != nil {
+		})
+		if err != nil {
 			return nil, err
 		}
 
@@ -391,30 +787,246 @@ func PromptProcesses() ([]models.Process, error) {
 	return processes, nil
 }
 
+// uniqueProcessName returns name, or name with a number appended when another
+// site or an earlier process of this site already uses it
+func uniqueProcessName(name string, taken map[string]string, processes []models.Process) string {
+	used := func(candidate string) bool {
+		if _, ok := taken[candidate]; ok {
+			return true
+		}
+		for _, process := range processes {
+			if process.Name == candidate {
+				return true
+			}
+		}
+		return false
+	}
+	candidate := name
+	for n := 2; used(candidate); n++ {
+		candidate = fmt.Sprintf("%s-%d", name, n)
+	}
+	return candidate
+}
+
+// promptDaemonOptions prompts for the daemon options of a process, keeping
+// its current values as defaults. Empty answers keep Forge's defaults.
+func promptDaemonOptions(process *models.Process) error {
+	var err error
+	if process.Directory, err = askString("processes", nil, &survey.Input{
+		Message: "Working directory (absolute, empty for the site directory):",
+		Default: process.Directory,
+	}, survey.WithValidator(func(ans interface{}) error {
+		if dir := ans.(string); dir != "" && !strings.HasPrefix(dir, "/") {
+			return fmt.Errorf("directory must be an absolute path")
+		}
+		return nil
+	})); err != nil {
+		return err
+	}
+
+	if process.User, err = askString("processes", nil, &survey.Input{
+		Message: "User (empty for the site's user):",
+		Default: process.User,
+	}); err != nil {
+		return err
+	}
+
+	for _, option := range []struct {
+		message string
+		value   *int
+	}{
+		{"Number of processes:", &process.Processes},
+		{"Start seconds (startsecs):", &process.StartSecs},
+		{"Stop wait seconds (stopwaitsecs):", &process.StopWaitSecs},
+	} {
+		def := ""
+		if *option.value != 0 {
+			def = strconv.Itoa(*option.value)
+		}
+		value, err := askString("processes", nil, &survey.Input{Message: option.message, Default: def},
+			survey.WithValidator(nonNegativeInt))
+		if err != nil {
+			return err
+		}
+		*option.value = 0
+		if value != "" {
+			*option.value, _ = strconv.Atoi(value)
+		}
+	}
+
+	return nil
+}
+
+// nonNegativeInt validates an optional whole number answer
+func nonNegativeInt(ans interface{}) error {
+	value := strings.TrimSpace(ans.(string))
+	if value == "" {
+		return nil
+	}
+	if n, err := strconv.Atoi(value); err != nil || n < 0 {
+		return fmt.Errorf("%q is not a whole number", value)
+	}
+	return nil
+}
+
 // PromptScheduler prompts for Laravel scheduler
-func PromptScheduler() (bool, error) {
+func PromptScheduler(preset *answers.Site, defaults *models.SiteConfig) (bool, error) {
 	fmt.Println("\nLaravel Scheduler")
 
-	var enabled bool
-	if err := survey.AskOne(&survey.Confirm{
+	if preset == nil {
+		preset = &answers.Site{}
+	}
+	if defaults == nil {
+		defaults = &models.SiteConfig{}
+	}
+
+	return askBool(preset.LaravelScheduler, &survey.Confirm{
 		Message: "Enable Laravel scheduler?",
+		Default: defaults.LaravelScheduler,
+	})
+}
+
+// PromptScheduledJobs prompts for the site's scheduled jobs, showing when
+// each one would run next
+func PromptScheduledJobs(preset *answers.Site, defaults *models.SiteConfig) ([]models.ScheduledJob, error) {
+	fmt.Println("\nScheduled Jobs")
+
+	if preset != nil && preset.ScheduledJobs != nil {
+		printScheduledJobs(*preset.ScheduledJobs)
+		return *preset.ScheduledJobs, nil
+	}
+
+	if defaults != nil && len(defaults.ScheduledJobs) > 0 {
+		printScheduledJobs(defaults.ScheduledJobs)
+		keep, err := askBool(nil, &survey.Confirm{
+			Message: fmt.Sprintf("Use these %d scheduled job(s)?", len(defaults.ScheduledJobs)),
+			Default: true,
+		})
+		if err != nil || keep {
+			return defaults.ScheduledJobs, err
+		}
+	}
+
+	addJobs, err := askBool(nil, &survey.Confirm{
+		Message: "Add scheduled jobs (cron)?",
 		Default: false,
-	}, &enabled); err != nil {
-		return false, err
+	})
+	if err != nil || !addJobs {
+		return nil, err
+	}
+
+	var jobs []models.ScheduledJob
+	for {
+		var job models.ScheduledJob
+		if job.Command, err = askString("scheduled_jobs", nil, &survey.Input{Message: "Command:"},
+			survey.WithValidator(survey.Required)); err != nil {
+			return nil, err
+		}
+
+		if job.Frequency, err = askString("scheduled_jobs", nil, &survey.Select{
+			Message: "Frequency:",
+			Options: cron.FrequencyNames,
+			Default: "nightly",
+			Description: func(value string, index int) string {
+				return cron.Frequencies[value]
+			},
+		}); err != nil {
+			return nil, err
+		}
+
+		if job.Frequency == "custom" {
+			if job.Cron, err = askString("scheduled_jobs", nil, &survey.Input{
+				Message: "Cron expression (minute hour day-of-month month day-of-week):",
+			}, survey.WithValidator(func(ans interface{}) error {
+				_, err := cron.Parse(ans.(string))
+				return err
+			})); err != nil {
+				return nil, err
+			}
+		}
+
+		if job.User, err = askString("scheduled_jobs", nil, &survey.Input{
+			Message: "User (empty for the site's user):",
+		}); err != nil {
+			return nil, err
+		}
+
+		printNextRuns(job, time.Now().UTC(), 5)
+		jobs = append(jobs, job)
+
+		addAnother, err := askBool(nil, &survey.Confirm{
+			Message: "Add another scheduled job?",
+			Default: false,
+		})
+		if err != nil {
+			return nil, err
+		}
+		if !addAnother {
+			break
+		}
+	}
+
+	return jobs, nil
+}
+
+// printScheduledJobs lists jobs with their next run
+func printScheduledJobs(jobs []models.ScheduledJob) {
+	for _, job := range jobs {
+		fmt.Printf("  - %s: %s\n", job.Schedule(), job.Command)
+		printNextRuns(job, time.Now().UTC(), 1)
 	}
+}
 
-	return enabled, nil
+// printNextRuns prints when a scheduled job runs next. Forge servers run on UTC.
+func printNextRuns(job models.ScheduledJob, from time.Time, n int) {
+	expr := job.Expression()
+	if expr == "" {
+		fmt.Println("    -> Runs whenever the server restarts")
+		return
+	}
+	schedule, err := cron.Parse(expr)
+	if err != nil {
+		return
+	}
+	runs := schedule.NextN(from, n)
+	if len(runs) == 0 {
+		fmt.Println("    -> Never runs: no date matches the expression")
+		return
+	}
+	if len(runs) == 1 {
+		fmt.Printf("    -> Next run: %s UTC\n", runs[0].Format("Mon 2 Jan 2006 15:04"))
+		return
+	}
+	fmt.Println("    -> Next runs (UTC):")
+	for _, run := range runs {
+		fmt.Printf("       %s\n", run.Format("Mon 2 Jan 2006 15:04"))
+	}
 }
 
 // PromptAliases prompts for domain aliases
-func PromptAliases() ([]string, error) {
+func PromptAliases(preset *answers.Site, defaults *models.SiteConfig) ([]string, error) {
 	fmt.Println("\nDomain Aliases")
 
-	var addAliases bool
-	if err := survey.AskOne(&survey.Confirm{
+	if preset != nil && preset.Aliases != nil {
+		return *preset.Aliases, nil
+	}
+
+	if defaults != nil && len(defaults.Aliases) > 0 {
+		fmt.Printf("  Current aliases: %s\n", strings.Join(defaults.Aliases, ", "))
+		keep, err := askBool(nil, &survey.Confirm{
+			Message: "Keep the existing aliases?",
+			Default: true,
+		})
+		if err != nil || keep {
+			return defaults.Aliases, err
+		}
+	}
+
+	addAliases, err := askBool(nil, &survey.Confirm{
 		Message: "Add domain aliases?",
 		Default: false,
-	}, &addAliases); err != nil {
+	})
+	if err != nil {
 		return nil, err
 	}
 
@@ -425,20 +1037,20 @@ func PromptAliases() ([]string, error) {
 	var aliases []string
 
 	for {
-		var alias string
-		if err := survey.AskOne(&survey.Input{
+		alias, err := askString("aliases", nil, &survey.Input{
 			Message: "Alias domain:",
-		}, &alias, survey.WithValidator(survey.Required)); err != nil {
+		}, survey.WithValidator(survey.Required), survey.WithValidator(aliasValidator))
+		if err != nil {
 			return nil, err
 		}
 
 		aliases = append(aliases, alias)
 
-		var addAnother bool
-		if err := survey.AskOne(&survey.Confirm{
+		addAnother, err := askBool(nil, &survey.Confirm{
 			Message: "Add another alias?",
 			Default: false,
-		}, &addAnother); err != nil {
+		})
+		if err != nil {
 			return nil, err
 		}
 
@@ -450,71 +1062,122 @@ func PromptAliases() ([]string, error) {
 	return aliases, nil
 }
 
+// NginxTemplates is the directory of local nginx templates PromptNginxConfig offers
+var NginxTemplates string
+
 // PromptNginxConfig prompts for Nginx configuration
-func PromptNginxConfig() (map[string]interface{}, error) {
+func PromptNginxConfig(preset *answers.Site, defaults *models.SiteConfig) (map[string]interface{}, error) {
 	fmt.Println("\nNginx Configuration")
 
-	var configChoice string
-	if err := survey.AskOne(&survey.Select{
+	result := make(map[string]interface{})
+
+	if preset != nil && (preset.NginxTemplate != nil || preset.NginxTemplateVariables != nil || preset.NginxCustomConfig != nil) {
+		if preset.NginxTemplate != nil && *preset.NginxTemplate != "" {
+			result["nginx_template"] = *preset.NginxTemplate
+		}
+		if preset.NginxTemplateVariables != nil && len(*preset.NginxTemplateVariables) > 0 {
+			result["nginx_template_variables"] = *preset.NginxTemplateVariables
+		}
+		if preset.NginxCustomConfig != nil && *preset.NginxCustomConfig != "" {
+			result["nginx_custom_config"] = *preset.NginxCustomConfig
+		}
+		return result, nil
+	}
+	if defaults == nil {
+		defaults = &models.SiteConfig{}
+	}
+
+	defaultChoice := "default"
+	if defaults.NginxTemplate != "" {
+		defaultChoice = "template"
+	} else if defaults.NginxCustomConfig != "" {
+		defaultChoice = "custom-file"
+	}
+
+	configChoice, err := askString("nginx_template", nil, &survey.Select{
 		Message: "Nginx configuration:",
 		Options: []string{"default", "template", "custom-file"},
-		Default: "default",
-	}, &configChoice); err != nil {
+		Default: defaultChoice,
+	})
+	if err != nil {
 		return nil, err
 	}
 
-	result := make(map[string]interface{})
-
 	switch configChoice {
 	case "template":
-		var templateName string
-		if err := survey.AskOne(&survey.Input{
+		templates, err := nginx.LoadTemplates(NginxTemplates)
+		if err != nil {
+			fmt.Printf("  Warning: could not read nginx templates: %v\n", err)
+		}
+		if len(templates) > 0 {
+			name, variables, err := promptLocalNginxTemplate(templates, defaults)
+			if err != nil {
+				return nil, err
+			}
+			if name != "" {
+				result["nginx_template"] = name
+				if len(variables) > 0 {
+					result["nginx_template_variables"] = variables
+				}
+				break
+			}
+		}
+
+		templateName, err := askString("nginx_template", nil, &survey.Input{
 			Message: "Template name:",
-		}, &templateName, survey.WithValidator(survey.Required)); err != nil {
+			Default: defaults.NginxTemplate,
+		}, survey.WithValidator(survey.Required))
+		if err != nil {
 			return nil, err
 		}
 		result["nginx_template"] = templateName
 
-		var addVars bool
-		if err := survey.AskOne(&survey.Confirm{
+		keepVars := false
+		if len(defaults.NginxTemplateVariables) > 0 {
+			for key, value := range defaults.NginxTemplateVariables {
+				fmt.Printf("  - %s=%s\n", key, value)
+			}
+			if keepVars, err = askBool(nil, &survey.Confirm{
+				Message: "Keep the existing template variables?",
+				Default: true,
+			}); err != nil {
+				return nil, err
+			}
+		}
+
+		addVars := false
+		if keepVars {
+			result["nginx_template_variables"] = defaults.NginxTemplateVariables
+		} else if addVars, err = askBool(nil, &survey.Confirm{
 			Message: "Add template variables?",
 			Default: false,
-		}, &addVars); err != nil {
+		}); err != nil {
 			return nil, err
 		}
 
 		if addVars {
 			variables := make(map[string]string)
 			for {
-				questions := []*survey.Question{
-					{
-						Name:     "key",
-						Prompt:   &survey.Input{Message: "Variable name:"},
-						Validate: survey.Required,
-					},
-					{
-						Name:     "value",
-						Prompt:   &survey.Input{Message: "Variable value:"},
-						Validate: survey.Required,
-					},
+				key, err := askString("nginx_template_variables", nil, &survey.Input{
+					Message: "Variable name:",
+				}, survey.WithValidator(survey.Required))
+				if err != nil {
+					return nil, err
 				}
-
-				answers := struct {
-					Key   string
-					Value string
-				}{}
-
-				if err := survey.Ask(questions, &answers); err != nil {
+				value, err := askString("nginx_template_variables", nil, &survey.Input{
+					Message: "Variable value:",
+				}, survey.WithValidator(survey.Required))
+				if err != nil {
 					return nil, err
 				}
 
-				variables[answers.Key] = answers.Value
+				variables[key] = value
 
-				var addAnother bool
-				if err := survey.AskOne(&survey.Confirm{
+				addAnother, err := askBool(nil, &survey.Confirm{
 					Message: "Add another variable?",
 					Default: false,
-				}, &addAnother); err != nil {
+				})
+				if err != nil {
 					return nil, err
 				}
 
@@ -526,10 +1189,11 @@ func PromptNginxConfig() (map[string]interface{}, error) {
 		}
 
 	case "custom-file":
-		var customConfig string
-		if err := survey.AskOne(&survey.Input{
+		customConfig, err := askString("nginx_custom_config", nil, &survey.Input{
 			Message: "Path to custom nginx config (relative to repository root):",
-		}, &customConfig, survey.WithValidator(survey.Required)); err != nil {
+			Default: defaults.NginxCustomConfig,
+		}, survey.WithValidator(survey.Required))
+		if err != nil {
 			return nil, err
 		}
 		result["nginx_custom_config"] = customConfig
@@ -538,38 +1202,103 @@ func PromptNginxConfig() (map[string]interface{}, error) {
 	return result, nil
 }
 
+// promptLocalNginxTemplate offers the local templates and prompts for exactly
+// the variables the chosen one uses. It returns an empty name when another
+// Forge template is wanted.
+func promptLocalNginxTemplate(templates []nginx.Template, defaults *models.SiteConfig) (string, map[string]string, error) {
+	const other = "other Forge template"
+	options := make([]string, 0, len(templates)+1)
+	for _, t := range templates {
+		options = append(options, t.Name)
+	}
+	options = append(options, other)
+
+	def := other
+	if _, ok := nginx.Find(templates, defaults.NginxTemplate); ok || defaults.NginxTemplate == "" {
+		def = orDefault(defaults.NginxTemplate, options[0])
+	}
+
+	name, err := askString("nginx_template", nil, &survey.Select{
+		Message: "Template:",
+		Options: options,
+		Default: def,
+		Description: func(value string, index int) string {
+			if t, ok := nginx.Find(templates, value); ok {
+				return t.Path
+			}
+			return ""
+		},
+	})
+	if err != nil || name == other {
+		return "", nil, err
+	}
+
+	template, _ := nginx.Find(templates, name)
+	placeholders := template.Variables()
+	if len(placeholders) == 0 {
+		fmt.Println("  -> The template only uses Forge variables")
+		return name, nil, nil
+	}
+
+	variables := make(map[string]string, len(placeholders))
+	for _, p := range placeholders {
+		if forge := nginx.Suggest(p.Name, nginx.ForgeVariableNames()); forge != "" {
+			fmt.Printf("  Warning: {{%s}} on line %d looks like a typo of the Forge variable {{%s}}\n", p.Name, p.Line, forge)
+		}
+		value, err := askString("nginx_template_variables", nil, &survey.Input{
+			Message: fmt.Sprintf("{{%s}}:", p.Name),
+			Default: defaults.NginxTemplateVariables[p.Name],
+		}, survey.WithValidator(survey.Required))
+		if err != nil {
+			return "", nil, err
+		}
+		variables[p.Name] = value
+	}
+	return name, variables, nil
+}
+
 // PromptSSLCertificate prompts for SSL certificate
-func PromptSSLCertificate() (bool, error) {
+func PromptSSLCertificate(preset *answers.Site, defaults *models.SiteConfig) (bool, error) {
 	fmt.Println("\nSSL Certificate")
 
-	var enabled bool
-	if err := survey.AskOne(&survey.Confirm{
-		Message: "Create SSL certificate?",
-		Default: false,
-	}, &enabled); err != nil {
-		return false, err
+	if preset == nil {
+		preset = &answers.Site{}
+	}
+	if defaults == nil {
+		defaults = &models.SiteConfig{}
 	}
 
-	return enabled, nil
+	return askBool(preset.Certificate, &survey.Confirm{
+		Message: "Create SSL certificate?",
+		Default: defaults.Certificate,
+	})
 }
 
 // PromptIsolation prompts for site isolation
-func PromptIsolation() (map[string]interface{}, error) {
+func PromptIsolation(preset *answers.Site, defaults *models.SiteConfig) (map[string]interface{}, error) {
 	fmt.Println("\nSite Isolation")
 
-	var isolated bool
-	if err := survey.AskOne(&survey.Confirm{
+	if preset == nil {
+		preset = &answers.Site{}
+	}
+	if defaults == nil {
+		defaults = &models.SiteConfig{}
+	}
+
+	isolated, err := askBool(preset.Isolated, &survey.Confirm{
 		Message: "Run as isolated user?",
-		Default: false,
-	}, &isolated); err != nil {
+		Default: defaults.Isolated,
+	})
+	if err != nil {
 		return nil, err
 	}
 
 	var isolatedUser string
 	if isolated {
-		if err := survey.AskOne(&survey.Input{
+		if isolatedUser, err = askString("isolated_user", preset.IsolatedUser, &survey.Input{
 			Message: "Isolated user name:",
-		}, &isolatedUser, survey.WithValidator(survey.Required)); err != nil {
+			Default: defaults.IsolatedUser,
+		}, survey.WithValidator(survey.Required)); err != nil {
 			return nil, err
 		}
 	}
@@ -581,89 +1310,88 @@ func PromptIsolation() (map[string]interface{}, error) {
 }
 
 // PromptZeroDowntime prompts for zero-downtime deployment settings
-func PromptZeroDowntime() (map[string]interface{}, error) {
+func PromptZeroDowntime(preset *answers.Site, defaults *models.SiteConfig) (map[string]interface{}, error) {
 	fmt.Println("\nZero-Downtime Deployment")
 
-	var zeroDowntime bool
-	if err := survey.AskOne(&survey.Confirm{
+	if preset == nil {
+		preset = &answers.Site{}
+	}
+	if defaults == nil {
+		defaults = &models.SiteConfig{}
+	}
+
+	zeroDowntime, err := askBool(preset.ZeroDowntimeDeployments, &survey.Confirm{
 		Message: "Enable zero-downtime deployments?",
-		Default: false,
-	}, &zeroDowntime); err != nil {
+		Default: defaults.ZeroDowntimeDeployments,
+	})
+	if err != nil {
 		return nil, err
 	}
 
 	var sharedPaths []models.SharedPath
+	keepPaths := false
 
-	if zeroDowntime {
-		var addPaths bool
-		if err := survey.AskOne(&survey.Confirm{
-			Message: "Add shared paths?",
+	if zeroDowntime && preset.SharedPaths == nil && len(defaults.SharedPaths) > 0 {
+		for _, path := range defaults.SharedPaths {
+			fmt.Printf("  - %s\n", path.From)
+		}
+		if keepPaths, err = askBool(nil, &survey.Confirm{
+			Message: "Keep the existing shared paths?",
 			Default: true,
-		}, &addPaths); err != nil {
+		}); err != nil {
 			return nil, err
 		}
+	}
 
-		if addPaths {
-			for {
-				var pathType string
-				if err := survey.AskOne(&survey.Select{
-					Message: "Path type:",
-					Options: []string{"simple", "custom"},
-					Default: "simple",
-				}, &pathType); err != nil {
-					return nil, err
-				}
+	if zeroDowntime && preset.SharedPaths != nil {
+		sharedPaths = *preset.SharedPaths
+	} else if keepPaths {
+		sharedPaths = defaults.SharedPaths
+	} else if zeroDowntime {
+		// Shared paths are optional, so without input there are none
+		addPaths, err := askBool(nil, &survey.Confirm{
+			Message: "Add shared paths?",
+			Default: !NoInput,
+		})
+		if err != nil {
+			return nil, err
+		}
 
-				if pathType == "simple" {
-					var path string
-					if err := survey.AskOne(&survey.Input{
-						Message: "Path:",
-					}, &path, survey.WithValidator(survey.Required)); err != nil {
-						return nil, err
-					}
-					sharedPaths = append(sharedPaths, models.SharedPath{From: path})
-				} else {
-					var fromPath, toPath string
-
-					questions := []*survey.Question{
-						{
-							Name:     "from",
-							Prompt:   &survey.Input{Message: "From path:"},
-							Validate: survey.Required,
-						},
-						{
-							Name:     "to",
-							Prompt:   &survey.Input{Message: "To path:"},
-							Validate: survey.Required,
-						},
-					}
-
-					answers := struct {
-						From string
-						To   string
-					}{}
-
-					if err := survey.Ask(questions, &answers); err != nil {
-						return nil, err
-					}
-
-					fromPath = answers.From
-					toPath = answers.To
-
-					sharedPaths = append(sharedPaths, models.SharedPath{From: fromPath, To: toPath})
-				}
+		for addPaths {
+			pathType, err := askString("shared_paths", nil, &survey.Select{
+				Message: "Path type:",
+				Options: []string{"simple", "custom"},
+				Default: "simple",
+			})
+			if err != nil {
+				return nil, err
+			}
 
-				var addAnother bool
-				if err := survey.AskOne(&survey.Confirm{
-					Message: "Add another shared path?",
-					Default: false,
-				}, &addAnother); err != nil {
+			message := "Path:"
+			if pathType == "custom" {
+				message = "From path:"
+			}
+			from, err := askString("shared_paths", nil, &survey.Input{
+				Message: message,
+			}, survey.WithValidator(survey.Required))
+			if err != nil {
+				return nil, err
+			}
+			path := models.SharedPath{From: from}
+			if pathType == "custom" {
+				if path.To, err = askString("shared_paths", nil, &survey.Input{
+					Message: "To path:",
+				}, survey.WithValidator(survey.Required)); err != nil {
 					return nil, err
 				}
+			}
+			sharedPaths = append(sharedPaths, path)
 
-				if !addAnother {
-					break
-				}
+			if addPaths, err = askBool(nil, &survey.Confirm{
+				Message: "Add another shared path?",
+				Default: false,
+			}); err != nil {
+				return nil, err
 			}
 		}
 	}
@@ -674,76 +1402,117 @@ func PromptZeroDowntime() (map[string]interface{}, error) {
 	}, nil
 }
 
-// PromptCompleteSite orchestrates all site prompts
-func PromptCompleteSite(defaultBranch string, siteNumber int) (*models.SiteConfig, error) {
-	// Basic info
-	basicInfo, err := PromptSiteBasicInfo(siteNumber)
+// PromptCompleteSite orchestrates all site prompts.
+// Questions answered in preset are not asked, and the remaining questions are
+// pre-filled from defaults, such as the current values of a site being edited.
+// Both preset and defaults may be nil.
+func PromptCompleteSite(config *models.DeploymentConfig, siteNumber int, preset *answers.Site, defaults *models.SiteConfig) (*models.SiteConfig, error) {
+	// Basic info, with a name no other site uses
+	names := make(map[string]bool)
+	for i, site := range config.Sites {
+		if i != siteNumber-1 {
+			names[site.Name] = true
+		}
+	}
+	basicInfo, err := PromptSiteBasicInfo(siteNumber, preset, defaults, names)
 	if err != nil {
 		return nil, err
 	}
 
 	// Repository settings
-	repoSettings, err := PromptSiteRepositorySettings(defaultBranch)
+	repoSettings, err := PromptSiteRepositorySettings(config.GithubBranch, preset, defaults)
 	if err != nil {
 		return nil, err
 	}
 
+	// Pre-fill the remaining prompts from the project when creating a new site
+	edited := defaults
+	var project *detect.Project
+	if defaults == nil {
+		if project = detectProject(repoSettings["root_dir"].(string)); project != nil {
+			if summary := project.Summary(); summary != "" {
+				fmt.Printf("\n  Detected %s\n", summary)
+			}
+			defaults = project.Defaults()
+		}
+	}
+
 	// PHP settings
-	phpSettings, err := PromptSitePHPSettings()
+	phpSettings, err := PromptSitePHPSettings(preset, defaults)
+	if err != nil {
+		return nil, err
+	}
+
+	// Zero-downtime, asked first since it selects the deployment script variant
+	zeroDowntime, err := PromptZeroDowntime(preset, defaults)
 	if err != nil {
 		return nil, err
 	}
 
 	// Deployment script
-	deploymentScript, err := PromptDeploymentScript()
+	deploymentScript, err := PromptDeploymentScript(preset, defaults, project, scripts.Options{
+		ZeroDowntime: zeroDowntime["zero_downtime_deployments"].(bool),
+		RootDir:      repoSettings["root_dir"].(string),
+	})
 	if err != nil {
 		return nil, err
 	}
 
 	// Environment variables
-	environment, envFile, err := PromptEnvironmentVariables()
+	environment, envFile, err := PromptEnvironmentVariables(preset, defaults)
 	if err != nil {
 		return nil, err
 	}
 
 	// Processes
-	processes, err := PromptProcesses()
+	phpVersion, _ := phpSettings["php_version"].(string)
+	// Process names must not clash with the other sites' processes
+	taken := make(map[string]string)
+	for i, site := range config.Sites {
+		if i == siteNumber-1 {
+			continue
+		}
+		for _, process := range site.Processes {
+			taken[process.Name] = site.Name
+		}
+	}
+	processes, err := PromptProcesses(preset, defaults, phpVersion, taken)
 	if err != nil {
 		return nil, err
 	}
 
 	// Scheduler
-	scheduler, err := PromptScheduler()
+	scheduler, err := PromptScheduler(preset, defaults)
 	if err != nil {
 		return nil, err
 	}
 
-	// Aliases
-	aliases, err := PromptAliases()
+	// Scheduled jobs
+	scheduledJobs, err := PromptScheduledJobs(preset, defaults)
 	if err != nil {
 		return nil, err
 	}
 
-	// Nginx
-	nginxConfig, err := PromptNginxConfig()
+	// Aliases
+	aliases, err := PromptAliases(preset, defaults)
 	if err != nil {
 		return nil, err
 	}
 
-	// SSL
-	certificate, err := PromptSSLCertificate()
+	// Nginx
+	nginxConfig, err := PromptNginxConfig(preset, defaults)
 	if err != nil {
 		return nil, err
 	}
 
-	// Isolation
-	isolation, err := PromptIsolation()
+	// SSL
+	certificate, err := PromptSSLCertificate(preset, defaults)
 	if err != nil {
 		return nil, err
 	}
 
-	// Zero-downtime
-	zeroDowntime, err := PromptZeroDowntime()
+	// Isolation
+	isolation, err := PromptIsolation(preset, defaults)
 	if err != nil {
 		return nil, err
 	}
@@ -763,6 +1532,7 @@ func PromptCompleteSite(defaultBranch string, siteNumber int) (*models.SiteConfi
 		EnvFile:                     envFile,
 		Processes:                   processes,
 		LaravelScheduler:            scheduler,
+		ScheduledJobs:               scheduledJobs,
 		Aliases:                     aliases,
 		Certificate:                 certificate,
 		Isolated:                    isolation["isolated"].(bool),
@@ -797,7 +1567,36 @@ func PromptCompleteSite(defaultBranch string, siteNumber int) (*models.SiteConfi
 		site.SharedPaths = sharedPaths.([]models.SharedPath)
 	}
 
-	site.SetDefaults()
+	// An edited site keeps leaving out the defaults it left out, so that only
+	// changed keys move in the file
+	resolved := *site
+	resolved.SetDefaults()
+	if edited == nil {
+		site = &resolved
+	} else {
+		site.ClearDefaults(edited)
+	}
+
+	// Suggest APP_URL from the domain once it and the certificate are known
+	if resolved.ProjectType == "laravel" && site.Environment != "" && (preset == nil || preset.Environment == nil) {
+		if env := dotenv.Parse(site.Environment); !env.Has("APP_URL") {
+			add, err := askBool(nil, &survey.Confirm{
+				Message: fmt.Sprintf("The environment has no APP_URL. Add APP_URL=%s?", site.URL()),
+				Default: true,
+			})
+			if err != nil {
+				return nil, err
+			}
+			if add {
+				site.Environment = strings.TrimRight(site.Environment, "\n") + "\nAPP_URL=" + site.URL() + "\n"
+			}
+		}
+	}
 
 	return site, nil
 }
+
+// PromptConfirm asks a yes/no question. With input disabled the default is returned.
+func PromptConfirm(message string, def bool) (bool, error) {
+	return askBool(nil, &survey.Confirm{Message: message, Default: def})
+}
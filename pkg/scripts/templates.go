@@ -0,0 +1,171 @@
+// Package scripts provides a library of Forge deployment scripts.
+//
+// Scripts use the Forge macros ($FORGE_COMPOSER, $FORGE_PHP, $FORGE_PHP_FPM,
+// $FORGE_SITE_PATH, $FORGE_SITE_BRANCH) and, for zero-downtime sites, the
+// release macros $CREATE_RELEASE() and $ACTIVATE_RELEASE().
+package scripts
+
+import (
+	"fmt"
+	"path"
+	"strings"
+)
+
+// Options select the variant of a template
+type Options struct {
+	// ZeroDowntime builds the script in a new release directory and activates it
+	ZeroDowntime bool
+	// RootDir is the application directory inside the repository
+	RootDir string
+}
+
+// Template is a deployment script from the library
+type Template struct {
+	Name        string
+	Description string
+	// PHP sites reload PHP-FPM once the new code is live
+	PHP bool
+	// Build runs inside the new code before it goes live
+	Build []string
+	// After runs once the new code is live, e.g. to restart long-running processes
+	After []string
+}
+
+var (
+	composerInstall = "$FORGE_COMPOSER install --no-dev --no-interaction --prefer-dist --optimize-autoloader"
+	npmBuild        = "npm ci\nnpm run build"
+	laravelBuild    = []string{
+		composerInstall,
+		"$FORGE_PHP artisan migrate --force",
+		"$FORGE_PHP artisan optimize",
+	}
+	queueRestart = "$FORGE_PHP artisan queue:restart"
+)
+
+// Templates is the library of deployment scripts
+var Templates = []Template{
+	{
+		Name:        "laravel",
+		Description: "Composer install, migrations and caches",
+		PHP:         true,
+		Build:       laravelBuild,
+		After:       []string{queueRestart},
+	},
+	{
+		Name:        "laravel-npm",
+		Description: "Laravel plus an npm asset build",
+		PHP:         true,
+		Build:       append(append([]string{}, laravelBuild[0], npmBuild), laravelBuild[1:]...),
+		After:       []string{queueRestart},
+	},
+	{
+		Name:        "laravel-octane",
+		Description: "Laravel served by Octane, reloaded after deploying",
+		PHP:         true,
+		Build:       laravelBuild,
+		After:       []string{queueRestart, "$FORGE_PHP artisan octane:reload"},
+	},
+	{
+		Name:        "laravel-horizon",
+		Description: "Laravel with Horizon, terminated so it restarts on the new code",
+		PHP:         true,
+		Build:       laravelBuild,
+		After:       []string{"$FORGE_PHP artisan horizon:terminate"},
+	},
+	{
+		Name:        "statamic",
+		Description: "Statamic: assets, caches, Stache and static cache warming",
+		PHP:         true,
+		Build: []string{
+			composerInstall,
+			npmBuild,
+			"$FORGE_PHP artisan optimize",
+			"$FORGE_PHP artisan statamic:stache:warm",
+			"$FORGE_PHP artisan statamic:search:update --all",
+		},
+		After: []string{
+			"$FORGE_PHP artisan statamic:static:clear",
+			"$FORGE_PHP artisan statamic:static:warm --queue",
+		},
+	},
+	{
+		Name:        "wordpress-bedrock",
+		Description: "WordPress installed with Composer (Roots Bedrock)",
+		PHP:         true,
+		Build:       []string{composerInstall},
+	},
+	{
+		Name:        "static",
+		Description: "Static site, optionally built with npm",
+		Build:       []string{"# " + strings.ReplaceAll(npmBuild, "\n", "\n# ")},
+	},
+}
+
+// Names returns the names of the templates in library order
+func Names() []string {
+	names := make([]string, len(Templates))
+	for i, t := range Templates {
+		names[i] = t.Name
+	}
+	return names
+}
+
+// Find returns the template with the given name
+func Find(name string) (*Template, bool) {
+	for i := range Templates {
+		if Templates[i].Name == name {
+			return &Templates[i], true
+		}
+	}
+	return nil, false
+}
+
+const fpmReload = `( flock -w 10 9 || exit 1
+    echo 'Restarting FPM...'; sudo -S service $FORGE_PHP_FPM reload ) 9>/tmp/fpmlock`
+
+// Script renders the template for a site
+func (t *Template) Script(opts Options) string {
+	var sections []string
+
+	if opts.ZeroDowntime {
+		sections = append(sections, "set -e", "$CREATE_RELEASE()", "cd $FORGE_RELEASE_DIRECTORY"+subdir(opts.RootDir))
+	} else {
+		checkout := "cd $FORGE_SITE_PATH\ngit pull origin $FORGE_SITE_BRANCH"
+		if dir := subdir(opts.RootDir); dir != "" {
+			checkout += "\ncd " + dir[1:]
+		}
+		sections = append(sections, "set -e", checkout)
+	}
+
+	sections = append(sections, strings.Join(t.Build, "\n"))
+
+	if opts.ZeroDowntime {
+		sections = append(sections, "$ACTIVATE_RELEASE()")
+	}
+	if t.PHP {
+		sections = append(sections, fpmReload)
+	}
+	if len(t.After) > 0 {
+		sections = append(sections, strings.Join(t.After, "\n"))
+	}
+
+	return strings.Join(sections, "\n\n") + "\n"
+}
+
+// subdir returns the path to append to the site directory to reach rootDir
+func subdir(rootDir string) string {
+	rootDir = path.Clean(rootDir)
+	if rootDir == "." || rootDir == "" {
+		return ""
+	}
+	return "/" + rootDir
+}
+
+// Render renders the named template
+func Render(name string, opts Options) (string, error) {
+	t, ok := Find(name)
+	if !ok {
+		return "", fmt.Errorf("unknown deployment script template %q (must be one of: %s)", name, strings.Join(Names(), ", "))
+	}
+	return t.Script(opts), nil
+}
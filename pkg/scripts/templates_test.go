@@ -0,0 +1,37 @@
+package scripts
+
+import (
+	"testing"
+
+	"github.com/the-trybe/forge-deploy-cli/pkg/lint"
+)
+
+func TestTemplatesAreLintClean(t *testing.T) {
+	for _, name := range Names() {
+		for _, options := range []Options{
+			{},
+			{ZeroDowntime: true},
+			{RootDir: "backend"},
+			{RootDir: "apps/api/", ZeroDowntime: true},
+		} {
+			script, err := Render(name, options)
+			if err != nil {
+				t.Fatalf("%s %+v: %v", name, options, err)
+			}
+
+			rootDir := options.RootDir
+			if rootDir == "" {
+				rootDir = "."
+			}
+			for _, finding := range lint.Script(script, options.ZeroDowntime, rootDir) {
+				t.Errorf("%s %+v: %s: %s\n%s", name, options, finding.Severity, finding, script)
+			}
+		}
+	}
+}
+
+func TestRenderUnknownTemplate(t *testing.T) {
+	if _, err := Render("rails", Options{}); err == nil {
+		t.Error("got no error for an unknown template")
+	}
+}
@@ -0,0 +1,175 @@
+// Package secrets detects values in environment files that look like real
+// credentials, so they can be replaced with placeholders before being committed.
+package secrets
+
+import (
+	"fmt"
+	"math"
+	"regexp"
+	"strings"
+
+	"github.com/the-trybe/forge-deploy-cli/pkg/dotenv"
+)
+
+// Finding is an environment variable whose value looks like a real secret.
+// Line and EndLine span the whole entry, which may be a multiline value.
+type Finding struct {
+	Line    int
+	EndLine int
+	Key     string
+	Reason  string
+	// Quote is the quote around the value, or 0 when it is unquoted
+	Quote byte
+}
+
+func (f Finding) String() string {
+	return fmt.Sprintf("line %d: %s looks like %s", f.Line, f.Key, f.Reason)
+}
+
+// Placeholder returns the placeholder that replaces the value of key
+func (f Finding) Placeholder() string {
+	return "${" + f.Key + "}"
+}
+
+var (
+	placeholder = regexp.MustCompile(`^\$\{[A-Za-z_][A-Za-z0-9_]*\}$`)
+
+	patterns = []struct {
+		pattern *regexp.Regexp
+		reason  string
+	}{
+		{regexp.MustCompile(`^base64:[A-Za-z0-9+/]{20,}={0,2}$`), "a Laravel application key"},
+		{regexp.MustCompile(`\b(?:AKIA|ASIA)[0-9A-Z]{16}\b`), "an AWS access key ID"},
+		{regexp.MustCompile(`\b(?:sk|rk)_(?:live|test)_[0-9A-Za-z]{16,}\b`), "a Stripe secret key"},
+		{regexp.MustCompile(`\bwhsec_[0-9A-Za-z]{16,}\b`), "a Stripe webhook secret"},
+		{regexp.MustCompile(`\bkey-[0-9a-f]{32}\b`), "a Mailgun API key"},
+		{regexp.MustCompile(`\b[0-9a-f]{32}-[0-9a-f]{8}-[0-9a-f]{8}\b`), "a Mailgun API key"},
+		{regexp.MustCompile(`^[a-z][a-z0-9+.-]*://[^:/@\s]+:[^@/\s]+@`), "a URL with embedded credentials"},
+	}
+
+	awsSecretKey  = regexp.MustCompile(`^[A-Za-z0-9/+=]{40}$`)
+	sensitiveName = regexp.MustCompile(`(?i)(PASSWORD|PASSWD|PASS$|PWD|SECRET|TOKEN|PRIVATE_KEY|API_KEY|CREDENTIALS)`)
+
+	// dummyValues are common example values that are not worth flagging
+	dummyValues = map[string]bool{
+		"": true, "null": true, "none": true, "changeme": true, "change-me": true,
+		"secret": true, "password": true, "example": true, "xxx": true,
+	}
+)
+
+// Scan returns the variables of an environment whose values look like real secrets.
+// Values that are already placeholders are skipped.
+func Scan(env string) []Finding {
+	var findings []Finding
+	for _, entry := range dotenv.Parse(env).Entries {
+		if reason := classify(entry.Key, entry.Value); reason != "" {
+			findings = append(findings, Finding{Line: entry.Line, EndLine: entry.EndLine, Key: entry.Key, Reason: reason, Quote: entry.Quote})
+		}
+	}
+	return findings
+}
+
+// Replace swaps the value of every finding for its placeholder. Quoted values
+// stay quoted, so the secret filled in later may contain spaces or "#". Single
+// quotes become double quotes, since a single-quoted ${KEY} is not a reference.
+func Replace(env string, findings []Finding) string {
+	lines := strings.Split(env, "\n")
+	replaced := make(map[int]string)
+	skip := make(map[int]bool)
+	for _, f := range findings {
+		if f.Line < 1 || f.EndLine > len(lines) {
+			continue
+		}
+		line := lines[f.Line-1]
+		if i := strings.Index(line, "="); i >= 0 {
+			placeholder := f.Placeholder()
+			if f.Quote != 0 {
+				placeholder = `"` + placeholder + `"`
+			}
+			replaced[f.Line-1] = line[:i+1] + placeholder
+			for n := f.Line; n < f.EndLine; n++ {
+				skip[n] = true
+			}
+		}
+	}
+
+	var out []string
+	for i, line := range lines {
+		if skip[i] {
+			continue
+		}
+		if r, ok := replaced[i]; ok {
+			line = r
+		}
+		out = append(out, line)
+	}
+	return strings.Join(out, "\n")
+}
+
+// Sensitive reports whether a variable should be kept out of git, either
+// because of its name (passwords, tokens, keys) or because its value looks
+// like a real secret. Unlike Scan it also flags empty and example values.
+func Sensitive(key, value string) bool {
+	return key == "APP_KEY" || sensitiveName.MatchString(key) || classify(key, value) != ""
+}
+
+// classify returns why a value looks like a secret, or an empty string
+func classify(key, value string) string {
+	if placeholder.MatchString(value) || dummyValues[strings.ToLower(value)] {
+		return ""
+	}
+	if strings.Contains(value, "${") {
+		// Values interpolating other variables are not literal secrets
+		return ""
+	}
+
+	for _, p := range patterns {
+		if p.pattern.MatchString(value) {
+			return p.reason
+		}
+	}
+
+	upper := strings.ToUpper(key)
+	if strings.Contains(upper, "AWS") && strings.Contains(upper, "SECRET") && awsSecretKey.MatchString(value) {
+		return "an AWS secret access key"
+	}
+	if sensitiveName.MatchString(key) {
+		if strings.HasPrefix(upper, "DB_") || strings.HasPrefix(upper, "DATABASE_") {
+			return "a database password"
+		}
+		return "a password or token"
+	}
+	if highEntropy(value) {
+		return "a high-entropy value (random key or token)"
+	}
+	return ""
+}
+
+// highEntropy reports whether a value is long and random enough to be a generated key
+func highEntropy(value string) bool {
+	if len(value) < 20 || strings.ContainsAny(value, " \t") || strings.Contains(value, "://") || strings.HasPrefix(value, "/") {
+		return false
+	}
+
+	var classes int
+	for _, set := range []string{"abcdefghijklmnopqrstuvwxyz", "ABCDEFGHIJKLMNOPQRSTUVWXYZ", "0123456789"} {
+		if strings.ContainsAny(value, set) {
+			classes++
+		}
+	}
+	if classes < 2 {
+		return false
+	}
+
+	counts := make(map[rune]int)
+	for _, r := range value {
+		counts[r]++
+	}
+	var entropy float64
+	n := float64(len([]rune(value)))
+	for _, c := range counts {
+		p := float64(c) / n
+		entropy -= p * math.Log2(p)
+	}
+	return entropy >= 4.0
+}
@@ -0,0 +1,218 @@
+package validate
+
+import (
+	"fmt"
+	"path"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/the-trybe/forge-deploy-cli/pkg/cron"
+	"github.com/the-trybe/forge-deploy-cli/pkg/lint"
+	"github.com/the-trybe/forge-deploy-cli/pkg/models"
+	"github.com/the-trybe/forge-deploy-cli/pkg/processes"
+	"github.com/the-trybe/forge-deploy-cli/pkg/secrets"
+)
+
+// siteIssue builds an issue about a field of the site at index
+func siteIssue(cfg *models.DeploymentConfig, index int, severity Severity, code, field, format string, args ...interface{}) Issue {
+	return Issue{Issue: models.Issue{
+		Field:    fmt.Sprintf("sites[%d].%s", index, field),
+		Code:     code,
+		Severity: severity,
+		Message:  fmt.Sprintf("Site %d (%s): %s", index+1, cfg.Sites[index].Name, fmt.Sprintf(format, args...)),
+	}}
+}
+
+// checkSite runs the semantic checks that go beyond SiteConfig.Validate
+func checkSite(cfg *models.DeploymentConfig, index int) []Issue {
+	site := &cfg.Sites[index]
+	var issues []Issue
+
+	// add returns the issue so a fix can be set on it
+	add := func(severity Severity, code, field, format string, args ...interface{}) *Issue {
+		issues = append(issues, siteIssue(cfg, index, severity, code, field, format, args...))
+		return &issues[len(issues)-1]
+	}
+
+	if site.Environment != "" && site.EnvFile != "" {
+		add(SeverityWarning, "conflicting-settings", "env_file", "both environment and env_file are set; only one should be used")
+	}
+
+	if site.NginxTemplate != "" && site.NginxCustomConfig != "" {
+		add(SeverityWarning, "conflicting-settings", "nginx_custom_config", "both nginx_template and nginx_custom_config are set; only one should be used")
+	}
+
+	if len(site.NginxTemplateVariables) > 0 && site.NginxTemplate == "" {
+		add(SeverityWarning, "ignored-setting", "nginx_template_variables", "nginx_template_variables are ignored without nginx_template")
+	}
+
+	if len(site.SharedPaths) > 0 && !site.ZeroDowntimeDeployments {
+		add(SeverityWarning, "ignored-setting", "shared_paths", "shared_paths are ignored unless zero_downtime_deployments is enabled")
+	}
+
+	if site.IsolatedUser != "" && !site.Isolated {
+		add(SeverityWarning, "ignored-setting", "isolated_user", "isolated_user is ignored unless isolated is true")
+	}
+
+	if site.LaravelScheduler && site.ProjectType == "other" {
+		add(SeverityWarning, "scheduler-without-laravel", "laravel_scheduler", "laravel_scheduler is enabled on a site whose project_type is 'other'")
+	}
+
+	for _, dir := range []struct{ field, value string }{
+		{"root_dir", site.RootDir},
+		{"web_dir", site.WebDir},
+	} {
+		if path.IsAbs(dir.value) {
+			add(SeverityError, "absolute-path", dir.field, "%s must be relative to the repository root", dir.field)
+		} else if dir.value != "" && strings.HasPrefix(path.Clean(dir.value), "..") {
+			add(SeverityError, "path-outside-repository", dir.field, "%s must not point outside the repository", dir.field)
+		}
+	}
+
+	processNames := make(map[string]bool)
+	for i, process := range site.Processes {
+		field := fmt.Sprintf("processes[%d]", i)
+		if process.Name == "" {
+			add(SeverityError, "required", field+".name", "process %d has no name", i+1)
+		} else if processNames[process.Name] {
+			add(SeverityError, "duplicate-process-name", field+".name", "process name %q is used more than once", process.Name)
+		}
+		processNames[process.Name] = true
+
+		if strings.TrimSpace(process.Command) == "" {
+			add(SeverityError, "required", field+".command", "process %d has no command", i+1)
+		}
+
+		issues = append(issues, checkProcess(cfg, index, i)...)
+	}
+
+	issues = append(issues, checkScheduledJobs(cfg, index)...)
+
+	if site.WWWRedirectType == "from-www" && site.DomainMode == "custom" && strings.HasPrefix(strings.ToLower(site.Name), "www.") {
+		add(SeverityWarning, "www-redirect-direction", "www_redirect_type", "from-www redirects to %s, but the site name starts with www.", site.WWWVariant()).
+			Fix = "set www_redirect_type: to-www, or drop the www. prefix from the name"
+	}
+
+	aliases := make(map[string]bool)
+	for i, alias := range site.Aliases {
+		field := fmt.Sprintf("aliases[%d]", i)
+		if strings.EqualFold(alias, site.Name) {
+			add(SeverityInfo, "redundant-alias", field, "alias %q is the same as the site name", alias).Fix = "remove the alias"
+		} else if aliases[strings.ToLower(alias)] {
+			add(SeverityWarning, "duplicate-alias", field, "alias %q is listed more than once", alias).Fix = "remove the alias"
+		} else if www := site.WWWVariant(); www != "" && strings.EqualFold(alias, www) {
+			add(SeverityInfo, "redundant-alias", field, "alias %q is already served by www_redirect_type %s", alias, site.WWWRedirectType).Fix = "remove the alias"
+		}
+		aliases[strings.ToLower(alias)] = true
+
+		if strings.HasPrefix(alias, "*.") && site.Certificate {
+			add(SeverityWarning, "wildcard-certificate", field, "Let's Encrypt only issues certificates for wildcard alias %q through a DNS challenge; the certificate request will fail without one", alias)
+		}
+	}
+
+	// Errors are reported by SiteConfig.Validate
+	for _, finding := range lint.Script(site.DeploymentScript, site.ZeroDowntimeDeployments, site.RootDir) {
+		if finding.Severity == lint.Warning && site.DeploymentScript != "" {
+			add(SeverityWarning, finding.Code, "deployment_script", "%s", finding)
+		}
+	}
+
+	for _, finding := range secrets.Scan(site.Environment) {
+		add(SeverityWarning, "secret-in-environment", "environment", "environment %s", finding).
+			Fix = fmt.Sprintf("replace the value with %s and add a GitHub secret", finding.Placeholder())
+	}
+
+	return issues
+}
+
+var artisanTimeout = regexp.MustCompile(`--timeout[= ](\d+)`)
+
+// checkProcess checks that a process's daemon options fit its site
+func checkProcess(cfg *models.DeploymentConfig, siteIndex, index int) []Issue {
+	site := &cfg.Sites[siteIndex]
+	process := site.Processes[index]
+	var issues []Issue
+
+	add := func(code, field, format string, args ...interface{}) *Issue {
+		field = fmt.Sprintf("processes[%d].%s", index, field)
+		message := fmt.Sprintf("process %d (%s): %s", index+1, process.Name, fmt.Sprintf(format, args...))
+		issues = append(issues, siteIssue(cfg, siteIndex, SeverityWarning, code, field, "%s", message))
+		return &issues[len(issues)-1]
+	}
+
+	// An invalid php_version has no binary to compare with, and is reported by SiteConfig.Validate
+	if binary, ok := processes.Binary(process.Command); ok && binary != "php" {
+		if want := processes.PHPBinary(site.PHPVersion); want != "php" && binary != want {
+			add("php-version-mismatch", "command", "runs %s but php_version is %s", binary, site.PHPVersion).Fix = "run " + want
+		}
+	}
+
+	user := "forge"
+	if site.Isolated && site.IsolatedUser != "" {
+		user = site.IsolatedUser
+	}
+	switch {
+	case process.User == "root":
+		add("runs-as-root", "user", "runs as root")
+	case site.Isolated && process.User != "" && process.User != user:
+		add("user-not-isolated-user", "user", "runs as %s, not as the site's isolated user %s", process.User, user)
+	}
+
+	home := path.Join("/home", user, site.Domain())
+	if dir := path.Clean(process.Directory); path.IsAbs(dir) && dir != home && !strings.HasPrefix(dir, home+"/") {
+		add("directory-outside-site", "directory", "directory %s is outside the site directory %s", process.Directory, home)
+	}
+
+	if m := artisanTimeout.FindStringSubmatch(process.Command); m != nil && process.StopWaitSecs > 0 {
+		if timeout, _ := strconv.Atoi(m[1]); process.StopWaitSecs < timeout {
+			add("stopwaitsecs-below-timeout", "stopwaitsecs", "stopwaitsecs %d is lower than --timeout=%d, so jobs can be killed while running", process.StopWaitSecs, timeout).
+				Fix = fmt.Sprintf("set stopwaitsecs to at least %d", timeout)
+		}
+	}
+
+	return issues
+}
+
+// checkScheduledJobs warns about scheduled jobs that duplicate the Laravel
+// scheduler or each other, never run, or run as an unexpected user
+func checkScheduledJobs(cfg *models.DeploymentConfig, index int) []Issue {
+	site := &cfg.Sites[index]
+	var issues []Issue
+
+	seen := make(map[string]bool)
+	for i, job := range site.ScheduledJobs {
+		add := func(code, field, format string, args ...interface{}) *Issue {
+			field = fmt.Sprintf("scheduled_jobs[%d]%s", i, field)
+			message := fmt.Sprintf("scheduled job %d: %s", i+1, fmt.Sprintf(format, args...))
+			issues = append(issues, siteIssue(cfg, index, SeverityWarning, code, field, "%s", message))
+			return &issues[len(issues)-1]
+		}
+
+		if strings.Contains(job.Command, "artisan schedule:run") {
+			add("scheduler-as-job", ".command", "runs the Laravel scheduler").Fix = "set laravel_scheduler: true and remove the job"
+		}
+
+		key := job.Expression() + "\x00" + job.Command
+		if seen[key] {
+			add("duplicate-job", "", "%q is scheduled more than once at the same time", job.Command)
+		}
+		seen[key] = true
+
+		if expr := job.Expression(); expr != "" {
+			if schedule, err := cron.Parse(expr); err == nil && schedule.Next(time.Now()).IsZero() {
+				add("never-runs", ".cron", "%q never runs: no date matches it", expr)
+			}
+		}
+
+		switch {
+		case job.User == "root":
+			add("runs-as-root", ".user", "runs as root")
+		case site.Isolated && site.IsolatedUser != "" && job.User != "" && job.User != site.IsolatedUser:
+			add("user-not-isolated-user", ".user", "runs as %s, not as the site's isolated user %s", job.User, site.IsolatedUser)
+		}
+	}
+
+	return issues
+}
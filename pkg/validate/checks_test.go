@@ -0,0 +1,39 @@
+package validate
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/the-trybe/forge-deploy-cli/pkg/models"
+)
+
+func TestCheckProcessPHPVersion(t *testing.T) {
+	tests := []struct {
+		name       string
+		phpVersion string
+		command    string
+		want       []issueSummary
+	}{
+		{"matching", "php83", "php8.3 artisan horizon", nil},
+		{"bare php", "php83", "php artisan horizon", nil},
+		{"no artisan", "php83", "php8.2 worker.php", nil},
+		{"mismatch", "php83", "/usr/bin/php8.2 artisan horizon", []issueSummary{
+			{"sites[0].processes[0].command", "php-version-mismatch", SeverityWarning, "run php8.3"},
+		}},
+		{"no php_version", "", "php8.2 artisan horizon", nil},
+		{"invalid php_version", "8.3", "php8.2 artisan horizon", nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := nginxSite(models.SiteConfig{
+				PHPVersion: tt.phpVersion,
+				Processes:  []models.Process{{Name: "horizon", Command: tt.command}},
+			})
+			got := summarize(checkProcess(cfg, 0, 0))
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got %+v\nwant %+v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,87 @@
+package validate
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/the-trybe/forge-deploy-cli/pkg/dotenv"
+	"github.com/the-trybe/forge-deploy-cli/pkg/models"
+)
+
+// EnvTemplate is the file a site's environment is compared with, relative to its root_dir
+const EnvTemplate = ".env.example"
+
+// checkEnvironment parses a site's environment and compares it with the
+// project's .env.example
+func checkEnvironment(cfg *models.DeploymentConfig, index int, opts Options) []Issue {
+	site := &cfg.Sites[index]
+	var issues []Issue
+
+	add := func(severity Severity, code, field, format string, args ...interface{}) *Issue {
+		issues = append(issues, siteIssue(cfg, index, severity, code, field, format, args...))
+		return &issues[len(issues)-1]
+	}
+
+	field, content := "environment", site.Environment
+	if site.Environment == "" && site.EnvFile != "" {
+		if opts.Dir == "" {
+			return nil
+		}
+		field = "env_file"
+		data, err := os.ReadFile(filepath.Join(opts.Dir, site.EnvFile))
+		if err != nil {
+			add(SeverityError, "unreadable-file", field, "env_file %s cannot be read: %v", site.EnvFile, unwrapPathError(err))
+			return issues
+		}
+		content = string(data)
+	}
+	if content == "" {
+		return nil
+	}
+
+	env := dotenv.Parse(content)
+
+	for _, e := range env.Errors {
+		add(SeverityError, "invalid-dotenv", field, "%s %s", field, e)
+	}
+
+	firstLine := make(map[string]int)
+	for _, e := range env.Entries {
+		if _, ok := firstLine[e.Key]; !ok {
+			firstLine[e.Key] = e.Line
+		}
+	}
+	for _, e := range env.Duplicates() {
+		add(SeverityWarning, "duplicate-env-key", field, "%s line %d: %s is already defined on line %d", field, e.Line, e.Key, firstLine[e.Key])
+	}
+
+	if opts.Dir != "" {
+		templatePath := filepath.Join(opts.Dir, site.RootDir, EnvTemplate)
+		if data, err := os.ReadFile(templatePath); err == nil {
+			if missing := env.Missing(dotenv.Parse(string(data))); len(missing) > 0 {
+				add(SeverityWarning, "missing-env-keys", field, "%s is missing %d key(s) from %s: %s", field, len(missing), EnvTemplate, strings.Join(missing, ", "))
+			}
+		}
+	}
+
+	if site.ProjectType == "" || site.ProjectType == "laravel" {
+		if !env.Has("APP_KEY") {
+			add(SeverityError, "missing-app-key", field, "%s has no APP_KEY", field).
+				Fix = "generate one with 'php artisan key:generate --show' and reference it as ${APP_KEY}"
+		}
+		if !env.Has("APP_URL") {
+			add(SeverityWarning, "missing-app-url", field, "%s has no APP_URL", field).Fix = "add APP_URL=" + site.URL()
+		}
+	}
+
+	return issues
+}
+
+// unwrapPathError drops the path from an *os.PathError, which the message already names
+func unwrapPathError(err error) error {
+	if pathErr, ok := err.(*os.PathError); ok {
+		return pathErr.Err
+	}
+	return err
+}
@@ -0,0 +1,268 @@
+package validate
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/the-trybe/forge-deploy-cli/pkg/models"
+	"github.com/the-trybe/forge-deploy-cli/pkg/nginx"
+)
+
+// checkNginxTemplate compares a site's nginx_template_variables with the
+// placeholders of its template in the local templates directory
+func checkNginxTemplate(cfg *models.DeploymentConfig, index int, opts Options) []Issue {
+	site := &cfg.Sites[index]
+	if site.NginxTemplate == "" || opts.NginxTemplates == "" {
+		return nil
+	}
+
+	var issues []Issue
+	add := func(severity Severity, code, field, format string, args ...interface{}) *Issue {
+		issues = append(issues, siteIssue(cfg, index, severity, code, field, format, args...))
+		return &issues[len(issues)-1]
+	}
+
+	dir := opts.NginxTemplates
+	if !filepath.IsAbs(dir) {
+		dir = filepath.Join(opts.Dir, dir)
+	}
+	templates, err := nginx.LoadTemplates(dir)
+	if err != nil {
+		add(SeverityError, "unreadable-file", "nginx_template", "nginx templates cannot be read: %v", unwrapPathError(err))
+		return issues
+	}
+	if len(templates) == 0 {
+		// Without a template there is nothing to compare the variables with
+		if len(site.NginxTemplateVariables) > 0 {
+			add(SeverityWarning, "nginx-templates-not-found", "nginx_template_variables",
+				"nginx_template_variables are not checked: there are no %s templates in %s", nginx.TemplateExt, opts.NginxTemplates).
+				Fix = fmt.Sprintf("add a copy of template %s as %s", site.NginxTemplate, filepath.Join(opts.NginxTemplates, site.NginxTemplate+nginx.TemplateExt))
+		}
+		return issues
+	}
+
+	template, ok := nginx.Find(templates, site.NginxTemplate)
+	if !ok {
+		add(SeverityWarning, "unknown-nginx-template", "nginx_template", "nginx_template %q is not in %s (%s); its variables are not checked",
+			site.NginxTemplate, opts.NginxTemplates, strings.Join(templateNames(templates), ", "))
+		return issues
+	}
+
+	var used []string
+	for _, p := range template.Variables() {
+		used = append(used, p.Name)
+	}
+
+	missing, unknown := template.Check(site.NginxTemplateVariables)
+	for _, p := range missing {
+		issue := add(SeverityError, "missing-template-variable", "nginx_template_variables",
+			"nginx template %s line %d uses {{%s}}, which nginx_template_variables does not set", template.Name, p.Line, p.Name)
+		if forge := nginx.Suggest(p.Name, nginx.ForgeVariableNames()); forge != "" {
+			issue.Fix = fmt.Sprintf("did you mean the Forge variable {{%s}}?", forge)
+		}
+	}
+	for _, name := range unknown {
+		if _, forge := nginx.ForgeVariables[name]; forge {
+			add(SeverityWarning, "unknown-template-variable", mapKey("nginx_template_variables", name),
+				"nginx_template_variables sets %s, which is filled in by Forge", name).Fix = "remove " + name
+			continue
+		}
+		issue := add(SeverityWarning, "unknown-template-variable", mapKey("nginx_template_variables", name),
+			"nginx_template_variables sets %s, which template %s does not use", name, template.Name)
+		if suggestion := nginx.Suggest(name, used); suggestion != "" {
+			issue.Fix = fmt.Sprintf("did you mean %s?", suggestion)
+		}
+	}
+
+	return issues
+}
+
+// mapKey returns the path of a key of a map field, e.g.
+// nginx_template_variables["app.host"]. The key is quoted since it may
+// contain dots.
+func mapKey(field, key string) string {
+	return field + "[" + strconv.Quote(key) + "]"
+}
+
+// templateNames lists the names of templates, sorted
+func templateNames(templates []nginx.Template) []string {
+	names := make([]string, len(templates))
+	for i, t := range templates {
+		names[i] = t.Name
+	}
+	sort.Strings(names)
+	return names
+}
+
+// serverOnlyDirectives are common directives that nginx rejects outside a
+// server or location block
+var serverOnlyDirectives = map[string]bool{
+	"location": true, "listen": true, "server_name": true, "root": true, "index": true,
+	"try_files": true, "fastcgi_pass": true, "proxy_pass": true, "return": true,
+	"rewrite": true, "if": true, "ssl_certificate": true, "ssl_certificate_key": true,
+}
+
+// checkNginxCustomConfig parses a site's nginx_custom_config and checks that
+// its server blocks answer for the site's domain and aliases
+func checkNginxCustomConfig(cfg *models.DeploymentConfig, index int, opts Options) []Issue {
+	site := &cfg.Sites[index]
+	if site.NginxCustomConfig == "" {
+		return nil
+	}
+
+	var issues []Issue
+	add := func(severity Severity, code, format string, args ...interface{}) {
+		issues = append(issues, siteIssue(cfg, index, severity, code, "nginx_custom_config", format, args...))
+	}
+
+	if filepath.IsAbs(site.NginxCustomConfig) {
+		add(SeverityError, "absolute-path", "nginx_custom_config must be relative to the repository root")
+		return issues
+	}
+	if strings.HasPrefix(filepath.ToSlash(filepath.Clean(site.NginxCustomConfig)), "..") {
+		add(SeverityError, "path-outside-repository", "nginx_custom_config must not point outside the repository")
+		return issues
+	}
+	if opts.Dir == "" {
+		return issues
+	}
+
+	file := filepath.Join(opts.Dir, site.NginxCustomConfig)
+	data, err := os.ReadFile(file)
+	if err != nil {
+		add(SeverityError, "unreadable-file", "nginx_custom_config %s cannot be read: %v", site.NginxCustomConfig, unwrapPathError(err))
+		return issues
+	}
+
+	// Issues in the file itself point at the file and line
+	addAt := func(severity Severity, code string, line int, format string, args ...interface{}) {
+		issue := siteIssue(cfg, index, severity, code, "nginx_custom_config", format, args...)
+		issue.File, issue.Line = file, line
+		issues = append(issues, issue)
+	}
+
+	directives, err := nginx.Parse(string(data))
+	if err != nil {
+		if syntax, ok := err.(*nginx.SyntaxError); ok {
+			addAt(SeverityError, "nginx-syntax", syntax.Line, "nginx_custom_config: %s", syntax.Message)
+		} else {
+			add(SeverityError, "nginx-syntax", "nginx_custom_config: %v", err)
+		}
+		return issues
+	}
+
+	servers := 0
+	for _, d := range directives {
+		switch {
+		case d.Name == "server" && d.HasBlock:
+			servers++
+		case serverOnlyDirectives[d.Name]:
+			addAt(SeverityError, "nginx-directive-context", d.Line, "nginx_custom_config: %q is not allowed at the top level; move it into a server block", d.Name)
+		case !nginx.TopLevelDirectives[d.Name]:
+			addAt(SeverityWarning, "unknown-nginx-directive", d.Line, "nginx_custom_config: unknown top-level directive %q", d.Name)
+		}
+	}
+	if servers == 0 {
+		addAt(SeverityWarning, "missing-server-block", 1, "nginx_custom_config defines no server block")
+		return issues
+	}
+
+	for _, issue := range checkServerNames(site, directives) {
+		addAt(issue.severity, issue.code, issue.line, "nginx_custom_config: %s", issue.message)
+	}
+
+	return issues
+}
+
+type serverNameIssue struct {
+	severity Severity
+	code     string
+	line     int
+	message  string
+}
+
+// checkServerNames compares the server_name directives of the server blocks
+// with the names the site answers for
+func checkServerNames(site *models.SiteConfig, directives []nginx.Directive) []serverNameIssue {
+	expected := map[string]string{strings.ToLower(site.Domain()): "the site's domain"}
+	if www := site.WWWVariant(); www != "" {
+		expected[strings.ToLower(www)] = "the www redirect"
+	}
+	for _, alias := range site.Aliases {
+		expected[strings.ToLower(alias)] = "an alias"
+	}
+
+	var issues []serverNameIssue
+	found := make(map[string]bool)
+	firstServer := 0
+	nginx.Walk(directives, func(d nginx.Directive, parents []string) {
+		if d.Name == "server" && d.HasBlock && firstServer == 0 {
+			firstServer = d.Line
+		}
+		if d.Name != "server_name" || len(parents) != 1 || parents[0] != "server" {
+			return
+		}
+		for _, name := range d.Args {
+			name = strings.ToLower(name)
+			if name == "_" || name == "" || strings.HasPrefix(name, "~") {
+				continue
+			}
+			// ".example.com" answers for example.com and *.example.com
+			matches := []string{name}
+			if base, ok := strings.CutPrefix(name, "."); ok {
+				matches = []string{base, "*" + name}
+			}
+			known := false
+			for _, match := range matches {
+				found[match] = true
+				if _, ok := expected[match]; ok {
+					known = true
+				}
+			}
+			if !known {
+				issues = append(issues, serverNameIssue{SeverityWarning, "unexpected-server-name", d.Line,
+					fmt.Sprintf("server_name %s is neither the site's domain nor one of its aliases", name)})
+			}
+		}
+	})
+
+	names := make([]string, 0, len(expected))
+	for name := range expected {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		if answers(found, name) {
+			continue
+		}
+		severity := SeverityWarning
+		if expected[name] == "the site's domain" {
+			severity = SeverityError
+		}
+		issues = append(issues, serverNameIssue{severity, "missing-server-name", firstServer,
+			fmt.Sprintf("no server_name answers for %s (%s)", name, expected[name])})
+	}
+	return issues
+}
+
+// answers reports whether the server names found answer for name, exactly or
+// through a wildcard such as *.example.com, which nginx matches at any depth
+func answers(found map[string]bool, name string) bool {
+	if found[name] {
+		return true
+	}
+	for rest := name; ; {
+		_, parent, ok := strings.Cut(rest, ".")
+		if !ok || !strings.Contains(parent, ".") {
+			return false
+		}
+		if found["*."+parent] {
+			return true
+		}
+		rest = parent
+	}
+}
@@ -0,0 +1,157 @@
+package validate
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/the-trybe/forge-deploy-cli/pkg/models"
+)
+
+// writeFiles writes files, keyed by their path relative to dir
+func writeFiles(t *testing.T, dir string, files map[string]string) {
+	t.Helper()
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+// nginxSite returns a configuration with a single site
+func nginxSite(site models.SiteConfig) *models.DeploymentConfig {
+	site.Name = "app.example.com"
+	site.DomainMode = "custom"
+	return &models.DeploymentConfig{Sites: []models.SiteConfig{site}}
+}
+
+// issueSummary is the part of an issue the nginx tests compare
+type issueSummary struct {
+	Field    string
+	Code     string
+	Severity Severity
+	Fix      string
+}
+
+func summarize(issues []Issue) []issueSummary {
+	var out []issueSummary
+	for _, issue := range issues {
+		out = append(out, issueSummary{issue.Field, issue.Code, issue.Severity, issue.Fix})
+	}
+	return out
+}
+
+func TestCheckNginxTemplate(t *testing.T) {
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{
+		".forge/nginx-templates/octane.conf": "server {\n    server_name {{DOMAINS}};\n    proxy_pass http://127.0.0.1:{{OCTANE_PORT}};\n}\n",
+	})
+	opts := Options{Dir: dir, NginxTemplates: ".forge/nginx-templates"}
+
+	tests := []struct {
+		name      string
+		template  string
+		variables map[string]string
+		opts      Options
+		want      []issueSummary
+	}{
+		{"all set", "octane", map[string]string{"OCTANE_PORT": "8000"}, opts, nil},
+		{"no template", "", nil, opts, nil},
+		{"templates not checked", "octane", nil, Options{Dir: dir}, nil},
+		{"missing", "octane", nil, opts, []issueSummary{
+			{"sites[0].nginx_template_variables", "missing-template-variable", SeverityError, ""},
+		}},
+		{"typo", "octane", map[string]string{"OCTANE_PROT": "8000"}, opts, []issueSummary{
+			{"sites[0].nginx_template_variables", "missing-template-variable", SeverityError, ""},
+			{`sites[0].nginx_template_variables["OCTANE_PROT"]`, "unknown-template-variable", SeverityWarning, "did you mean OCTANE_PORT?"},
+		}},
+		{"Forge variable", "octane", map[string]string{"OCTANE_PORT": "8000", "SITE": "x"}, opts, []issueSummary{
+			{`sites[0].nginx_template_variables["SITE"]`, "unknown-template-variable", SeverityWarning, "remove SITE"},
+		}},
+		{"unknown template", "default", nil, opts, []issueSummary{
+			{"sites[0].nginx_template", "unknown-nginx-template", SeverityWarning, ""},
+		}},
+		{"no templates", "octane", map[string]string{"OCTANE_PORT": "8000"}, Options{Dir: dir, NginxTemplates: "nginx"}, []issueSummary{
+			{"sites[0].nginx_template_variables", "nginx-templates-not-found", SeverityWarning, "add a copy of template octane as nginx/octane.conf"},
+		}},
+		{"no templates or variables", "octane", nil, Options{Dir: dir, NginxTemplates: "nginx"}, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := nginxSite(models.SiteConfig{NginxTemplate: tt.template, NginxTemplateVariables: tt.variables})
+			got := summarize(checkNginxTemplate(cfg, 0, tt.opts))
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got %+v\nwant %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCheckNginxCustomConfig(t *testing.T) {
+	type located struct {
+		Code     string
+		Severity Severity
+		Line     int
+	}
+
+	tests := []struct {
+		name    string
+		path    string
+		content string
+		want    []located
+	}{
+		{"valid", "nginx/app.conf", "server {\n    server_name app.example.com www.app.example.com;\n}\n", nil},
+		{"absolute path", "/etc/nginx/app.conf", "", []located{{"absolute-path", SeverityError, 0}}},
+		{"outside the repository", "../app.conf", "", []located{{"path-outside-repository", SeverityError, 0}}},
+		{"missing file", "nginx/missing.conf", "", []located{{"unreadable-file", SeverityError, 0}}},
+		{"syntax error", "nginx/app.conf", "server {\n    listen 80\n}\n", []located{{"nginx-syntax", SeverityError, 2}}},
+		{"unclosed block", "nginx/app.conf", "server {\n    listen 80;\n", []located{{"nginx-syntax", SeverityError, 1}}},
+		{"top-level directives", "nginx/app.conf", "gzip on;\nlisten 80;\nfoo bar;\nserver {\n    server_name app.example.com www.app.example.com;\n}\n", []located{
+			{"nginx-directive-context", SeverityError, 2},
+			{"unknown-nginx-directive", SeverityWarning, 3},
+		}},
+		{"no server block", "nginx/app.conf", "gzip on;\n", []located{{"missing-server-block", SeverityWarning, 1}}},
+		{"leading dot", "nginx/app.conf", "server {\n    server_name .app.example.com;\n}\n", nil},
+		{"wildcard", "nginx/app.conf", "server {\n    server_name app.example.com *.example.com;\n}\n", []located{
+			{"unexpected-server-name", SeverityWarning, 2},
+		}},
+		{"leading dot of another domain", "nginx/app.conf", "server {\n    server_name app.example.com www.app.example.com .example.com;\n}\n", []located{
+			{"unexpected-server-name", SeverityWarning, 2},
+		}},
+		{"server names", "nginx/app.conf", "\nserver {\n    server_name other.example.com;\n}\n", []located{
+			{"unexpected-server-name", SeverityWarning, 3},
+			{"missing-server-name", SeverityError, 2},
+			{"missing-server-name", SeverityWarning, 2},
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			if tt.content != "" {
+				writeFiles(t, dir, map[string]string{tt.path: tt.content})
+			}
+			cfg := nginxSite(models.SiteConfig{NginxCustomConfig: tt.path, Aliases: []string{"www.app.example.com"}})
+
+			var got []located
+			for _, issue := range checkNginxCustomConfig(cfg, 0, Options{Dir: dir}) {
+				if issue.Field != "sites[0].nginx_custom_config" {
+					t.Errorf("got field %s", issue.Field)
+				}
+				if issue.Line != 0 && issue.File != filepath.Join(dir, tt.path) {
+					t.Errorf("issue on line %d located in %s, want the config file", issue.Line, issue.File)
+				}
+				got = append(got, located{issue.Code, issue.Severity, issue.Line})
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got %+v\nwant %+v", got, tt.want)
+			}
+		})
+	}
+}
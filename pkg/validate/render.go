@@ -0,0 +1,108 @@
+package validate
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// Formats lists the supported output formats
+var Formats = []string{"text", "json", "github", "sarif"}
+
+// Render writes the result in the given format
+func Render(w io.Writer, result *Result, format string) error {
+	switch format {
+	case "text":
+		return renderText(w, result)
+	case "json":
+		return renderJSON(w, result)
+	case "github":
+		return renderGitHub(w, result)
+	case "sarif":
+		return renderSARIF(w, result)
+	}
+	return fmt.Errorf("unknown format %q (must be one of: %s)", format, strings.Join(Formats, ", "))
+}
+
+func renderText(w io.Writer, result *Result) error {
+	for _, issue := range result.Issues {
+		fmt.Fprintf(w, "%s: %s: %s [%s]\n", location(issue, result.File), issue.Severity, issue.Message, issue.Code)
+		if issue.Fix != "" {
+			fmt.Fprintf(w, "    fix: %s\n", issue.Fix)
+		}
+	}
+
+	if len(result.Issues) == 0 {
+		fmt.Fprintf(w, "%s: configuration valid\n", result.File)
+		return nil
+	}
+
+	fmt.Fprintf(w, "\n%d error(s), %d warning(s), %d info\n", result.Errors(), result.Warnings(), result.Infos())
+	return nil
+}
+
+func renderJSON(w io.Writer, result *Result) error {
+	output := struct {
+		*Result
+		Valid bool `json:"valid"`
+	}{result, result.Errors() == 0}
+
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(output)
+}
+
+// renderGitHub writes GitHub Actions workflow commands that show up as annotations
+func renderGitHub(w io.Writer, result *Result) error {
+	for _, issue := range result.Issues {
+		file := issue.File
+		if file == "" {
+			file = result.File
+		}
+
+		params := []string{"file=" + escapeProperty(file)}
+		if issue.Line > 0 {
+			params = append(params, fmt.Sprintf("line=%d", issue.Line))
+		}
+		if issue.Column > 0 {
+			params = append(params, fmt.Sprintf("col=%d", issue.Column))
+		}
+		params = append(params, "title="+escapeProperty(issue.Code))
+
+		message := issue.Message
+		if issue.Fix != "" {
+			message += "\nFix: " + issue.Fix
+		}
+
+		// GitHub calls the info level "notice"
+		command := string(issue.Severity)
+		if issue.Severity == SeverityInfo {
+			command = "notice"
+		}
+
+		fmt.Fprintf(w, "::%s %s::%s\n", command, strings.Join(params, ","), escapeData(message))
+	}
+	return nil
+}
+
+func location(issue Issue, file string) string {
+	if issue.File != "" {
+		file = issue.File
+	}
+	switch {
+	case issue.Line == 0:
+		return file
+	case issue.Column == 0:
+		return fmt.Sprintf("%s:%d", file, issue.Line)
+	}
+	return fmt.Sprintf("%s:%d:%d", file, issue.Line, issue.Column)
+}
+
+func escapeData(s string) string {
+	return strings.NewReplacer("%", "%25", "\r", "%0D", "\n", "%0A").Replace(s)
+}
+
+func escapeProperty(s string) string {
+	return strings.NewReplacer("%", "%25", "\r", "%0D", "\n", "%0A", ":", "%3A", ",", "%2C").Replace(s)
+}
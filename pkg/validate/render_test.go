@@ -0,0 +1,237 @@
+package validate
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/the-trybe/forge-deploy-cli/pkg/models"
+)
+
+var update = flag.Bool("update", false, "update the golden files in testdata")
+
+// sampleResult has an issue of every severity, with and without position,
+// fix and field, and one located in another file
+func sampleResult() *Result {
+	return &Result{File: "forge-deploy.yml", Issues: []Issue{
+		{
+			Issue: models.Issue{Field: "sites[0].php_version", Code: "invalid-format", Severity: SeverityError,
+				Message: "Site 1 (app): php_version must start with 'php' (e.g., 'php81', 'php84')", Fix: "set php_version: php84"},
+			File: "forge-deploy.yml", Line: 9, Column: 5,
+		},
+		{
+			Issue: models.Issue{Field: "sites[1].processes[0].command", Code: "required", Severity: SeverityError,
+				Message: "Site 2 (admin): process 1 has no command"},
+			File: "forge-deploy.yml", Line: 14, Column: 11,
+		},
+		{
+			Issue: models.Issue{Field: "sites[0].environment", Code: "secret-in-environment", Severity: SeverityWarning,
+				Message: "Site 1 (app): environment line 3: STRIPE_SECRET looks like a Stripe secret key, 100% sure: really", Fix: "replace the value with ${STRIPE_SECRET} and add a GitHub secret"},
+			File: "forge-deploy.yml", Line: 10, Column: 5,
+		},
+		{
+			Issue: models.Issue{Field: "sites[0].nginx_custom_config", Code: "nginx-syntax", Severity: SeverityError,
+				Message: "Site 1 (app): nginx_custom_config: unexpected \"}\"\nat the end of the file"},
+			File: "nginx/app.conf", Line: 12,
+		},
+		{
+			Issue: models.Issue{Field: "sites[1].github_branch", Code: "redundant-branch", Severity: SeverityInfo,
+				Message: "Site 2 (admin): github_branch main is the default branch", Fix: "remove github_branch"},
+			File: "forge-deploy.yml", Line: 13, Column: 5,
+		},
+		{
+			Issue: models.Issue{Code: "yaml-syntax", Severity: SeverityError, Message: "mapping values are not allowed in this context"},
+		},
+	}}
+}
+
+func TestRender(t *testing.T) {
+	results := map[string]*Result{
+		"sample": sampleResult(),
+		"valid":  {File: "forge-deploy.yml", Issues: []Issue{}},
+	}
+
+	for name, result := range results {
+		for _, format := range Formats {
+			golden := filepath.Join("testdata", name+"."+format+".golden")
+			t.Run(name+"/"+format, func(t *testing.T) {
+				var buf bytes.Buffer
+				if err := Render(&buf, result, format); err != nil {
+					t.Fatal(err)
+				}
+
+				if *update {
+					if err := os.WriteFile(golden, buf.Bytes(), 0644); err != nil {
+						t.Fatal(err)
+					}
+					return
+				}
+				want, err := os.ReadFile(golden)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if got := buf.String(); got != string(want) {
+					t.Errorf("got:\n%s\nwant:\n%s", got, want)
+				}
+			})
+		}
+	}
+}
+
+func TestRenderUnknownFormat(t *testing.T) {
+	if err := Render(&bytes.Buffer{}, sampleResult(), "xml"); err == nil {
+		t.Error("expected an error for an unknown format")
+	}
+}
+
+func TestFailed(t *testing.T) {
+	issue := func(severity Severity) Issue {
+		return Issue{Issue: models.Issue{Code: "code", Severity: severity}}
+	}
+
+	tests := []struct {
+		name   string
+		issues []Issue
+		failed bool
+		strict bool // whether it fails with --strict
+	}{
+		{"no issues", nil, false, false},
+		{"info only", []Issue{issue(SeverityInfo)}, false, false},
+		{"warning", []Issue{issue(SeverityWarning), issue(SeverityInfo)}, false, true},
+		{"error", []Issue{issue(SeverityError)}, true, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := &Result{Issues: tt.issues}
+			if got := result.Failed(false); got != tt.failed {
+				t.Errorf("Failed(false) = %v, want %v", got, tt.failed)
+			}
+			if got := result.Failed(true); got != tt.strict {
+				t.Errorf("Failed(true) = %v, want %v", got, tt.strict)
+			}
+		})
+	}
+}
+
+func TestFileDecodeErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		code    string
+		line    int
+	}{
+		{"syntax error", "organization: acme\n\tserver: web-1\n", "yaml-syntax", 2},
+		{"unknown field", "organization: acme\nregion: eu\n", "unknown-field", 2},
+		{"wrong type", "organization: acme\nsites: app\n", "invalid-value", 2},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "forge-deploy.yml")
+			if err := os.WriteFile(path, []byte(tt.content), 0644); err != nil {
+				t.Fatal(err)
+			}
+
+			result, err := File(path, Options{})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(result.Issues) != 1 {
+				t.Fatalf("got %d issues, want 1: %+v", len(result.Issues), result.Issues)
+			}
+			issue := result.Issues[0]
+			if issue.Code != tt.code || issue.Line != tt.line || issue.File != path {
+				t.Errorf("got %s at %s:%d, want %s at %s:%d", issue.Code, issue.File, issue.Line, tt.code, path, tt.line)
+			}
+			if !result.Failed(false) {
+				t.Error("a file that cannot be decoded must fail validation")
+			}
+		})
+	}
+}
+
+func TestRenderSARIF(t *testing.T) {
+	var buf bytes.Buffer
+	if err := Render(&buf, sampleResult(), "sarif"); err != nil {
+		t.Fatal(err)
+	}
+
+	var log sarifLog
+	if err := json.Unmarshal(buf.Bytes(), &log); err != nil {
+		t.Fatal(err)
+	}
+	if len(log.Runs) != 1 {
+		t.Fatalf("got %d runs, want 1", len(log.Runs))
+	}
+	run := log.Runs[0]
+
+	var rules []string
+	for _, rule := range run.Tool.Driver.Rules {
+		rules = append(rules, rule.ID)
+	}
+	wantRules := []string{
+		"invalid-format/sites.php_version",
+		"required/sites.processes.command",
+		"secret-in-environment",
+		"nginx-syntax",
+		"redundant-branch",
+		"yaml-syntax",
+	}
+	if !reflect.DeepEqual(rules, wantRules) {
+		t.Errorf("got rules %q, want %q", rules, wantRules)
+	}
+
+	wantLocations := []struct {
+		uri          string
+		line, column int
+	}{
+		{"forge-deploy.yml", 9, 5},
+		{"forge-deploy.yml", 14, 11},
+		{"forge-deploy.yml", 10, 5},
+		{"nginx/app.conf", 12, 0},
+		{"forge-deploy.yml", 13, 5},
+		{"forge-deploy.yml", 0, 0},
+	}
+	fingerprints := make(map[string]bool)
+	for i, result := range run.Results {
+		if result.RuleID != wantRules[i] || result.RuleIndex != i {
+			t.Errorf("result %d: got rule %s (%d), want %s (%d)", i, result.RuleID, result.RuleIndex, wantRules[i], i)
+		}
+
+		location := result.Locations[0].PhysicalLocation
+		want := wantLocations[i]
+		line, column := 0, 0
+		if location.Region != nil {
+			line, column = location.Region.StartLine, location.Region.StartColumn
+		}
+		if location.ArtifactLocation.URI != want.uri || line != want.line || column != want.column {
+			t.Errorf("result %d: got location %s:%d:%d, want %s:%d:%d", i, location.ArtifactLocation.URI, line, column, want.uri, want.line, want.column)
+		}
+
+		fp := result.PartialFingerprints[fingerprintKey]
+		if fp == "" || fingerprints[fp] {
+			t.Errorf("result %d: fingerprint %q is empty or not unique", i, fp)
+		}
+		fingerprints[fp] = true
+	}
+
+	// Moving an issue to another line keeps its fingerprint
+	moved := sampleResult()
+	moved.Issues[0].Line += 3
+	buf.Reset()
+	if err := Render(&buf, moved, "sarif"); err != nil {
+		t.Fatal(err)
+	}
+	var movedLog sarifLog
+	if err := json.Unmarshal(buf.Bytes(), &movedLog); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := movedLog.Runs[0].Results[0].PartialFingerprints[fingerprintKey], run.Results[0].PartialFingerprints[fingerprintKey]; got != want {
+		t.Errorf("fingerprint changed from %s to %s when the issue moved", want, got)
+	}
+}
@@ -0,0 +1,279 @@
+package validate
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// SARIF 2.1.0, the format code scanning tools such as GitHub code scanning read
+const (
+	sarifVersion = "2.1.0"
+	sarifSchema  = "https://json.schemastore.org/sarif-2.1.0.json"
+)
+
+type sarifLog struct {
+	Schema  string     `json:"$schema"`
+	Version string     `json:"version"`
+	Runs    []sarifRun `json:"runs"`
+}
+
+type sarifRun struct {
+	Tool    sarifTool     `json:"tool"`
+	Results []sarifResult `json:"results"`
+}
+
+type sarifTool struct {
+	Driver sarifDriver `json:"driver"`
+}
+
+type sarifDriver struct {
+	Name           string      `json:"name"`
+	InformationURI string      `json:"informationUri"`
+	Rules          []sarifRule `json:"rules"`
+}
+
+type sarifRule struct {
+	ID               string        `json:"id"`
+	ShortDescription *sarifMessage `json:"shortDescription,omitempty"`
+	HelpURI          string        `json:"helpUri"`
+}
+
+type sarifResult struct {
+	RuleID              string            `json:"ruleId"`
+	RuleIndex           int               `json:"ruleIndex"`
+	Level               string            `json:"level"`
+	Message             sarifMessage      `json:"message"`
+	Locations           []sarifLocation   `json:"locations"`
+	PartialFingerprints map[string]string `json:"partialFingerprints"`
+	Properties          map[string]string `json:"properties,omitempty"`
+}
+
+type sarifMessage struct {
+	Text string `json:"text"`
+}
+
+type sarifLocation struct {
+	PhysicalLocation sarifPhysicalLocation `json:"physicalLocation"`
+}
+
+type sarifPhysicalLocation struct {
+	ArtifactLocation sarifArtifactLocation `json:"artifactLocation"`
+	Region           *sarifRegion          `json:"region,omitempty"`
+}
+
+type sarifArtifactLocation struct {
+	URI string `json:"uri"`
+}
+
+type sarifRegion struct {
+	StartLine   int `json:"startLine"`
+	StartColumn int `json:"startColumn,omitempty"`
+}
+
+// sarifLevels maps severities to SARIF result levels
+var sarifLevels = map[Severity]string{
+	SeverityError:   "error",
+	SeverityWarning: "warning",
+	SeverityInfo:    "note",
+}
+
+// Documentation of the checks, and of those with their own README section
+const (
+	helpURI        = "https://github.com/the-trybe/forge-deploy-cli#validating-a-configuration"
+	scriptHelpURI  = "https://github.com/the-trybe/forge-deploy-cli#deployment-script-templates"
+	secretsHelpURI = "https://github.com/the-trybe/forge-deploy-cli#secret-detection"
+)
+
+// fieldRuleCodes are codes shared by unrelated fields. Their rules are split
+// by field, so code scanning tells a missing php_version from a missing
+// process command.
+var fieldRuleCodes = map[string]bool{
+	"required":             true,
+	"invalid-value":        true,
+	"invalid-format":       true,
+	"negative-number":      true,
+	"ignored-setting":      true,
+	"conflicting-settings": true,
+}
+
+// ruleDescriptions are the short descriptions of the rules, by issue code
+var ruleDescriptions = map[string]string{
+	"yaml-syntax":                   "The file is not valid YAML",
+	"unknown-field":                 "Unknown setting",
+	"required":                      "Required setting is missing",
+	"invalid-value":                 "Setting has an invalid value",
+	"invalid-format":                "Setting has an invalid format",
+	"negative-number":               "Number must not be negative",
+	"ignored-setting":               "Setting is ignored",
+	"conflicting-settings":          "Settings conflict",
+	"unreadable-file":               "Referenced file cannot be read",
+	"absolute-path":                 "Path must be relative to the repository root",
+	"absolute-path-required":        "Path must be absolute",
+	"path-outside-repository":       "Path points outside the repository",
+	"invalid-hostname":              "Invalid domain name",
+	"invalid-user":                  "Invalid user name",
+	"invalid-cron":                  "Invalid cron expression",
+	"invalid-environment":           "Environment cannot be resolved",
+	"invalid-dotenv":                "Malformed environment line",
+	"cron-without-custom-frequency": "cron is set without the custom frequency",
+	"never-runs":                    "Scheduled job never runs",
+	"duplicate-job":                 "Job is scheduled twice",
+	"scheduler-as-job":              "Laravel scheduler set up as a scheduled job",
+	"scheduler-without-laravel":     "Laravel scheduler on a site that is not Laravel",
+	"runs-as-root":                  "Runs as root",
+	"user-not-isolated-user":        "Runs as another user than the isolated user",
+	"directory-outside-site":        "Process directory is outside the site",
+	"stopwaitsecs-below-timeout":    "Queue worker can be killed while running a job",
+	"php-version-mismatch":          "Command runs another PHP version than the site",
+	"duplicate-site-name":           "Site name is used twice",
+	"duplicate-hostname":            "Domain is served by two sites",
+	"duplicate-alias":               "Alias is listed twice",
+	"duplicate-process-name":        "Process name is used more than once on the server",
+	"duplicate-environment":         "Environment name is used twice",
+	"duplicate-branch":              "Branch deploys two environments",
+	"duplicate-env-key":             "Environment key is defined twice",
+	"certificate-alias-conflict":    "Certificate alias is another site's domain",
+	"isolated-user-php-conflict":    "Isolated user is shared with another PHP version",
+	"served-directory-conflict":     "Sites serve overlapping directories",
+	"redundant-branch":              "Site branch repeats the default branch",
+	"redundant-alias":               "Alias is already served",
+	"wildcard-certificate":          "Certificate requested for a wildcard alias",
+	"www-redirect-on-forge":         "www redirect on an on-forge domain",
+	"www-redirect-direction":        "www redirect points the wrong way",
+	"missing-env-keys":              "Environment is missing keys of .env.example",
+	"missing-app-key":               "Laravel environment has no APP_KEY",
+	"missing-app-url":               "Laravel environment has no APP_URL",
+	"secret-in-environment":         "Secret committed in the environment",
+	"unknown-nginx-template":        "Unknown nginx template",
+	"nginx-templates-not-found":     "No local nginx templates to check variables against",
+	"unknown-template-variable":     "nginx template variable is not used",
+	"missing-template-variable":     "nginx template variable is not set",
+	"nginx-syntax":                  "nginx configuration syntax error",
+	"nginx-directive-context":       "nginx directive used outside its block",
+	"unknown-nginx-directive":       "Unknown nginx directive",
+	"missing-server-block":          "nginx configuration has no server block",
+	"missing-server-name":           "No server_name answers for a site domain",
+	"unexpected-server-name":        "server_name answers for another domain",
+	// Deployment script linter
+	"missing-set-e":                       "Deployment script does not stop on errors",
+	"missing-release-macro":               "Zero-downtime script does not activate the release",
+	"release-macro-order":                 "Release activated before the build",
+	"release-macro-without-zero-downtime": "Release macro without zero-downtime deployments",
+	"migrate-without-force":               "migrate without --force",
+	"hard-coded-php":                      "Hard-coded php binary",
+	"hard-coded-composer":                 "Hard-coded composer binary",
+	"hard-coded-path":                     "Hard-coded site path",
+	"cd-site-path":                        "cd into the site path",
+	"cd-outside-root-dir":                 "cd outside root_dir",
+	"artisan-outside-root-dir":            "artisan run outside root_dir",
+}
+
+var fieldIndex = regexp.MustCompile(`\[\d+\]`)
+
+// newSARIFRule returns the rule of an issue. Rules of codes shared by unrelated
+// fields are named after the field without indexes, e.g.
+// required/sites.processes.command.
+func newSARIFRule(issue Issue) sarifRule {
+	rule := sarifRule{ID: issue.Code, HelpURI: helpURI}
+	description := ruleDescriptions[issue.Code]
+	if fieldRuleCodes[issue.Code] && issue.Field != "" {
+		field := fieldIndex.ReplaceAllString(issue.Field, "")
+		rule.ID += "/" + field
+		description += ": " + field
+	}
+	if description != "" {
+		rule.ShortDescription = &sarifMessage{Text: description}
+	}
+
+	switch {
+	case issue.Code == "secret-in-environment":
+		rule.HelpURI = secretsHelpURI
+	case strings.HasSuffix(issue.Field, ".deployment_script"):
+		rule.HelpURI = scriptHelpURI
+	}
+	return rule
+}
+
+// renderSARIF writes the result as a SARIF log with one rule per issue code,
+// or per code and field for codes shared by unrelated fields
+func renderSARIF(w io.Writer, result *Result) error {
+	run := sarifRun{
+		Tool: sarifTool{Driver: sarifDriver{
+			Name:           "forge-deploy",
+			InformationURI: "https://github.com/the-trybe/forge-deploy-cli",
+			Rules:          []sarifRule{},
+		}},
+		Results: []sarifResult{},
+	}
+
+	rules := make(map[string]int)
+	for _, issue := range result.Issues {
+		rule := newSARIFRule(issue)
+		index, ok := rules[rule.ID]
+		if !ok {
+			index = len(run.Tool.Driver.Rules)
+			rules[rule.ID] = index
+			run.Tool.Driver.Rules = append(run.Tool.Driver.Rules, rule)
+		}
+
+		file := issue.File
+		if file == "" {
+			file = result.File
+		}
+		location := sarifPhysicalLocation{ArtifactLocation: sarifArtifactLocation{URI: sarifURI(file)}}
+		if issue.Line > 0 {
+			location.Region = &sarifRegion{StartLine: issue.Line, StartColumn: issue.Column}
+		}
+
+		text := issue.Message
+		properties := map[string]string{}
+		if issue.Field != "" {
+			properties["field"] = issue.Field
+		}
+		if issue.Fix != "" {
+			text += "\nFix: " + issue.Fix
+			properties["fix"] = issue.Fix
+		}
+
+		run.Results = append(run.Results, sarifResult{
+			RuleID:              run.Tool.Driver.Rules[index].ID,
+			RuleIndex:           index,
+			Level:               sarifLevels[issue.Severity],
+			Message:             sarifMessage{Text: text},
+			Locations:           []sarifLocation{{PhysicalLocation: location}},
+			PartialFingerprints: map[string]string{fingerprintKey: fingerprint(file, run.Tool.Driver.Rules[index].ID, issue)},
+			Properties:          properties,
+		})
+	}
+
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(sarifLog{Schema: sarifSchema, Version: sarifVersion, Runs: []sarifRun{run}})
+}
+
+// fingerprintKey names the fingerprint code scanning matches results by
+// across runs
+const fingerprintKey = "forgeDeployIssue/v1"
+
+// fingerprint identifies an issue by its file, rule, field and message
+// rather than by its position, so that results survive edits elsewhere in
+// the file
+func fingerprint(file, ruleID string, issue Issue) string {
+	sum := sha256.Sum256([]byte(strings.Join([]string{filepath.ToSlash(file), ruleID, issue.Field, issue.Message}, "\x00")))
+	return hex.EncodeToString(sum[:16])
+}
+
+// sarifURI returns a relative path as is, resolved against the repository
+// root by code scanning, and an absolute path as a file URI
+func sarifURI(path string) string {
+	path = filepath.ToSlash(path)
+	if filepath.IsAbs(path) {
+		return "file://" + path
+	}
+	return path
+}
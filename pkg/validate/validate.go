@@ -0,0 +1,236 @@
+package validate
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/the-trybe/forge-deploy-cli/pkg/config"
+	"github.com/the-trybe/forge-deploy-cli/pkg/models"
+)
+
+// Severity is the severity of an issue
+type Severity = models.Severity
+
+const (
+	SeverityError   = models.SeverityError
+	SeverityWarning = models.SeverityWarning
+	SeverityInfo    = models.SeverityInfo
+)
+
+// Issue is a validation issue located in the file it was found in
+type Issue struct {
+	models.Issue
+	File   string `json:"file,omitempty"`
+	Line   int    `json:"line,omitempty"`
+	Column int    `json:"column,omitempty"`
+}
+
+// Result is the outcome of validating a configuration file
+type Result struct {
+	File   string  `json:"file"`
+	Issues []Issue `json:"issues"`
+}
+
+// Errors returns the number of issues with error severity
+func (r *Result) Errors() int {
+	return r.count(SeverityError)
+}
+
+// Warnings returns the number of issues with warning severity
+func (r *Result) Warnings() int {
+	return r.count(SeverityWarning)
+}
+
+// Infos returns the number of issues with info severity
+func (r *Result) Infos() int {
+	return r.count(SeverityInfo)
+}
+
+// Failed reports whether the result should fail a CI run: when it has errors,
+// or in strict mode warnings
+func (r *Result) Failed(strict bool) bool {
+	return r.Errors() > 0 || strict && r.Warnings() > 0
+}
+
+func (r *Result) count(severity Severity) int {
+	n := 0
+	for _, issue := range r.Issues {
+		if issue.Severity == severity {
+			n++
+		}
+	}
+	return n
+}
+
+// Options provides the context of checks that read files next to the configuration
+type Options struct {
+	// Dir is the repository root that env_file, root_dir and templates are
+	// resolved against. Checks that read files are skipped when it is empty.
+	Dir string
+	// NginxTemplates is the directory of local nginx templates, relative to Dir.
+	// Template variables are not checked when it is empty.
+	NginxTemplates string
+}
+
+// File loads and validates a forge-deploy.yml file, or a base configuration
+// with environment overlays. Files that cannot be decoded are reported as
+// issues rather than as an error.
+func File(path string, opts Options) (*Result, error) {
+	result := &Result{File: path, Issues: []Issue{}}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var doc *config.Document
+	if config.IsProject(data) {
+		doc, err = config.ParseProjectDocument(data, path)
+	} else {
+		doc, err = config.ParseDocument(data, path)
+	}
+	if err != nil {
+		switch e := err.(type) {
+		case config.ErrorList:
+			for _, item := range e {
+				result.Issues = append(result.Issues, decodeIssue(item))
+			}
+		case *config.Error:
+			result.Issues = append(result.Issues, decodeIssue(e))
+		default:
+			return nil, err
+		}
+		return result, nil
+	}
+
+	result.Issues = Document(doc, opts)
+	return result, nil
+}
+
+// Document validates a loaded configuration, locating each issue in the file.
+// Paths in the configuration are resolved against the file's directory.
+func Document(doc *config.Document, opts Options) []Issue {
+	opts.Dir = filepath.Dir(doc.Path)
+	var issues []Issue
+	if doc.Project != nil {
+		issues = Project(doc.Project, opts)
+	} else {
+		issues = Config(doc.Config, opts)
+	}
+	for i := range issues {
+		if issues[i].File != "" {
+			// Already located in another file, e.g. a custom nginx config
+			continue
+		}
+		issues[i].File = doc.Path
+		if issues[i].Field != "" {
+			issues[i].Line, issues[i].Column = doc.Position(issues[i].Field)
+		}
+	}
+	return issues
+}
+
+// Config runs the model validation and the semantic checks on a configuration
+func Config(cfg *models.DeploymentConfig, opts Options) []Issue {
+	issues := []Issue{}
+
+	for _, issue := range cfg.Validate() {
+		issues = append(issues, Issue{Issue: issue})
+	}
+
+	return append(issues, checks(cfg, opts)...)
+}
+
+// Project runs the model validation of a base configuration and the semantic
+// checks on the base configuration and each environment resolved from it.
+// Like ProjectConfig.Validate, issues of the base configuration are reported
+// once and the issues an environment introduces under that environment.
+func Project(project *models.ProjectConfig, opts Options) []Issue {
+	issues := []Issue{}
+
+	for _, issue := range project.Validate() {
+		issues = append(issues, Issue{Issue: issue})
+	}
+
+	base := checks(&project.DeploymentConfig, opts)
+	inherited := make([]bool, len(base))
+	if len(project.Environments) == 0 {
+		for i := range inherited {
+			inherited[i] = true
+		}
+	}
+
+	var introduced []Issue
+	for i, env := range project.Environments {
+		resolved, err := project.Resolve(env.Name)
+		if err != nil {
+			// Reported by ProjectConfig.Validate
+			continue
+		}
+		matched := make([]bool, len(base))
+		for _, issue := range checks(resolved, opts) {
+			if j := matchIssue(base, matched, issue); j >= 0 {
+				inherited[j] = true
+				continue
+			}
+			issue.Field = project.OverlayField(i, issue.Field)
+			issue.Message = fmt.Sprintf("Environment %s: %s", env.Name, issue.Message)
+			introduced = append(introduced, issue)
+		}
+	}
+
+	for i, issue := range base {
+		if inherited[i] {
+			issues = append(issues, issue)
+		}
+	}
+	return append(issues, introduced...)
+}
+
+// matchIssue returns the index of the first issue of base that is not matched
+// yet and has the field, code and location of issue, or -1
+func matchIssue(base []Issue, matched []bool, issue Issue) int {
+	for i, b := range base {
+		if !matched[i] && b.Field == issue.Field && b.Code == issue.Code && b.File == issue.File && b.Line == issue.Line {
+			matched[i] = true
+			return i
+		}
+	}
+	return -1
+}
+
+// checks runs the semantic checks that go beyond the model validation
+func checks(cfg *models.DeploymentConfig, opts Options) []Issue {
+	var issues []Issue
+	for i := range cfg.Sites {
+		issues = append(issues, checkSite(cfg, i)...)
+		issues = append(issues, checkEnvironment(cfg, i, opts)...)
+		issues = append(issues, checkNginxTemplate(cfg, i, opts)...)
+		issues = append(issues, checkNginxCustomConfig(cfg, i, opts)...)
+	}
+
+	return issues
+}
+
+func decodeIssue(err *config.Error) Issue {
+	code := "invalid-value"
+	switch {
+	case strings.HasPrefix(err.Message, "unknown field"):
+		code = "unknown-field"
+	case err.Column == 0:
+		// Only syntax errors lack a column
+		code = "yaml-syntax"
+	}
+	return Issue{
+		Issue: models.Issue{
+			Code:     code,
+			Severity: SeverityError,
+			Message:  err.Message,
+		},
+		File:   err.File,
+		Line:   err.Line,
+		Column: err.Column,
+	}
+}